@@ -3,7 +3,16 @@ module github.com/zydee3/stockdb
 go 1.23.3
 
 require (
+	github.com/parquet-go/parquet-go v0.25.1
 	github.com/urfave/cli/v3 v3.3.8
 	golang.org/x/time v0.12.0
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
@@ -0,0 +1,125 @@
+package cboe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.StatusError interface
+
+const (
+	// DefaultBaseURL serves Cboe's delayed quotes, which need no API key.
+	DefaultBaseURL = "https://cdn.cboe.com/api/global/delayed_quotes"
+
+	// chainTimeLayout is the layout of the UTC snapshot time of a chain.
+	chainTimeLayout = "2006-01-02 15:04:05"
+)
+
+// HTTPClient is a client for Cboe's delayed quotes.
+type HTTPClient struct {
+	client  httpUtil.HTTPClient
+	baseURL string
+}
+
+// APIError is a non-200 response from Cboe.
+type APIError struct {
+	URL        string
+	StatusCode int
+}
+
+// Chain is the delayed option chain of an underlying.
+type Chain struct {
+	Timestamp string    `json:"timestamp"`
+	Data      ChainData `json:"data"`
+
+	BytesFetched int64 `json:"-"`
+}
+
+type ChainData struct {
+	Symbol       string        `json:"symbol"`
+	CurrentPrice float64       `json:"current_price"`
+	Options      []ChainOption `json:"options"`
+}
+
+// ChainOption is an option of a chain. Option is its OCC symbol.
+type ChainOption struct {
+	Option         string  `json:"option"`
+	Bid            float64 `json:"bid"`
+	BidSize        float64 `json:"bid_size"`
+	Ask            float64 `json:"ask"`
+	AskSize        float64 `json:"ask_size"`
+	IV             float64 `json:"iv"`
+	OpenInterest   float64 `json:"open_interest"`
+	Volume         float64 `json:"volume"`
+	LastTradePrice float64 `json:"last_trade_price"`
+}
+
+// NewHTTPClient returns a client for the delayed quotes at baseURL, which
+// defaults to DefaultBaseURL.
+func NewHTTPClient(client httpUtil.HTTPClient, baseURL string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &HTTPClient{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Cboe request to %s failed (%d %s)", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *APIError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+// GetTime returns the time the chain was quoted at.
+func (c *Chain) GetTime() (time.Time, error) {
+	return time.ParseInLocation(chainTimeLayout, c.Timestamp, time.UTC)
+}
+
+// OptionChain returns the delayed option chain of symbol. Index symbols are
+// written with a leading caret, e.g. ^SPX.
+func (h *HTTPClient) OptionChain(ctx context.Context, symbol string) (*Chain, error) {
+	// Cboe publishes index chains under an underscore prefix.
+	path := strings.ToUpper(symbol)
+	if index, isIndex := strings.CutPrefix(path, "^"); isIndex {
+		path = "_" + index
+	}
+
+	chainURL := fmt.Sprintf("%s/options/%s.json", h.baseURL, path)
+
+	response, err := h.client.Get(ctx, chainURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, &APIError{URL: chainURL, StatusCode: response.StatusCode}
+	}
+
+	chain := &Chain{}
+	if unmarshalError := json.Unmarshal(body, chain); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to decode Cboe option chain of %s: %w", symbol, unmarshalError)
+	}
+
+	chain.BytesFetched = int64(len(body))
+
+	return chain, nil
+}
@@ -0,0 +1,159 @@
+package cboe
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+
+const (
+	SourceType = "CBOE"
+
+	EndpointOptionChain = "OPTION_CHAIN"
+
+	// ParameterExpiresWithin is the spec.source.parameters key limiting a
+	// snapshot to the options expiring within that many days of it. Every
+	// listed expiration is kept by default.
+	ParameterExpiresWithin = "expiresWithin"
+)
+
+// Provider snapshots the delayed option chains Cboe publishes. Each option is
+// stored as a contract linked to its underlying and a quote at the time of
+// the snapshot.
+type Provider struct {
+	client *HTTPClient
+}
+
+func NewProvider(client *HTTPClient) *Provider {
+	return &Provider{
+		client: client,
+	}
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityLatest}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{EndpointOptionChain}
+}
+
+func (p *Provider) Limits() provider.RequestLimits {
+	const (
+		requestsPerMinute = 30
+		burst             = 2
+	)
+
+	return provider.RequestLimits{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             burst,
+	}
+}
+
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	if request.Endpoint != EndpointOptionChain {
+		return nil, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+
+	if _, err := expiresWithin(request); err != nil {
+		return nil, err
+	}
+
+	chain, err := p.client.OptionChain(ctx, request.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         chain,
+		BytesFetched: chain.BytesFetched,
+	}, nil
+}
+
+// Normalize returns a contract and a quote for every option of the chain.
+// Options with a symbol that is not a valid OCC symbol are skipped.
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	chain, ok := payload.Data.(*Chain)
+	if !ok {
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+
+	quotedAt, err := chain.GetTime()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Cboe chain timestamp %q: %w", chain.Timestamp, err)
+	}
+
+	window, err := expiresWithin(payload.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	underlying := strings.ToUpper(payload.Request.Symbol)
+	lastExpiration := quotedAt.AddDate(0, 0, window)
+
+	normalized := make([]records.Record, 0, 2*len(chain.Data.Options))
+
+	for _, option := range chain.Data.Options {
+		contract, parseError := records.ParseOCCSymbol(option.Option)
+		if parseError != nil {
+			logger.Warnf("Skipping option in Cboe chain of %s: %v", underlying, parseError)
+			continue
+		}
+
+		if window > 0 && contract.Expiration.After(lastExpiration) {
+			continue
+		}
+
+		contract.Underlying = underlying
+		contract.Source = SourceType
+
+		normalized = append(normalized,
+			contract,
+			records.OptionQuote{
+				Symbol:            contract.Symbol,
+				Underlying:        underlying,
+				Time:              quotedAt,
+				Bid:               option.Bid,
+				BidSize:           option.BidSize,
+				Ask:               option.Ask,
+				AskSize:           option.AskSize,
+				Last:              option.LastTradePrice,
+				Volume:            option.Volume,
+				OpenInterest:      option.OpenInterest,
+				ImpliedVolatility: option.IV,
+				UnderlyingPrice:   chain.Data.CurrentPrice,
+				Source:            SourceType,
+			},
+		)
+	}
+
+	return normalized, nil
+}
+
+// expiresWithin returns the expiresWithin parameter of request in days, or
+// zero if it is not set.
+func expiresWithin(request provider.Request) (int, error) {
+	value := request.Parameters[ParameterExpiresWithin]
+	if value == "" {
+		return 0, nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a positive number of days", ParameterExpiresWithin, value)
+	}
+
+	return days, nil
+}
@@ -0,0 +1,196 @@
+package edgar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.StatusError interface
+
+const (
+	// DefaultBaseURL serves the submissions and XBRL APIs.
+	DefaultBaseURL = "https://data.sec.gov"
+	// DefaultTickersURL lists the ticker and CIK of every registrant.
+	DefaultTickersURL = "https://www.sec.gov" + TickersPath
+	// TickersPath is the path of the ticker list, which mirrors of SEC's
+	// hosts serve under their base URL.
+	TickersPath = "/files/company_tickers.json"
+
+	// MaxRequestsPerSecond is the fair access limit of SEC's EDGAR APIs.
+	MaxRequestsPerSecond = 10
+)
+
+// ErrUnknownTicker is returned when SEC has no CIK for a ticker.
+var ErrUnknownTicker = errors.New("unknown ticker")
+
+// ClientOptions configures an HTTPClient.
+type ClientOptions struct {
+	// UserAgent identifies the requester to SEC, which requires a company or
+	// name and a contact email, e.g. "Example Corp admin@example.com".
+	UserAgent string
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+	// TickersURL defaults to DefaultTickersURL.
+	TickersURL string
+	// RequestsPerSecond defaults to, and cannot exceed, MaxRequestsPerSecond.
+	RequestsPerSecond float64
+}
+
+// HTTPClient is a client for the EDGAR submissions and XBRL APIs. It sends
+// the User-Agent SEC requires and paces requests to SEC's rate limit. Cached
+// responses are not paced.
+type HTTPClient struct {
+	client     httpUtil.HTTPClient
+	userAgent  string
+	baseURL    string
+	tickersURL string
+
+	mu      sync.Mutex
+	tickers map[string]string
+}
+
+type companyTicker struct {
+	CIK    int    `json:"cik_str"`
+	Ticker string `json:"ticker"`
+	Title  string `json:"title"`
+}
+
+// APIError is a non-200 response from EDGAR.
+type APIError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("EDGAR request to %s failed (%d %s)", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *APIError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+func NewHTTPClient(client httpUtil.HTTPClient, options ClientOptions) (*HTTPClient, error) {
+	if strings.TrimSpace(options.UserAgent) == "" {
+		return nil, errors.New("EDGAR requires a User-Agent with a name and contact email")
+	}
+
+	if options.BaseURL == "" {
+		options.BaseURL = DefaultBaseURL
+	}
+
+	if options.TickersURL == "" {
+		options.TickersURL = DefaultTickersURL
+	}
+
+	if options.RequestsPerSecond <= 0 || options.RequestsPerSecond > MaxRequestsPerSecond {
+		options.RequestsPerSecond = MaxRequestsPerSecond
+	}
+
+	client.Budget = rate.NewLimiter(rate.Limit(options.RequestsPerSecond), 1)
+
+	return &HTTPClient{
+		client:     client,
+		userAgent:  options.UserAgent,
+		baseURL:    strings.TrimSuffix(options.BaseURL, "/"),
+		tickersURL: options.TickersURL,
+	}, nil
+}
+
+// LookupCIK returns the ten digit CIK of a ticker. The ticker list is
+// downloaded on first use.
+func (h *HTTPClient) LookupCIK(ctx context.Context, ticker string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tickers == nil {
+		companies := map[string]companyTicker{}
+		if _, err := h.getJSON(ctx, h.tickersURL, &companies); err != nil {
+			return "", fmt.Errorf("failed to load EDGAR tickers: %w", err)
+		}
+
+		tickers := make(map[string]string, len(companies))
+		for _, company := range companies {
+			tickers[strings.ToUpper(company.Ticker)] = FormatCIK(company.CIK)
+		}
+
+		h.tickers = tickers
+	}
+
+	cik, ok := h.tickers[strings.ToUpper(ticker)]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownTicker, ticker)
+	}
+
+	return cik, nil
+}
+
+// CacheTTLs returns the HTTP cache TTLs of the EDGAR APIs. Filings are
+// checked for hourly, company facts and the ticker list change at most daily.
+func CacheTTLs() httpUtil.CacheTTLs {
+	const (
+		submissionsCacheTTL = time.Hour
+		dailyCacheTTL       = 24 * time.Hour
+	)
+
+	return httpUtil.CacheTTLs{
+		"submissions/CIK*.json":  submissionsCacheTTL,
+		"companyfacts/CIK*.json": dailyCacheTTL,
+		"company_tickers.json":   dailyCacheTTL,
+	}
+}
+
+// FormatCIK zero-pads a CIK to the ten digits used in EDGAR URLs.
+func FormatCIK(cik int) string {
+	const (
+		cikDigits = 10
+	)
+
+	formatted := strconv.Itoa(cik)
+	return strings.Repeat("0", max(cikDigits-len(formatted), 0)) + formatted
+}
+
+// getJSON requests url with the User-Agent and decodes the JSON response into
+// target. It returns the size of the response.
+func (h *HTTPClient) getJSON(ctx context.Context, url string, target any) (int64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("User-Agent", h.userAgent)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return 0, &APIError{URL: url, StatusCode: response.StatusCode}
+	}
+
+	if unmarshalError := json.Unmarshal(body, target); unmarshalError != nil {
+		return 0, fmt.Errorf("failed to decode EDGAR response from %s: %w", url, unmarshalError)
+	}
+
+	return int64(len(body)), nil
+}
@@ -0,0 +1,52 @@
+package edgar
+
+import (
+	"context"
+	"fmt"
+)
+
+// CompanyFacts is every XBRL fact a registrant has filed, as returned by the
+// companyfacts API. Facts are keyed by taxonomy, such as "us-gaap", then by
+// concept.
+type CompanyFacts struct {
+	CIK          int                                `json:"cik"`
+	EntityName   string                             `json:"entityName"`
+	Facts        map[string]map[string]ConceptFacts `json:"facts"`
+	BytesFetched int64                              `json:"-"`
+}
+
+// ConceptFacts are the values of a concept, keyed by unit, such as "USD" or
+// "shares".
+type ConceptFacts struct {
+	Label       string                 `json:"label"`
+	Description string                 `json:"description"`
+	Units       map[string][]FactValue `json:"units"`
+}
+
+// FactValue is a single filed value of a concept. Start is empty for
+// instantaneous values, such as balance sheet items.
+type FactValue struct {
+	Start        string  `json:"start"`
+	End          string  `json:"end"`
+	Value        float64 `json:"val"`
+	Accession    string  `json:"accn"`
+	FiscalYear   int     `json:"fy"`
+	FiscalPeriod string  `json:"fp"`
+	Form         string  `json:"form"`
+	Filed        string  `json:"filed"`
+	Frame        string  `json:"frame"`
+}
+
+// CompanyFacts returns the XBRL facts of the registrant with the ten digit cik.
+func (h *HTTPClient) CompanyFacts(ctx context.Context, cik string) (*CompanyFacts, error) {
+	facts := &CompanyFacts{}
+
+	bytesFetched, err := h.getJSON(ctx, fmt.Sprintf("%s/api/xbrl/companyfacts/CIK%s.json", h.baseURL, cik), facts)
+	if err != nil {
+		return nil, err
+	}
+
+	facts.BytesFetched = bytesFetched
+
+	return facts, nil
+}
@@ -0,0 +1,268 @@
+package edgar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+
+const (
+	SourceType = "EDGAR"
+
+	EndpointFilings = "FILINGS"
+	EndpointFacts   = "FACTS"
+
+	// ParameterForms is the spec.source.parameters key limiting FILINGS to a
+	// comma separated list of forms, e.g. "10-K,10-Q".
+	ParameterForms = "forms"
+	// ParameterConcepts is the spec.source.parameters key limiting FACTS to a
+	// comma separated list of concepts, either bare, e.g. "Revenues", or
+	// qualified by taxonomy, e.g. "us-gaap:Revenues".
+	ParameterConcepts = "concepts"
+)
+
+type Provider struct {
+	client *HTTPClient
+}
+
+type filingsData struct {
+	cik     string
+	filings []SubmittedFiling
+}
+
+type factsData struct {
+	cik   string
+	facts *CompanyFacts
+}
+
+func NewProvider(client *HTTPClient) *Provider {
+	return &Provider{
+		client: client,
+	}
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityHistorical, provider.CapabilityLatest}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{EndpointFilings, EndpointFacts}
+}
+
+// Limits is SEC's fair access limit, which the HTTPClient paces its requests
+// to.
+func (p *Provider) Limits() provider.RequestLimits {
+	const (
+		secondsPerMinute = 60
+	)
+
+	return provider.RequestLimits{
+		RequestsPerMinute: MaxRequestsPerSecond * secondsPerMinute,
+		Burst:             1,
+	}
+}
+
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	cik, err := p.client.LookupCIK(ctx, request.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.Endpoint {
+	case EndpointFilings:
+		data := filingsData{cik: cik, filings: []SubmittedFiling{}}
+		bytesFetched := int64(0)
+
+		for filing, fetchError := range p.client.AllFilings(ctx, cik, request.From, &bytesFetched) {
+			if fetchError != nil {
+				return nil, fetchError
+			}
+
+			data.filings = append(data.filings, filing)
+		}
+
+		return &provider.Payload{
+			Request:      request,
+			Data:         data,
+			BytesFetched: bytesFetched,
+		}, nil
+
+	case EndpointFacts:
+		facts, fetchError := p.client.CompanyFacts(ctx, cik)
+		if fetchError != nil {
+			return nil, fetchError
+		}
+
+		return &provider.Payload{
+			Request:      request,
+			Data:         factsData{cik: cik, facts: facts},
+			BytesFetched: facts.BytesFetched,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+}
+
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	switch data := payload.Data.(type) {
+	case filingsData:
+		return normalizeFilings(payload.Request, data)
+	case factsData:
+		return normalizeFacts(payload.Request, data)
+	default:
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+}
+
+// normalizeFilings converts the filings of a registrant into filings. Filings
+// outside the request window, or of forms the request excludes, are skipped.
+func normalizeFilings(request provider.Request, data filingsData) ([]records.Record, error) {
+	forms := parseList(request.Parameters[ParameterForms])
+
+	normalized := []records.Record{}
+
+	for _, filing := range data.filings {
+		if len(forms) > 0 && !slices.Contains(forms, filing.Form) {
+			continue
+		}
+
+		filingDate, err := parseDate(filing.FilingDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filing date %q: %w", filing.FilingDate, err)
+		}
+
+		if !request.Contains(filingDate) {
+			continue
+		}
+
+		reportDate, err := parseDate(filing.ReportDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid report date %q: %w", filing.ReportDate, err)
+		}
+
+		acceptedAt := time.Time{}
+		if filing.AcceptanceDateTime != "" {
+			acceptedAt, err = time.Parse(time.RFC3339, filing.AcceptanceDateTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid acceptance time %q: %w", filing.AcceptanceDateTime, err)
+			}
+		}
+
+		normalized = append(normalized, records.Filing{
+			Symbol:          strings.ToUpper(request.Symbol),
+			CIK:             data.cik,
+			AccessionNumber: filing.AccessionNumber,
+			Form:            filing.Form,
+			FilingDate:      filingDate,
+			ReportDate:      reportDate,
+			AcceptedAt:      acceptedAt.UTC(),
+			PrimaryDocument: filing.PrimaryDocument,
+			Source:          SourceType,
+		})
+	}
+
+	return normalized, nil
+}
+
+// normalizeFacts converts the XBRL facts of a registrant into facts. Facts for
+// a period ending outside the request window, or of concepts the request
+// excludes, are skipped.
+func normalizeFacts(request provider.Request, data factsData) ([]records.Record, error) {
+	concepts := parseList(request.Parameters[ParameterConcepts])
+
+	normalized := []records.Record{}
+
+	for _, taxonomy := range slices.Sorted(maps.Keys(data.facts.Facts)) {
+		for _, concept := range slices.Sorted(maps.Keys(data.facts.Facts[taxonomy])) {
+			if len(concepts) > 0 &&
+				!slices.Contains(concepts, concept) && !slices.Contains(concepts, taxonomy+":"+concept) {
+				continue
+			}
+
+			units := data.facts.Facts[taxonomy][concept].Units
+			for _, unit := range slices.Sorted(maps.Keys(units)) {
+				for _, value := range units[unit] {
+					fact, err := newFact(request, data.cik, taxonomy, concept, unit, value)
+					if err != nil {
+						return nil, err
+					}
+
+					if request.Contains(fact.PeriodEnd) {
+						normalized = append(normalized, fact)
+					}
+				}
+			}
+		}
+	}
+
+	return normalized, nil
+}
+
+func newFact(
+	request provider.Request,
+	cik string,
+	taxonomy string,
+	concept string,
+	unit string,
+	value FactValue,
+) (records.Fact, error) {
+	periodStart, startError := parseDate(value.Start)
+	periodEnd, endError := parseDate(value.End)
+	filingDate, filedError := parseDate(value.Filed)
+
+	if err := errors.Join(startError, endError, filedError); err != nil {
+		return records.Fact{}, fmt.Errorf("invalid date in %s:%s fact of %s: %w", taxonomy, concept, value.Accession, err)
+	}
+
+	return records.Fact{
+		Symbol:          strings.ToUpper(request.Symbol),
+		CIK:             cik,
+		Taxonomy:        taxonomy,
+		Concept:         concept,
+		Unit:            unit,
+		Value:           value.Value,
+		PeriodStart:     periodStart,
+		PeriodEnd:       periodEnd,
+		FiscalYear:      value.FiscalYear,
+		FiscalPeriod:    value.FiscalPeriod,
+		Form:            value.Form,
+		FilingDate:      filingDate,
+		AccessionNumber: value.Accession,
+		Frame:           value.Frame,
+		Source:          SourceType,
+	}, nil
+}
+
+// parseDate parses an EDGAR date, returning the zero time for an empty date.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.ParseInLocation(time.DateOnly, value, time.UTC)
+}
+
+func parseList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,189 @@
+package edgar
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+// Submissions is the filing history of a registrant as returned by the
+// submissions API. Recent holds at least the last year of filings, or the
+// last 1,000, in columns indexed alike. Older filings are in Files, newest
+// first.
+type Submissions struct {
+	CIK     string   `json:"cik"`
+	Name    string   `json:"name"`
+	Tickers []string `json:"tickers"`
+	Filings struct {
+		Recent RecentFilings     `json:"recent"`
+		Files  []SubmissionsFile `json:"files"`
+	} `json:"filings"`
+	BytesFetched int64 `json:"-"`
+}
+
+// SubmissionsFile is a file of older filings of a registrant, in the columns
+// of RecentFilings.
+type SubmissionsFile struct {
+	Name        string `json:"name"`
+	FilingCount int    `json:"filingCount"`
+	FilingFrom  string `json:"filingFrom"`
+	FilingTo    string `json:"filingTo"`
+}
+
+// OlderFilings are the filings of a SubmissionsFile.
+type OlderFilings struct {
+	RecentFilings
+
+	BytesFetched int64 `json:"-"`
+}
+
+// SubmittedFiling is a filing of RecentFilings.
+type SubmittedFiling struct {
+	AccessionNumber    string
+	FilingDate         string
+	ReportDate         string
+	AcceptanceDateTime string
+	Form               string
+	PrimaryDocument    string
+}
+
+type RecentFilings struct {
+	AccessionNumber    []string `json:"accessionNumber"`
+	FilingDate         []string `json:"filingDate"`
+	ReportDate         []string `json:"reportDate"`
+	AcceptanceDateTime []string `json:"acceptanceDateTime"`
+	Form               []string `json:"form"`
+	PrimaryDocument    []string `json:"primaryDocument"`
+}
+
+// Submissions returns the filing history of the registrant with the ten digit
+// cik.
+func (h *HTTPClient) Submissions(ctx context.Context, cik string) (*Submissions, error) {
+	submissions := &Submissions{}
+
+	bytesFetched, err := h.getJSON(ctx, fmt.Sprintf("%s/submissions/CIK%s.json", h.baseURL, cik), submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions.BytesFetched = bytesFetched
+
+	return submissions, nil
+}
+
+// OlderFilings returns the filings of the submissions file of a registrant
+// named name.
+func (h *HTTPClient) OlderFilings(ctx context.Context, name string) (*OlderFilings, error) {
+	filings := &OlderFilings{}
+
+	bytesFetched, err := h.getJSON(ctx, fmt.Sprintf("%s/submissions/%s", h.baseURL, name), filings)
+	if err != nil {
+		return nil, err
+	}
+
+	filings.BytesFetched = bytesFetched
+
+	return filings, nil
+}
+
+// AllFilings returns the filings of the registrant with the ten digit cik,
+// newest first: the recent filings, then those of each older submissions
+// file. A file is requested only once the filings before it were consumed,
+// and paging stops at the first filing made before from. The bytes of each
+// response are added to bytesFetched, if it is not nil.
+func (h *HTTPClient) AllFilings(
+	ctx context.Context,
+	cik string,
+	from time.Time,
+	bytesFetched *int64,
+) iter.Seq2[SubmittedFiling, error] {
+	// The cursor of the recent filings is empty, and that of older filings
+	// the name of their file, which the recent filings list.
+	var files []string
+
+	getNextCursor := func(cursor string) string {
+		// The recent filings are not in files, so they are followed by the
+		// first file.
+		next := slices.Index(files, cursor) + 1
+		if next >= len(files) {
+			return ""
+		}
+
+		return files[next]
+	}
+
+	fetch := func(ctx context.Context, position httpUtil.PagePosition) (httpUtil.Page[SubmittedFiling], error) {
+		var filings RecentFilings
+		var bytes int64
+
+		if position.Cursor == "" {
+			submissions, err := h.Submissions(ctx, cik)
+			if err != nil {
+				return httpUtil.Page[SubmittedFiling]{}, err
+			}
+
+			for _, file := range submissions.Filings.Files {
+				files = append(files, file.Name)
+			}
+
+			filings, bytes = submissions.Filings.Recent, submissions.BytesFetched
+		} else {
+			older, err := h.OlderFilings(ctx, position.Cursor)
+			if err != nil {
+				return httpUtil.Page[SubmittedFiling]{}, err
+			}
+
+			filings, bytes = older.RecentFilings, older.BytesFetched
+		}
+
+		if bytesFetched != nil {
+			*bytesFetched += bytes
+		}
+
+		return httpUtil.Page[SubmittedFiling]{
+			Items:      filings.GetFilings(),
+			NextCursor: getNextCursor(position.Cursor),
+		}, nil
+	}
+
+	return httpUtil.Paginate(ctx, httpUtil.CursorPagination{}, fetch, httpUtil.PageOptions[SubmittedFiling]{
+		Stop: func(filing SubmittedFiling) bool {
+			filingDate, err := parseDate(filing.FilingDate)
+			return err == nil && !filingDate.IsZero() && filingDate.Before(from)
+		},
+	})
+}
+
+// GetFilings returns the filings as rows.
+func (r RecentFilings) GetFilings() []SubmittedFiling {
+	filings := make([]SubmittedFiling, r.Len())
+	for i := range filings {
+		filings[i] = SubmittedFiling{
+			AccessionNumber:    r.AccessionNumber[i],
+			FilingDate:         r.FilingDate[i],
+			ReportDate:         r.ReportDate[i],
+			AcceptanceDateTime: r.AcceptanceDateTime[i],
+			Form:               r.Form[i],
+			PrimaryDocument:    r.PrimaryDocument[i],
+		}
+	}
+
+	return filings
+}
+
+// Len returns the number of filings, which is the length of the shortest
+// column so a malformed response cannot index out of range.
+func (r RecentFilings) Len() int {
+	return min(
+		len(r.AccessionNumber),
+		len(r.FilingDate),
+		len(r.ReportDate),
+		len(r.AcceptanceDateTime),
+		len(r.Form),
+		len(r.PrimaryDocument),
+	)
+}
@@ -0,0 +1,222 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+const (
+	// ParameterPath is the file or glob to import. "{symbol}" is replaced by
+	// the symbol of each target, for dumps with a file per security.
+	ParameterPath = "path"
+	// ParameterFormat is csv, jsonl or parquet. By default it is taken from
+	// the file extension.
+	ParameterFormat = "format"
+	// ParameterTimezone is the IANA timezone of times without an offset. It
+	// defaults to UTC.
+	ParameterTimezone = "timezone"
+	// ParameterTimeFormat is the Go layout of the time column, or "unix" or
+	// "unixms" for epoch times. By default common layouts are tried in turn.
+	ParameterTimeFormat = "timeFormat"
+	ParameterDelimiter  = "delimiter"
+	ParameterBatchSize  = "batchSize"
+	// ParameterResolution is the resolution of imported bars, daily by
+	// default.
+	ParameterResolution = "resolution"
+	// ParameterColumnPrefix prefixes the parameters that map a field to the
+	// column holding it, e.g. "column.close: Adj Close". Unmapped fields are
+	// read from the column of the same name.
+	ParameterColumnPrefix = "column."
+
+	TimeFormatUnix      = "unix"
+	TimeFormatUnixMilli = "unixms"
+
+	symbolPlaceholder = "{symbol}"
+)
+
+// Fields of imported bars that can be mapped to columns.
+const (
+	FieldSymbol = "symbol"
+	FieldTime   = "time"
+	FieldOpen   = "open"
+	FieldHigh   = "high"
+	FieldLow    = "low"
+	FieldClose  = "close"
+	FieldVolume = "volume"
+)
+
+// defaultTimeLayouts are tried in turn when no time format is configured.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var defaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	time.DateTime,
+	time.DateOnly,
+	"01/02/2006",
+	"20060102",
+}
+
+// importOptions are the spec.source.parameters of a FILE collection.
+type importOptions struct {
+	path       string
+	format     string
+	location   *time.Location
+	timeFormat string
+	delimiter  rune
+	batchSize  int
+	resolution records.Resolution
+	columns    map[string]string
+	// symbolMapped is set when rows name their security, in which case rows
+	// of other securities are skipped.
+	symbolMapped bool
+}
+
+func parseOptions(request provider.Request) (importOptions, error) {
+	const (
+		defaultBatchSize = 5000
+	)
+
+	parameters := request.Parameters
+
+	options := importOptions{
+		path:       parameters[ParameterPath],
+		format:     strings.ToLower(parameters[ParameterFormat]),
+		location:   time.UTC,
+		timeFormat: parameters[ParameterTimeFormat],
+		delimiter:  ',',
+		batchSize:  defaultBatchSize,
+		columns:    map[string]string{},
+	}
+
+	if options.path == "" {
+		return options, fmt.Errorf("source type %s requires the %s parameter", SourceType, ParameterPath)
+	}
+
+	if options.format != "" && !slices.Contains([]string{FormatCSV, FormatJSONL, FormatParquet}, options.format) {
+		return options, fmt.Errorf("unsupported file format %q", options.format)
+	}
+
+	if timezone := parameters[ParameterTimezone]; timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return options, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		options.location = location
+	}
+
+	if delimiter := parameters[ParameterDelimiter]; delimiter != "" {
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return options, fmt.Errorf("invalid delimiter %q, expected a single character", delimiter)
+		}
+
+		options.delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	}
+
+	if batchSize := parameters[ParameterBatchSize]; batchSize != "" {
+		parsed, err := strconv.Atoi(batchSize)
+		if err != nil || parsed <= 0 {
+			return options, fmt.Errorf("invalid batch size %q", batchSize)
+		}
+		options.batchSize = parsed
+	}
+
+	resolution, err := records.ParseResolution(parameters[ParameterResolution])
+	if err != nil {
+		return options, err
+	}
+
+	options.resolution = resolution
+
+	for _, field := range []string{FieldSymbol, FieldTime, FieldOpen, FieldHigh, FieldLow, FieldClose, FieldVolume} {
+		column, mapped := parameters[ParameterColumnPrefix+field]
+		if !mapped {
+			column = field
+		}
+
+		options.columns[field] = column
+		if field == FieldSymbol {
+			options.symbolMapped = mapped
+		}
+	}
+
+	return options, nil
+}
+
+// files returns the files to import for symbol, in name order.
+func (o importOptions) files(symbol string) ([]string, error) {
+	pattern := strings.ReplaceAll(o.path, symbolPlaceholder, symbol)
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", pattern, err)
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
+// formatOf returns the format of path, from the format parameter or the file
+// extension.
+func (o importOptions) formatOf(path string) (string, error) {
+	if o.format != "" {
+		return o.format, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".parquet", ".pq":
+		return FormatParquet, nil
+	}
+
+	return "", fmt.Errorf("cannot tell the format of %s, set the %s parameter", path, ParameterFormat)
+}
+
+// parseTime parses the time of a row. Times without an offset are read in the
+// import's timezone.
+func (o importOptions) parseTime(value string) (time.Time, error) {
+	switch o.timeFormat {
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch time %q", value)
+		}
+
+		if o.timeFormat == TimeFormatUnixMilli {
+			return time.UnixMilli(epoch).UTC(), nil
+		}
+
+		return time.Unix(epoch, 0).UTC(), nil
+
+	case "":
+		for _, layout := range defaultTimeLayouts {
+			if t, err := time.ParseInLocation(layout, value, o.location); err == nil {
+				return t, nil
+			}
+		}
+
+		return time.Time{}, fmt.Errorf("unrecognized time %q, set the %s parameter", value, ParameterTimeFormat)
+	}
+
+	return time.ParseInLocation(o.timeFormat, value, o.location)
+}
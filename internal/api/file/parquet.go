@@ -0,0 +1,185 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// The Parquet reader supports the files vendors and dataframe libraries write
+// for tabular data: flat schemas of required or optional columns. Values are
+// formatted as strings so Parquet rows map to fields the same way CSV rows do.
+
+// parquetColumn is a leaf column of a flat Parquet schema.
+type parquetColumn struct {
+	name string
+	kind parquet.Kind
+	date bool
+	// timeUnit is set for timestamp columns, which are formatted in UTC when
+	// utc is set and as wall clock times otherwise.
+	timeUnit time.Duration
+	utc      bool
+}
+
+// parquetReader reads the rows of a Parquet file, one row group after the
+// other.
+type parquetReader struct {
+	reader  *parquet.Reader
+	columns []parquetColumn
+	rows    []parquet.Row
+}
+
+func newParquetReader(file io.ReaderAt, size int64) (*parquetReader, error) {
+	parquetFile, err := parquet.OpenFile(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Parquet file: %w", err)
+	}
+
+	columns, err := parquetColumns(parquetFile.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	return &parquetReader{
+		reader:  parquet.NewReader(parquetFile),
+		columns: columns,
+		rows:    make([]parquet.Row, 1),
+	}, nil
+}
+
+// Read returns the next row as values by column name, without the columns that
+// are null. It returns io.EOF after the last row.
+func (r *parquetReader) Read() (map[string]string, error) {
+	n, err := r.reader.ReadRows(r.rows)
+	if n == 0 {
+		if err == nil || errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read Parquet row: %w", err)
+	}
+
+	row := make(map[string]string, len(r.columns))
+	for _, value := range r.rows[0] {
+		if value.IsNull() || value.Column() >= len(r.columns) {
+			continue
+		}
+
+		column := r.columns[value.Column()]
+		row[column.name] = column.format(value)
+	}
+
+	return row, nil
+}
+
+func (r *parquetReader) Close() error {
+	return r.reader.Close()
+}
+
+// parquetColumns returns the leaf columns of schema, by column index. Nested
+// and repeated columns cannot be mapped to fields, so they are rejected.
+func parquetColumns(schema *parquet.Schema) ([]parquetColumn, error) {
+	paths := schema.Columns()
+	columns := make([]parquetColumn, len(paths))
+
+	for _, path := range paths {
+		if len(path) != 1 {
+			return nil, fmt.Errorf("nested Parquet column %s is not supported", strings.Join(path, "."))
+		}
+
+		leaf, _ := schema.Lookup(path...)
+		if leaf.Node.Repeated() {
+			return nil, fmt.Errorf("repeated Parquet column %s is not supported", path[0])
+		}
+
+		columnType := leaf.Node.Type()
+		columns[leaf.ColumnIndex] = parquetColumn{name: path[0], kind: columnType.Kind()}
+		columns[leaf.ColumnIndex].annotate(columnType.LogicalType())
+	}
+
+	return columns, nil
+}
+
+// annotate reads the date and timestamp annotations of a column. Legacy
+// converted types are reported as their logical type.
+func (c *parquetColumn) annotate(logicalType *format.LogicalType) {
+	switch {
+	case logicalType == nil:
+	case logicalType.Date != nil:
+		c.date = true
+	case logicalType.Timestamp != nil:
+		c.utc = logicalType.Timestamp.IsAdjustedToUTC
+
+		switch unit := logicalType.Timestamp.Unit; {
+		case unit.Millis != nil:
+			c.timeUnit = time.Millisecond
+		case unit.Micros != nil:
+			c.timeUnit = time.Microsecond
+		case unit.Nanos != nil:
+			c.timeUnit = time.Nanosecond
+		}
+	}
+}
+
+func (c parquetColumn) format(value parquet.Value) string {
+	switch c.kind {
+	case parquet.Boolean:
+		return strconv.FormatBool(value.Boolean())
+	case parquet.Int32:
+		return c.formatInt(int64(value.Int32()))
+	case parquet.Int64:
+		return c.formatInt(value.Int64())
+	case parquet.Int96:
+		return formatInt96(value.Int96())
+	case parquet.Float:
+		return strconv.FormatFloat(float64(value.Float()), 'g', -1, 32)
+	case parquet.Double:
+		return strconv.FormatFloat(value.Double(), 'g', -1, 64)
+	}
+
+	return string(value.ByteArray())
+}
+
+func (c parquetColumn) formatInt(value int64) string {
+	const (
+		hoursPerDay = 24
+	)
+
+	switch {
+	case c.date:
+		return time.Unix(0, 0).UTC().Add(time.Duration(value) * hoursPerDay * time.Hour).Format(time.DateOnly)
+
+	case c.timeUnit != 0:
+		t := time.Unix(0, 0).UTC().Add(time.Duration(value) * c.timeUnit)
+		if c.utc {
+			return t.Format(time.RFC3339Nano)
+		}
+
+		// Timestamps that are not adjusted to UTC are wall clock times, so
+		// they are read in the import's timezone.
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+
+	return strconv.FormatInt(value, 10)
+}
+
+// formatInt96 formats the legacy INT96 timestamps written by Spark and Impala:
+// nanoseconds within the day followed by the Julian day number.
+func formatInt96(value deprecated.Int96) string {
+	const (
+		julianUnixEpoch = 2440588
+		hoursPerDay     = 24
+	)
+
+	nanoseconds := time.Duration(uint64(value[1])<<32 | uint64(value[0]))
+	days := int64(value[2]) - julianUnixEpoch
+
+	return time.Unix(days*hoursPerDay*int64(time.Hour/time.Second), 0).UTC().Add(nanoseconds).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,99 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/utility"
+)
+
+// Progress remembers how far each file import got, so an interrupted import
+// resumes after the last batch it wrote instead of starting over, also after
+// the daemon restarts. Imports are kept in a single JSON file that is
+// replaced atomically on every update.
+type Progress struct {
+	mu      sync.Mutex
+	path    string
+	imports map[string]ImportProgress
+}
+
+// ImportProgress is the progress of importing one file for one target. The
+// size and modification time of the file are recorded so a file that changed
+// since is imported again from the start.
+type ImportProgress struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	Rows      int64     `json:"rows"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OpenProgress loads the import progress at path. An empty path keeps the
+// progress in memory only.
+func OpenProgress(path string) (*Progress, error) {
+	const (
+		progressDirPerm = 0755
+	)
+
+	progress := &Progress{
+		path:    path,
+		imports: map[string]ImportProgress{},
+	}
+
+	if path == "" {
+		return progress, nil
+	}
+
+	if err := utility.CreateParentDir(path, progressDirPerm); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if unmarshalError := json.Unmarshal(data, &progress.imports); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to read import progress %s: %w", path, unmarshalError)
+	}
+
+	return progress, nil
+}
+
+func (p *Progress) Get(key string) (ImportProgress, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	progress, exists := p.imports[key]
+
+	return progress, exists
+}
+
+func (p *Progress) Set(key string, progress ImportProgress) error {
+	const (
+		progressFilePerm = 0644
+	)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.imports[key] = progress
+
+	if p.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(p.imports, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utility.WriteFileAtomic(p.path, data, progressFilePerm)
+}
@@ -0,0 +1,348 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Streamer interface
+
+const (
+	SourceType = "FILE"
+
+	EndpointBars = "BARS"
+)
+
+// Provider imports records from local files. Files are streamed in batches,
+// and the progress of each file is recorded after its batch is written so an
+// interrupted import resumes where it stopped.
+type Provider struct {
+	progress *Progress
+}
+
+// Row is a row of an import file. Line is its position among the file's rows,
+// starting at one.
+type Row struct {
+	Path   string
+	Line   int64
+	Values map[string]string
+}
+
+// Batch is a run of rows read from import files.
+type Batch struct {
+	Rows []Row
+}
+
+func NewProvider(progress *Progress) *Provider {
+	return &Provider{
+		progress: progress,
+	}
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityHistorical}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{EndpointBars}
+}
+
+// Limits are unbounded, since imports make no network requests.
+func (p *Provider) Limits() provider.RequestLimits {
+	return provider.RequestLimits{}
+}
+
+// Fetch reads every matching file into a single payload without recording
+// progress. Workers stream imports instead.
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	collected := &Batch{}
+	bytesFetched := int64(0)
+
+	err := p.stream(ctx, request, false, func(payload *provider.Payload) error {
+		batch, _ := payload.Data.(*Batch)
+		collected.Rows = append(collected.Rows, batch.Rows...)
+		bytesFetched += payload.BytesFetched
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{Request: request, Data: collected, BytesFetched: bytesFetched}, nil
+}
+
+func (p *Provider) Stream(ctx context.Context, request provider.Request, write func(*provider.Payload) error) error {
+	return p.stream(ctx, request, true, write)
+}
+
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	batch, ok := payload.Data.(*Batch)
+	if !ok {
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+
+	options, err := parseOptions(payload.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]records.Record, 0, len(batch.Rows))
+
+	for _, row := range batch.Rows {
+		bar, include, parseError := options.parseBar(payload.Request.Symbol, row.Values)
+		if parseError != nil {
+			// A malformed row in a vendor dump should not stop years of good
+			// rows from being imported.
+			logger.Warnf("Skipping row %d of %s: %v", row.Line, row.Path, parseError)
+			continue
+		}
+
+		if include && payload.Request.Contains(bar.Time) {
+			normalized = append(normalized, bar)
+		}
+	}
+
+	return normalized, nil
+}
+
+func (p *Provider) stream(
+	ctx context.Context,
+	request provider.Request,
+	track bool,
+	write func(*provider.Payload) error,
+) error {
+	if request.Endpoint != EndpointBars {
+		return fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+
+	options, err := parseOptions(request)
+	if err != nil {
+		return err
+	}
+
+	paths, err := options.files(request.Symbol)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if importError := p.importFile(ctx, request, options, path, track, write); importError != nil {
+			return fmt.Errorf("failed to import %s: %w", path, importError)
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) importFile(
+	ctx context.Context,
+	request provider.Request,
+	options importOptions,
+	path string,
+	track bool,
+	write func(*provider.Payload) error,
+) error {
+	format, err := options.formatOf(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	key := progressKey(request, path)
+	progress := ImportProgress{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+
+	if track {
+		previous, exists := p.progress.Get(key)
+		if exists && previous.Size == progress.Size && previous.ModTime.Equal(progress.ModTime) {
+			if previous.Done {
+				logger.Debugf("Skipping %s, which was already imported for %s", path, request.Symbol)
+				return nil
+			}
+
+			logger.Infof("Resuming import of %s for %s after row %d", path, request.Symbol, previous.Rows)
+			progress.Rows = previous.Rows
+		}
+	}
+
+	counted := &countingFile{file: file}
+
+	reader, err := newRowReader(format, counted, info.Size(), options.delimiter)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	batch := &Batch{Rows: make([]Row, 0, options.batchSize)}
+	line := int64(0)
+	bytesWritten := int64(0)
+
+	flush := func(done bool) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		bytesRead := counted.bytesRead.Load()
+		payload := &provider.Payload{Request: request, Data: batch, BytesFetched: bytesRead - bytesWritten}
+		bytesWritten = bytesRead
+
+		if writeError := write(payload); writeError != nil {
+			return writeError
+		}
+
+		batch = &Batch{Rows: make([]Row, 0, options.batchSize)}
+
+		if !track {
+			return nil
+		}
+
+		progress.Rows = line
+		progress.Done = done
+		progress.UpdatedAt = time.Now()
+
+		return p.progress.Set(key, progress)
+	}
+
+	for {
+		values, readError := reader.Read()
+		if errors.Is(readError, io.EOF) {
+			break
+		} else if readError != nil {
+			return fmt.Errorf("failed to read row %d: %w", line+1, readError)
+		}
+
+		line++
+
+		// Rows up to the recorded progress were written before the import
+		// was interrupted.
+		if line <= progress.Rows {
+			continue
+		}
+
+		batch.Rows = append(batch.Rows, Row{Path: path, Line: line, Values: values})
+
+		if len(batch.Rows) >= options.batchSize {
+			if flushError := flush(false); flushError != nil {
+				return flushError
+			}
+		}
+	}
+
+	return flush(true)
+}
+
+// parseBar maps a row to a bar of symbol. It returns false for rows of other
+// securities.
+func (o importOptions) parseBar(symbol string, row map[string]string) (records.Bar, bool, error) {
+	barSymbol := strings.ToUpper(symbol)
+	if o.symbolMapped {
+		rowSymbol := strings.ToUpper(strings.TrimSpace(row[o.columns[FieldSymbol]]))
+		if rowSymbol != barSymbol {
+			return records.Bar{}, false, nil
+		}
+	}
+
+	value, exists := row[o.columns[FieldTime]]
+	if !exists {
+		return records.Bar{}, false, fmt.Errorf("missing column %q", o.columns[FieldTime])
+	}
+
+	start, err := o.parseTime(strings.TrimSpace(value))
+	if err != nil {
+		return records.Bar{}, false, err
+	}
+
+	// Daily bars are stamped at midnight UTC of their trading date, whatever
+	// the timezone of the file.
+	if !o.resolution.IsIntraday() {
+		local := start.In(o.location)
+		start = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	bar := records.Bar{
+		Symbol:     barSymbol,
+		Resolution: o.resolution,
+		Time:       start.UTC(),
+		Source:     SourceType,
+	}
+
+	prices := []struct {
+		field    string
+		target   *float64
+		optional bool
+	}{
+		{FieldOpen, &bar.Open, false},
+		{FieldHigh, &bar.High, false},
+		{FieldLow, &bar.Low, false},
+		{FieldClose, &bar.Close, false},
+		{FieldVolume, &bar.Volume, true},
+	}
+
+	for _, price := range prices {
+		value, exists := row[o.columns[price.field]]
+		if !exists {
+			if price.optional {
+				continue
+			}
+
+			return records.Bar{}, false, fmt.Errorf("missing column %q", o.columns[price.field])
+		}
+
+		parsed, parseError := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+		if parseError != nil {
+			return records.Bar{}, false, fmt.Errorf("invalid %s %q", price.field, value)
+		}
+
+		*price.target = parsed
+	}
+
+	return bar, true, nil
+}
+
+// progressKey identifies the import of path for a request. The parameters are
+// part of the key, so importing a file again with a different mapping starts
+// over.
+func progressKey(request provider.Request, path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s", request.Endpoint, strings.ToUpper(request.Symbol), path)
+
+	for _, key := range slices.Sorted(maps.Keys(request.Parameters)) {
+		fmt.Fprintf(hash, "\x00%s=%s", key, request.Parameters[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
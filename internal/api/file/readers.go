@@ -0,0 +1,156 @@
+package file
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// rowReader reads the rows of an import file as values by column name. Read
+// returns io.EOF after the last row.
+type rowReader interface {
+	Read() (map[string]string, error)
+	Close() error
+}
+
+// countingFile counts the bytes read from an import file.
+type countingFile struct {
+	file interface {
+		io.Reader
+		io.ReaderAt
+	}
+	bytesRead atomic.Int64
+}
+
+func (c *countingFile) Read(p []byte) (int, error) {
+	n, err := c.file.Read(p)
+	c.bytesRead.Add(int64(n))
+
+	return n, err
+}
+
+func (c *countingFile) ReadAt(p []byte, offset int64) (int, error) {
+	n, err := c.file.ReadAt(p, offset)
+	c.bytesRead.Add(int64(n))
+
+	return n, err
+}
+
+func newRowReader(format string, file *countingFile, size int64, delimiter rune) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(file, delimiter)
+	case FormatJSONL:
+		return newJSONLReader(file), nil
+	case FormatParquet:
+		return newParquetReader(file, size)
+	}
+
+	return nil, fmt.Errorf("unsupported file format %q", format)
+}
+
+// csvReader reads CSV files whose first record names the columns.
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+func newCSVReader(file io.Reader, delimiter rune) (*csvReader, error) {
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV file has no header")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, column := range header {
+		columns[i] = strings.TrimSpace(column)
+	}
+
+	// Spreadsheet exports often start with a byte order mark.
+	columns[0] = strings.TrimPrefix(columns[0], "\ufeff")
+
+	return &csvReader{
+		reader: reader,
+		header: columns,
+	}, nil
+}
+
+func (r *csvReader) Read() (map[string]string, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]string, len(r.header))
+	for i, value := range record {
+		if value != "" {
+			row[r.header[i]] = value
+		}
+	}
+
+	return row, nil
+}
+
+func (r *csvReader) Close() error {
+	return nil
+}
+
+// jsonlReader reads files of one JSON object per line.
+type jsonlReader struct {
+	decoder *json.Decoder
+}
+
+func newJSONLReader(file io.Reader) *jsonlReader {
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+
+	return &jsonlReader{
+		decoder: decoder,
+	}
+}
+
+func (r *jsonlReader) Read() (map[string]string, error) {
+	object := map[string]any{}
+	if err := r.decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]string, len(object))
+	for key, value := range object {
+		switch typed := value.(type) {
+		case nil:
+		case string:
+			row[key] = typed
+		case json.Number:
+			row[key] = typed.String()
+		case bool:
+			row[key] = strconv.FormatBool(typed)
+		default:
+			encoded, _ := json.Marshal(typed)
+			row[key] = string(encoded)
+		}
+	}
+
+	return row, nil
+}
+
+func (r *jsonlReader) Close() error {
+	return nil
+}
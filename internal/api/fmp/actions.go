@@ -0,0 +1,71 @@
+package fmp
+
+import (
+	"context"
+	"time"
+)
+
+// ActionQuery selects the corporate actions of a symbol.
+type ActionQuery struct {
+	Symbol string
+}
+
+// StockSplit is a stock split as returned by FMP.
+type StockSplit struct {
+	Symbol      string  `json:"symbol"`
+	Date        string  `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+}
+
+// StockDividend is a cash dividend as returned by FMP. Dividend is the amount
+// as declared, and AdjDividend the amount adjusted for later splits. Dates
+// FMP does not have are empty.
+type StockDividend struct {
+	Symbol          string  `json:"symbol"`
+	Date            string  `json:"date"`
+	RecordDate      string  `json:"recordDate"`
+	PaymentDate     string  `json:"paymentDate"`
+	DeclarationDate string  `json:"declarationDate"`
+	AdjDividend     float64 `json:"adjDividend"`
+	Dividend        float64 `json:"dividend"`
+	Yield           float64 `json:"yield"`
+	Frequency       string  `json:"frequency"`
+}
+
+// Splits returns the splits of the query's symbol, newest first.
+func (h *HTTPClient) Splits(ctx context.Context, query ActionQuery) (*Result[StockSplit], error) {
+	entry, err := LookupEndpoint(EndpointSplits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Actions are selected by count rather than by date window.
+	parameters := entry.parameters(time.Time{}, time.Time{})
+	parameters["symbol"] = query.Symbol
+
+	return getList[StockSplit](ctx, h, entry.Path, parameters)
+}
+
+// Dividends returns the dividends of the query's symbol, newest first.
+func (h *HTTPClient) Dividends(ctx context.Context, query ActionQuery) (*Result[StockDividend], error) {
+	entry, err := LookupEndpoint(EndpointDividends)
+	if err != nil {
+		return nil, err
+	}
+
+	// Actions are selected by count rather than by date window.
+	parameters := entry.parameters(time.Time{}, time.Time{})
+	parameters["symbol"] = query.Symbol
+
+	return getList[StockDividend](ctx, h, entry.Path, parameters)
+}
+
+// parseOptionalDate parses a date, returning the zero time for an empty date.
+func parseOptionalDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.ParseInLocation(time.DateOnly, value, time.UTC)
+}
@@ -1,52 +1,155 @@
 package fmp
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/secrets"
 )
 
-// TODO: Oscar - This should be named FMPClient or something similar. If you're
-// using this pattern for all APIs, then you should make it an interface so that
-// the workers can use any client abstractly.
+const (
+	// DefaultBaseURL is FMP's stable API.
+	DefaultBaseURL = "https://financialmodelingprep.com/stable"
+
+	// APIKeyHeader carries the API key, which FMP also accepts as a query
+	// parameter where it would end up in logged URLs.
+	APIKeyHeader = "apikey"
+)
+
+// TODO: Oscar - This should be named FMPClient or something similar. Workers
+// use it abstractly through Provider, which implements provider.Provider.
 
 type HTTPClient struct {
-	client httpUtil.HTTPClient
-	apiKey string
+	client  httpUtil.HTTPClient
+	apiKey  secrets.Value
+	baseURL string
+}
+
+// Result holds the decoded items of an FMP response.
+type Result[T any] struct {
+	Items        []T
+	BytesFetched int64
 }
 
-func NewHTTPClient(client httpUtil.HTTPClient, apiKey string) *HTTPClient {
+// NewHTTPClient returns a client for the FMP API at baseURL, which defaults to
+// DefaultBaseURL. Requests are made with apiKey unless their context carries
+// another key, see secrets.NewContext.
+func NewHTTPClient(client httpUtil.HTTPClient, apiKey secrets.Value, baseURL string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	// FMP answers some errors, such as an exhausted plan, with a 200 status.
+	client.Cacheable = isCacheable
+
 	return &HTTPClient{
-		client: client,
-		apiKey: apiKey,
+		client:  client,
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 	}
 }
 
-func (h *HTTPClient) Get(endpoint string, data map[string]string) (*http.Response, error) {
-	const (
-		fmpURL = "https://financialmodelingprep.com/stable"
-	)
+// Get requests the FMP path with the given query parameters. The caller owns
+// the response body.
+func (h *HTTPClient) Get(ctx context.Context, path string, data map[string]string) (*http.Response, error) {
+	query := url.Values{}
+	for key, value := range data {
+		query.Set(key, value)
+	}
 
-	if data == nil {
-		data = map[string]string{}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/%s?%s", h.baseURL, path, query.Encode()), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	data["api_key"] = h.apiKey
+	apiKey := h.apiKey
+	if override, ok := secrets.FromContext(ctx); ok {
+		apiKey = override
+	}
 
-	var dataStringBuilder strings.Builder
-	for key, value := range data {
-		dataStringBuilder.WriteString(key)
-		dataStringBuilder.WriteString("=")
-		dataStringBuilder.WriteString(value)
-		dataStringBuilder.WriteString("&")
+	request.Header.Set(APIKeyHeader, apiKey.Reveal())
+
+	return h.client.Do(request)
+}
+
+// getList requests the FMP path and decodes the JSON array it returns. Error
+// payloads become an *APIError and empty arrays become ErrNoData.
+func getList[T any](ctx context.Context, h *HTTPClient, path string, data map[string]string) (*Result[T], error) {
+	response, err := h.Get(ctx, path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiError := decodeAPIError(path, response.StatusCode, body); apiError != nil {
+		return nil, apiError
+	}
+
+	items := []T{}
+	if unmarshalError := json.Unmarshal(body, &items); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to decode FMP %s response: %w", path, unmarshalError)
 	}
 
-	endpoint = fmt.Sprintf("%s/%s?%s", fmpURL, endpoint, dataStringBuilder.String())
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w from FMP %s", ErrNoData, path)
+	}
 
-	ctx := context.Background()
+	return &Result[T]{
+		Items:        items,
+		BytesFetched: int64(len(body)),
+	}, nil
+}
 
-	return h.client.Get(ctx, endpoint)
+// isCacheable reports whether response holds data rather than an error
+// payload, which must not be served from the cache after the error is gone.
+func isCacheable(response *http.Response, body []byte) bool {
+	return decodeAPIError("", response.StatusCode, body) == nil
+}
+
+// decodeAPIError returns the error described by an FMP response, or nil if the
+// response is a successful data payload. FMP reports some errors, such as an
+// invalid API key, with a 200 status and an error object as the body.
+func decodeAPIError(path string, statusCode int, body []byte) *APIError {
+	const (
+		maxMessageLength = 256
+	)
+
+	payload := errorPayload{}
+	isObject := bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+	if isObject {
+		// An undecodable object is left for the caller's decode to report.
+		_ = json.Unmarshal(body, &payload)
+	}
+
+	if statusCode == http.StatusOK && payload.message() == "" {
+		return nil
+	}
+
+	message := payload.message()
+	if message == "" {
+		message = string(bytes.TrimSpace(body))
+		if len(message) > maxMessageLength {
+			message = message[:maxMessageLength] + "..."
+		}
+	}
+
+	return &APIError{
+		Path:       path,
+		StatusCode: statusCode,
+		Message:    message,
+	}
 }
@@ -0,0 +1,140 @@
+package fmp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+const (
+	EndpointNews            = "NEWS"
+	EndpointPrices          = "PRICES"
+	EndpointIncomeStatement = "INCOME_STATEMENT"
+	EndpointBalanceSheet    = "BALANCE_SHEET"
+	EndpointCashFlow        = "CASH_FLOW"
+	EndpointEarnings        = "EARNINGS"
+	EndpointSplits          = "SPLITS"
+	EndpointDividends       = "DIVIDENDS"
+)
+
+const (
+	// statementLimit is the number of statements requested, ten years of
+	// quarterly statements.
+	statementLimit = "40"
+	// earningsLimit is the number of earnings reports requested, which
+	// includes the upcoming reports.
+	earningsLimit = "100"
+	// actionLimit is the number of corporate actions requested, enough for
+	// the full history of most securities.
+	actionLimit = "1000"
+
+	// statementCacheTTL and actionCacheTTL cover data that changes at most
+	// daily, earningsCacheTTL the estimates revised before a report.
+	statementCacheTTL = 24 * time.Hour
+	actionCacheTTL    = 24 * time.Hour
+	earningsCacheTTL  = 6 * time.Hour
+)
+
+// CatalogEntry maps a spec.source.endpoint value of a DataCollection onto the
+// FMP API.
+type CatalogEntry struct {
+	// Path is relative to the FMP base URL.
+	Path string
+	// Parameters are sent with every request to the endpoint.
+	Parameters map[string]string
+	// CacheTTL is how long responses are served from the HTTP cache. Zero
+	// does not cache them.
+	CacheTTL time.Duration
+}
+
+//nolint:gochecknoglobals // gochecknoglobals
+var catalog = map[string]CatalogEntry{
+	EndpointNews: {
+		Path:       "news/stock",
+		Parameters: map[string]string{"limit": strconv.Itoa(newsPageSize)},
+	},
+	EndpointPrices: {
+		Path: "historical-price-eod/full",
+	},
+	EndpointIncomeStatement: {
+		Path:       "income-statement",
+		Parameters: map[string]string{"limit": statementLimit},
+		CacheTTL:   statementCacheTTL,
+	},
+	EndpointBalanceSheet: {
+		Path:       "balance-sheet-statement",
+		Parameters: map[string]string{"limit": statementLimit},
+		CacheTTL:   statementCacheTTL,
+	},
+	EndpointCashFlow: {
+		Path:       "cash-flow-statement",
+		Parameters: map[string]string{"limit": statementLimit},
+		CacheTTL:   statementCacheTTL,
+	},
+	EndpointEarnings: {
+		Path:       "earnings",
+		Parameters: map[string]string{"limit": earningsLimit},
+		CacheTTL:   earningsCacheTTL,
+	},
+	EndpointSplits: {
+		Path:       "splits",
+		Parameters: map[string]string{"limit": actionLimit},
+		CacheTTL:   actionCacheTTL,
+	},
+	EndpointDividends: {
+		Path:       "dividends",
+		Parameters: map[string]string{"limit": actionLimit},
+		CacheTTL:   actionCacheTTL,
+	},
+}
+
+// LookupEndpoint returns the catalog entry for a manifest endpoint name.
+func LookupEndpoint(endpoint string) (CatalogEntry, error) {
+	entry, ok := catalog[endpoint]
+	if !ok {
+		return CatalogEntry{}, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, endpoint, SourceType)
+	}
+
+	return entry, nil
+}
+
+// CatalogEndpoints returns the manifest endpoint names in the catalog.
+func CatalogEndpoints() []string {
+	return slices.Sorted(maps.Keys(catalog))
+}
+
+// CacheTTLs returns the HTTP cache TTLs of the catalog endpoints.
+func CacheTTLs() httpUtil.CacheTTLs {
+	ttls := httpUtil.CacheTTLs{}
+	for _, entry := range catalog {
+		if entry.CacheTTL > 0 {
+			ttls[entry.Path] = entry.CacheTTL
+		}
+	}
+
+	return ttls
+}
+
+// parameters returns the query parameters for a request to the entry, with
+// the window given as dates in exchange time.
+func (e CatalogEntry) parameters(from, to time.Time) map[string]string {
+	parameters := maps.Clone(e.Parameters)
+	if parameters == nil {
+		parameters = map[string]string{}
+	}
+
+	if !from.IsZero() {
+		parameters["from"] = from.In(exchangeLocation).Format(time.DateOnly)
+	}
+
+	if !to.IsZero() {
+		parameters["to"] = to.In(exchangeLocation).Format(time.DateOnly)
+	}
+
+	return parameters
+}
@@ -0,0 +1,42 @@
+package fmp
+
+import (
+	"context"
+	"time"
+)
+
+// EarningsQuery selects the past and upcoming earnings reports of a symbol.
+type EarningsQuery struct {
+	Symbol string
+}
+
+// EarningsReport is an earnings report as returned by FMP. Actuals are null
+// until the report is released.
+type EarningsReport struct {
+	Symbol           string   `json:"symbol"`
+	Date             string   `json:"date"`
+	EPSActual        *float64 `json:"epsActual"`
+	EPSEstimated     *float64 `json:"epsEstimated"`
+	RevenueActual    *float64 `json:"revenueActual"`
+	RevenueEstimated *float64 `json:"revenueEstimated"`
+	LastUpdated      string   `json:"lastUpdated"`
+}
+
+// Earnings returns the earnings reports of the query's symbol, newest first.
+func (h *HTTPClient) Earnings(ctx context.Context, query EarningsQuery) (*Result[EarningsReport], error) {
+	entry, err := LookupEndpoint(EndpointEarnings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reports are selected by count rather than by date window.
+	parameters := entry.parameters(time.Time{}, time.Time{})
+	parameters["symbol"] = query.Symbol
+
+	return getList[EarningsReport](ctx, h, entry.Path, parameters)
+}
+
+// GetReportDate parses the date of the report.
+func (r EarningsReport) GetReportDate() (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, r.Date, time.UTC)
+}
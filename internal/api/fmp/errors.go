@@ -0,0 +1,47 @@
+package fmp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.StatusError interface
+
+// ErrNoData is returned when FMP has no data for a request, for example a
+// symbol it does not cover or a window without news.
+var ErrNoData = errors.New("no data")
+
+// APIError is an error reported by FMP, either as a non-200 status or as an
+// error payload.
+type APIError struct {
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+type errorPayload struct {
+	ErrorMessage string `json:"Error Message"`
+	Error        string `json:"error"`
+	Message      string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("FMP %s request failed (%d %s): %s",
+		e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+func (p errorPayload) message() string {
+	switch {
+	case p.ErrorMessage != "":
+		return p.ErrorMessage
+	case p.Error != "":
+		return p.Error
+	default:
+		return p.Message
+	}
+}
@@ -0,0 +1,543 @@
+package fmptest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+type FaultKind string
+
+const (
+	// FaultRateLimit answers 429 with FMP's limit message.
+	FaultRateLimit FaultKind = "rate-limit"
+	// FaultServerError answers with StatusCode, 503 by default.
+	FaultServerError FaultKind = "server-error"
+	// FaultSlow waits Delay before answering normally.
+	FaultSlow FaultKind = "slow"
+	// FaultMalformed answers 200 with a truncated JSON body.
+	FaultMalformed FaultKind = "malformed"
+
+	// BasePath is the path of the API on the server, like FMP's stable API.
+	BasePath = "/stable"
+
+	// DefaultAPIKey is the key the server accepts until SetAPIKey changes it.
+	DefaultAPIKey = "fmptest-key"
+
+	rateLimitMessage = "Limit Reach . Please upgrade your plan or visit our documentation for more details at " +
+		"https://site.financialmodelingprep.com/"
+)
+
+// Fault makes the server misbehave on matching requests.
+type Fault struct {
+	Kind FaultKind
+	// Path matches the request path relative to BasePath, e.g.
+	// "historical-price-eod/full". Empty matches every path.
+	Path string
+	// Symbol matches the symbol or symbols parameter. Empty matches every
+	// symbol.
+	Symbol string
+	// Times is how many matching requests the fault applies to. Zero applies
+	// it to every matching request.
+	Times int
+	// Delay is how long FaultSlow waits.
+	Delay time.Duration
+	// StatusCode is the status of FaultServerError.
+	StatusCode int
+}
+
+// Request is a request the server received and the status it answered.
+type Request struct {
+	Path       string
+	Query      url.Values
+	StatusCode int
+}
+
+// Server is a fake FMP API serving deterministic data for any symbol: daily
+// and intraday prices, news, statements, earnings, splits and dividends. The
+// same request always gets the same response, unless a Fault is injected.
+type Server struct {
+	*httptest.Server
+
+	// Today is the last day with data, and the end of windows without a "to"
+	// parameter.
+	Today time.Time
+
+	mu       sync.Mutex
+	apiKey   string
+	faults   []*Fault
+	requests []Request
+}
+
+// NewServer starts a fake FMP server. The caller closes it.
+func NewServer() *Server {
+	s := &Server{
+		Today:  time.Date(2025, 4, 17, 0, 0, 0, 0, time.UTC),
+		apiKey: DefaultAPIKey,
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// GetBaseURL returns the base URL to configure an FMP client with.
+func (s *Server) GetBaseURL() string {
+	return s.URL + BasePath
+}
+
+// SetAPIKey changes the key the server accepts. An empty key accepts every
+// request.
+func (s *Server) SetAPIKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKey = apiKey
+}
+
+// Inject adds a fault. Faults are matched in the order they were injected.
+func (s *Server) Inject(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, BasePath), "/")
+	query := r.URL.Query()
+
+	status, body := s.respond(r, path, query)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: path, Query: query, StatusCode: status})
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Server) respond(r *http.Request, path string, query url.Values) (int, []byte) {
+	s.mu.Lock()
+	apiKey := s.apiKey
+	fault := s.takeFault(path, query)
+	s.mu.Unlock()
+
+	// FMP takes the key as a header or a query parameter.
+	if apiKey != "" && cmp.Or(r.Header.Get(fmp.APIKeyHeader), query.Get("apikey")) != apiKey {
+		return http.StatusUnauthorized, errorBody("Invalid API KEY. Feel free to create a Free API Key or visit " +
+			"https://site.financialmodelingprep.com/faqs?search=why-is-my-api-key-invalid for more information.")
+	}
+
+	if fault != nil {
+		switch fault.Kind {
+		case FaultRateLimit:
+			return http.StatusTooManyRequests, errorBody(rateLimitMessage)
+		case FaultServerError:
+			status := fault.StatusCode
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			return status, []byte(http.StatusText(status))
+		case FaultSlow:
+			select {
+			case <-r.Context().Done():
+			case <-time.After(fault.Delay):
+			}
+		case FaultMalformed:
+		}
+	}
+
+	data, found := s.data(path, query)
+	if !found {
+		return http.StatusNotFound, errorBody("Endpoint not found")
+	}
+
+	body, _ := json.Marshal(data)
+
+	if fault != nil && fault.Kind == FaultMalformed {
+		body = body[:len(body)/2]
+	}
+
+	return http.StatusOK, body
+}
+
+// takeFault returns the first fault matching the request and uses up one of
+// its times. s.mu must be held.
+func (s *Server) takeFault(path string, query url.Values) *Fault {
+	symbol := strings.ToUpper(query.Get("symbol") + query.Get("symbols"))
+
+	for i, fault := range s.faults {
+		if fault.Path != "" && fault.Path != path {
+			continue
+		}
+
+		if fault.Symbol != "" && !slices.Contains(strings.Split(symbol, ","), strings.ToUpper(fault.Symbol)) {
+			continue
+		}
+
+		matched := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+
+		return &matched
+	}
+
+	return nil
+}
+
+func (s *Server) data(path string, query url.Values) (any, bool) {
+	symbol := strings.ToUpper(query.Get("symbol"))
+	from, to := s.window(query)
+
+	if resolution, isChart := strings.CutPrefix(path, "historical-chart/"); isChart {
+		parsed, err := records.ParseResolution(resolution)
+		if err != nil || !parsed.IsIntraday() {
+			return nil, false
+		}
+		return intradayPrices(symbol, parsed, from, to), true
+	}
+
+	switch path {
+	case "historical-price-eod/full":
+		return dailyPrices(symbol, from, to), true
+	case "news/stock":
+		articles := news(strings.Split(strings.ToUpper(query.Get("symbols")), ","), from, to)
+		return getPage(articles, query), true
+	case "income-statement", "balance-sheet-statement", "cash-flow-statement":
+		return statements(symbol, path, query.Get("period"), s.Today), true
+	case "earnings":
+		return earnings(symbol), true
+	case "splits":
+		return []fmp.StockSplit{{Symbol: symbol, Date: "2022-06-06", Numerator: 2, Denominator: 1}}, true
+	case "dividends":
+		return dividends(symbol, s.Today), true
+	default:
+		return nil, false
+	}
+}
+
+// window returns the from and to parameters as days. A missing to is Today
+// and a missing from is 30 days before to.
+func (s *Server) window(query url.Values) (time.Time, time.Time) {
+	const (
+		defaultWindowDays = 30
+	)
+
+	to, err := time.Parse(time.DateOnly, query.Get("to"))
+	if err != nil || to.After(s.Today) {
+		to = s.Today
+	}
+
+	from, err := time.Parse(time.DateOnly, query.Get("from"))
+	if err != nil {
+		from = to.AddDate(0, 0, -defaultWindowDays)
+	}
+
+	return from, to
+}
+
+func errorBody(message string) []byte {
+	body, _ := json.Marshal(map[string]string{"Error Message": message})
+	return body
+}
+
+// level is the deterministic price of symbol in the period of length unit
+// that contains t.
+func level(symbol string, t time.Time, unit time.Duration) float64 {
+	const (
+		minBase    = 20
+		baseRange  = 480
+		steps      = 21
+		stepsScale = 1000
+	)
+
+	seed := symbolSeed(symbol)
+	base := minBase + float64(seed%baseRange)
+	period := t.Unix() / int64(unit.Seconds())
+	step := float64((int64(seed)+period*7)%steps-steps/2) / stepsScale
+
+	return round(base * (1 + step))
+}
+
+func bar(symbol string, date string, t time.Time, unit time.Duration) fmp.HistoricalPrice {
+	const (
+		spread     = 0.01
+		baseVolume = 1_000_000
+	)
+
+	open := level(symbol, t.Add(-unit), unit)
+	closing := level(symbol, t, unit)
+
+	return fmp.HistoricalPrice{
+		Symbol: symbol,
+		Date:   date,
+		Open:   open,
+		High:   round(max(open, closing) * (1 + spread)),
+		Low:    round(min(open, closing) * (1 - spread)),
+		Close:  closing,
+		Volume: float64(baseVolume + symbolSeed(symbol)%baseVolume),
+		Change: round(closing - open),
+	}
+}
+
+// dailyPrices returns a bar for each weekday in [from, to], newest first.
+func dailyPrices(symbol string, from time.Time, to time.Time) []fmp.HistoricalPrice {
+	const (
+		day = 24 * time.Hour
+	)
+
+	prices := []fmp.HistoricalPrice{}
+	for date := to; !date.Before(from); date = date.AddDate(0, 0, -1) {
+		if isWeekday(date) {
+			prices = append(prices, bar(symbol, date.Format(time.DateOnly), date, day))
+		}
+	}
+
+	return prices
+}
+
+// intradayPrices returns the bars of the regular session of each weekday in
+// [from, to], newest first, with times in exchange time.
+func intradayPrices(
+	symbol string,
+	resolution records.Resolution,
+	from time.Time,
+	to time.Time,
+) []fmp.HistoricalPrice {
+	const (
+		openHour, openMinute = 9, 30
+		closeHour            = 16
+		dateTimeLayout       = "2006-01-02 15:04:05"
+	)
+
+	exchange, _ := time.LoadLocation("America/New_York")
+	unit := resolution.GetDuration()
+
+	prices := []fmp.HistoricalPrice{}
+	for date := to; !date.Before(from); date = date.AddDate(0, 0, -1) {
+		if !isWeekday(date) {
+			continue
+		}
+
+		open := time.Date(date.Year(), date.Month(), date.Day(), openHour, openMinute, 0, 0, exchange)
+		last := time.Date(date.Year(), date.Month(), date.Day(), closeHour, 0, 0, 0, exchange).Add(-unit)
+		for start := last; !start.Before(open); start = start.Add(-unit) {
+			prices = append(prices, bar(symbol, start.Format(dateTimeLayout), start, unit))
+		}
+	}
+
+	return prices
+}
+
+// news returns an article per symbol for each weekday in [from, to], newest
+// first.
+func news(symbols []string, from time.Time, to time.Time) []fmp.NewsArticle {
+	const (
+		publishHour = 8
+	)
+
+	articles := []fmp.NewsArticle{}
+	for date := to; !date.Before(from); date = date.AddDate(0, 0, -1) {
+		if !isWeekday(date) {
+			continue
+		}
+
+		for _, symbol := range symbols {
+			day := date.Format(time.DateOnly)
+			articles = append(articles, fmp.NewsArticle{
+				Symbol:        symbol,
+				PublishedDate: date.Add(publishHour * time.Hour).Format("2006-01-02 15:04:05"),
+				Publisher:     "FMP Test Wire",
+				Title:         symbol + " market update for " + day,
+				Site:          "example.com",
+				Text:          "A deterministic article about " + symbol + ".",
+				URL:           "https://example.com/news/" + strings.ToLower(symbol) + "/" + day,
+			})
+		}
+	}
+
+	return articles
+}
+
+// getPage returns the page of items selected by the page and limit
+// parameters. Without a limit every item is on page 0.
+func getPage[T any](items []T, query url.Values) []T {
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = len(items)
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+
+	start := min(max(page, 0)*limit, len(items))
+
+	return items[start:min(start+limit, len(items))]
+}
+
+// statements returns the last three fiscal years, or the last eight quarters,
+// newest first.
+func statements(symbol string, path string, period string, today time.Time) []map[string]any {
+	const (
+		years            = 3
+		quarters         = 8
+		monthsPerQuarter = 3
+		revenueScale     = 1_000_000
+		netMargin        = 0.2
+	)
+
+	seed := float64(symbolSeed(symbol) % revenueScale)
+	lastYear := today.Year() - 1
+
+	type statementPeriod struct {
+		end    time.Time
+		year   int
+		period string
+	}
+
+	periods := []statementPeriod{}
+	if period == fmp.PeriodQuarter {
+		for i := range quarters {
+			// The day before the first of the month after the quarter.
+			quarterEnd := time.Date(lastYear, time.December-time.Month(monthsPerQuarter*i)+1, 1, 0, 0, 0, 0, time.UTC).
+				AddDate(0, 0, -1)
+			periods = append(periods, statementPeriod{
+				end:    quarterEnd,
+				year:   quarterEnd.Year(),
+				period: fmt.Sprintf("Q%d", (int(quarterEnd.Month())-1)/monthsPerQuarter+1),
+			})
+		}
+	} else {
+		for i := range years {
+			periods = append(periods, statementPeriod{
+				end:    time.Date(lastYear-i, time.December, 31, 0, 0, 0, 0, time.UTC),
+				year:   lastYear - i,
+				period: "FY",
+			})
+		}
+	}
+
+	result := []map[string]any{}
+	for i, p := range periods {
+		revenue := (seed + 1) * revenueScale * (1 - float64(i)/100)
+
+		statement := map[string]any{
+			"symbol":           symbol,
+			"date":             p.end.Format(time.DateOnly),
+			"reportedCurrency": "USD",
+			"cik":              "0000000000",
+			"filingDate":       p.end.AddDate(0, 1, 0).Format(time.DateOnly),
+			"acceptedDate":     p.end.AddDate(0, 1, 0).Format("2006-01-02 15:04:05"),
+			"fiscalYear":       p.year,
+			"period":           p.period,
+		}
+
+		switch path {
+		case "income-statement":
+			statement["revenue"] = math.Round(revenue)
+			statement["netIncome"] = math.Round(revenue * netMargin)
+		case "balance-sheet-statement":
+			statement["totalAssets"] = math.Round(revenue * 2)
+			statement["totalLiabilities"] = math.Round(revenue)
+		default:
+			statement["operatingCashFlow"] = math.Round(revenue * netMargin * 1.1)
+			statement["freeCashFlow"] = math.Round(revenue * netMargin)
+		}
+
+		result = append(result, statement)
+	}
+
+	return result
+}
+
+// earnings returns four reported quarters and the upcoming report, newest
+// first.
+func earnings(symbol string) []fmp.EarningsReport {
+	dates := []string{"2025-05-01", "2025-01-30", "2024-10-31", "2024-08-01", "2024-05-02"}
+	base := 1 + float64(symbolSeed(symbol)%200)/100
+
+	reports := []fmp.EarningsReport{}
+	for i, date := range dates {
+		estimate := round(base + float64(i)/100)
+		report := fmp.EarningsReport{Symbol: symbol, Date: date, EPSEstimated: &estimate, LastUpdated: date}
+
+		if i > 0 {
+			actual := round(estimate * 1.05)
+			report.EPSActual = &actual
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// dividends returns a quarterly dividend for the last year, newest first.
+func dividends(symbol string, today time.Time) []fmp.StockDividend {
+	const (
+		quarters = 4
+		amount   = 0.25
+	)
+
+	result := []fmp.StockDividend{}
+	exDate := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	for range quarters {
+		result = append(result, fmp.StockDividend{
+			Symbol:          symbol,
+			Date:            exDate.Format(time.DateOnly),
+			RecordDate:      exDate.AddDate(0, 0, 1).Format(time.DateOnly),
+			PaymentDate:     exDate.AddDate(0, 0, 14).Format(time.DateOnly),
+			DeclarationDate: exDate.AddDate(0, 0, -14).Format(time.DateOnly),
+			AdjDividend:     amount,
+			Dividend:        amount,
+			Frequency:       "Quarterly",
+		})
+		exDate = exDate.AddDate(0, -3, 0)
+	}
+
+	return result
+}
+
+func symbolSeed(symbol string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(symbol))
+	return hash.Sum32()
+}
+
+func isWeekday(date time.Time) bool {
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+func round(value float64) float64 {
+	const (
+		cents = 100
+	)
+
+	return math.Round(value*cents) / cents
+}
@@ -0,0 +1,117 @@
+package fmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+const (
+	// newsPageSize is the number of articles requested per page, FMP's
+	// maximum.
+	newsPageSize = 250
+	// maxNewsPages bounds the pages requested for a query, 25,000 articles,
+	// in case FMP ignores the window and keeps returning older articles.
+	maxNewsPages = 100
+)
+
+// NewsQuery selects stock news. Zero times leave the window open.
+type NewsQuery struct {
+	Symbols []string
+	From    time.Time
+	To      time.Time
+}
+
+// NewsArticle is a stock news article as returned by FMP.
+type NewsArticle struct {
+	Symbol        string `json:"symbol"`
+	PublishedDate string `json:"publishedDate"`
+	Publisher     string `json:"publisher"`
+	Title         string `json:"title"`
+	Image         string `json:"image"`
+	Site          string `json:"site"`
+	Text          string `json:"text"`
+	URL           string `json:"url"`
+}
+
+// News returns the news articles for the query's symbols, newest first. It
+// requests every page of the query, see AllNews to consume them a page at a
+// time.
+func (h *HTTPClient) News(ctx context.Context, query NewsQuery) (*Result[NewsArticle], error) {
+	result := &Result[NewsArticle]{}
+
+	for article, err := range h.AllNews(ctx, query, &result.BytesFetched) {
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, article)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, fmt.Errorf("%w from FMP %s", ErrNoData, catalog[EndpointNews].Path)
+	}
+
+	return result, nil
+}
+
+// AllNews returns the news articles for the query's symbols, newest first,
+// requesting the next page only once the articles of the last were consumed.
+// Paging stops at the first article published before query.From. The bytes
+// of each page are added to bytesFetched, if it is not nil.
+func (h *HTTPClient) AllNews(ctx context.Context, query NewsQuery, bytesFetched *int64) iter.Seq2[NewsArticle, error] {
+	pagination := httpUtil.PageNumberPagination{FirstPage: 0, PageSize: newsPageSize}
+
+	fetch := func(ctx context.Context, position httpUtil.PagePosition) (httpUtil.Page[NewsArticle], error) {
+		result, err := h.NewsPage(ctx, query, position.Number)
+		if err != nil {
+			return httpUtil.Page[NewsArticle]{}, err
+		}
+
+		if bytesFetched != nil {
+			*bytesFetched += result.BytesFetched
+		}
+
+		return httpUtil.Page[NewsArticle]{Items: result.Items}, nil
+	}
+
+	return httpUtil.Paginate(ctx, pagination, fetch, httpUtil.PageOptions[NewsArticle]{
+		MaxPages: maxNewsPages,
+		Stop: func(article NewsArticle) bool {
+			publishedAt, err := article.GetPublishedAt()
+			return err == nil && !query.From.IsZero() && publishedAt.Before(query.From)
+		},
+	})
+}
+
+// NewsPage returns a page of the news articles for the query's symbols,
+// numbered from 0. Pages past the last are empty.
+func (h *HTTPClient) NewsPage(ctx context.Context, query NewsQuery, page int) (*Result[NewsArticle], error) {
+	entry, err := LookupEndpoint(EndpointNews)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := entry.parameters(query.From, query.To)
+	parameters["symbols"] = strings.Join(query.Symbols, ",")
+	parameters["page"] = strconv.Itoa(page)
+
+	result, err := getList[NewsArticle](ctx, h, entry.Path, parameters)
+	if errors.Is(err, ErrNoData) {
+		return &Result[NewsArticle]{Items: []NewsArticle{}}, nil
+	}
+
+	return result, err
+}
+
+// GetPublishedAt parses the article's publish time, which FMP reports in
+// exchange time.
+func (a NewsArticle) GetPublishedAt() (time.Time, error) {
+	return time.ParseInLocation(dateTimeLayout, a.PublishedDate, exchangeLocation)
+}
@@ -0,0 +1,72 @@
+package fmp
+
+import (
+	"context"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+const (
+	// ParameterResolution is the spec.source.parameters key selecting the bar
+	// resolution of the PRICES endpoint.
+	ParameterResolution = "resolution"
+
+	intradayChartPath = "historical-chart/"
+)
+
+// PriceQuery selects the prices of a symbol. A zero resolution is daily, and
+// zero times leave the window open.
+type PriceQuery struct {
+	Symbol     string
+	Resolution records.Resolution
+	From       time.Time
+	To         time.Time
+}
+
+// HistoricalPrice is a price bar as returned by FMP. Date is in exchange time,
+// as a date for daily bars and as a date and time for intraday bars.
+type HistoricalPrice struct {
+	Symbol        string  `json:"symbol"`
+	Date          string  `json:"date"`
+	Open          float64 `json:"open"`
+	High          float64 `json:"high"`
+	Low           float64 `json:"low"`
+	Close         float64 `json:"close"`
+	Volume        float64 `json:"volume"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"changePercent"`
+	VWAP          float64 `json:"vwap"`
+}
+
+// Prices returns the price bars of the query's symbol, newest first. Daily bars
+// come from the end-of-day endpoint and intraday bars from the chart endpoint
+// for the resolution.
+func (h *HTTPClient) Prices(ctx context.Context, query PriceQuery) (*Result[HistoricalPrice], error) {
+	entry, err := LookupEndpoint(EndpointPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	path := entry.Path
+	if query.Resolution != "" && query.Resolution.IsIntraday() {
+		path = intradayChartPath + string(query.Resolution)
+	}
+
+	parameters := entry.parameters(query.From, query.To)
+	parameters["symbol"] = query.Symbol
+
+	return getList[HistoricalPrice](ctx, h, path, parameters)
+}
+
+// GetTime parses the start of the bar. Intraday bars are converted from
+// exchange time to UTC, and daily bars are stamped at midnight UTC of their
+// trading date.
+func (p HistoricalPrice) GetTime(resolution records.Resolution) (time.Time, error) {
+	if resolution.IsIntraday() {
+		start, err := time.ParseInLocation(dateTimeLayout, p.Date, exchangeLocation)
+		return start.UTC(), err
+	}
+
+	return time.ParseInLocation(time.DateOnly, p.Date, time.UTC)
+}
@@ -0,0 +1,527 @@
+package fmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // FMP timestamps are in exchange time, which must load without system tzdata.
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Streamer interface
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Subscriber interface
+
+const (
+	SourceType = "FMP"
+)
+
+const (
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var exchangeLocation = mustLoadLocation("America/New_York")
+
+type Provider struct {
+	client *HTTPClient
+	// stream is nil when streaming collections are not supported.
+	stream *StreamClient
+}
+
+func NewProvider(client *HTTPClient, stream *StreamClient) *Provider {
+	return &Provider{
+		client: client,
+		stream: stream,
+	}
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityHistorical, provider.CapabilityLatest}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{
+		EndpointNews,
+		EndpointPrices,
+		EndpointIncomeStatement,
+		EndpointBalanceSheet,
+		EndpointCashFlow,
+		EndpointEarnings,
+		EndpointSplits,
+		EndpointDividends,
+	}
+}
+
+func (p *Provider) Limits() provider.RequestLimits {
+	const (
+		requestsPerMinute = 300
+		burst             = 10
+	)
+
+	return provider.RequestLimits{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             burst,
+	}
+}
+
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	switch request.Endpoint {
+	case EndpointNews:
+		return p.fetchNews(ctx, request)
+	case EndpointPrices:
+		return p.fetchPrices(ctx, request)
+	case EndpointIncomeStatement, EndpointBalanceSheet, EndpointCashFlow:
+		return p.fetchStatements(ctx, request)
+	case EndpointEarnings:
+		return p.fetchEarnings(ctx, request)
+	case EndpointSplits:
+		return p.fetchSplits(ctx, request)
+	case EndpointDividends:
+		return p.fetchDividends(ctx, request)
+	default:
+		return nil, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+}
+
+// Stream writes news a page at a time, so a backfill of years of news holds a
+// single page in memory. Other endpoints are written as a single payload.
+func (p *Provider) Stream(ctx context.Context, request provider.Request, write func(*provider.Payload) error) error {
+	if request.Endpoint != EndpointNews {
+		payload, err := p.Fetch(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		return write(payload)
+	}
+
+	query := NewsQuery{
+		Symbols: []string{request.Symbol},
+		From:    request.From,
+		To:      request.To,
+	}
+
+	var bytesFetched, bytesWritten int64
+
+	batch := make([]NewsArticle, 0, newsPageSize)
+	flush := func() error {
+		payload := &provider.Payload{Request: request, Data: batch, BytesFetched: bytesFetched - bytesWritten}
+		bytesWritten = bytesFetched
+		batch = make([]NewsArticle, 0, newsPageSize)
+
+		return write(payload)
+	}
+
+	for article, err := range p.client.AllNews(ctx, query, &bytesFetched) {
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, article)
+		if len(batch) == newsPageSize {
+			if writeError := flush(); writeError != nil {
+				return writeError
+			}
+		}
+	}
+
+	// A window without news is not a failed collection, it is written as an
+	// empty payload.
+	if len(batch) > 0 || bytesWritten == 0 {
+		return flush()
+	}
+
+	return nil
+}
+
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	switch data := payload.Data.(type) {
+	case []NewsArticle:
+		return normalizeNews(data)
+	case []HistoricalPrice:
+		return normalizePrices(payload.Request, data)
+	case []FinancialStatement:
+		return normalizeStatements(payload.Request, data)
+	case []EarningsReport:
+		return normalizeEarnings(payload.Request, data)
+	case []StockSplit:
+		return normalizeSplits(payload.Request, data)
+	case []StockDividend:
+		return normalizeDividends(payload.Request, data)
+	case []StreamMessage:
+		return normalizeStream(data)
+	default:
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+}
+
+func (p *Provider) fetchNews(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	result, err := p.client.News(ctx, NewsQuery{
+		Symbols: []string{request.Symbol},
+		From:    request.From,
+		To:      request.To,
+	})
+
+	// A window without news is not a failed collection.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []NewsArticle{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) fetchPrices(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	resolution, err := records.ParseResolution(request.Parameters[ParameterResolution])
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.client.Prices(ctx, PriceQuery{
+		Symbol:     request.Symbol,
+		Resolution: resolution,
+		From:       request.From,
+		To:         request.To,
+	})
+
+	// A window without trading, such as a weekend, is not a failed collection.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []HistoricalPrice{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) fetchStatements(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	result, err := p.client.Statements(ctx, request.Endpoint, StatementQuery{
+		Symbol: request.Symbol,
+		Period: request.Parameters[ParameterPeriod],
+	})
+
+	// Securities without filings, such as ETFs, have no statements.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []FinancialStatement{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) fetchEarnings(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	result, err := p.client.Earnings(ctx, EarningsQuery{Symbol: request.Symbol})
+
+	// Securities without earnings, such as ETFs, have no reports.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []EarningsReport{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) fetchSplits(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	result, err := p.client.Splits(ctx, ActionQuery{Symbol: request.Symbol})
+
+	// Most securities have never split.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []StockSplit{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) fetchDividends(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	result, err := p.client.Dividends(ctx, ActionQuery{Symbol: request.Symbol})
+
+	// Many securities pay no dividends.
+	if errors.Is(err, ErrNoData) {
+		return &provider.Payload{Request: request, Data: []StockDividend{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         result.Items,
+		BytesFetched: result.BytesFetched,
+	}, nil
+}
+
+func normalizeNews(articles []NewsArticle) ([]records.Record, error) {
+	normalized := make([]records.Record, 0, 2*len(articles))
+
+	for _, article := range articles {
+		publishedAt, err := article.GetPublishedAt()
+		if err != nil {
+			return nil, fmt.Errorf("invalid publish date %q: %w", article.PublishedDate, err)
+		}
+
+		newsID := records.NewsID(article.URL)
+
+		normalized = append(normalized, records.NewsArticle{
+			ID:          newsID,
+			Source:      SourceType,
+			PublishedAt: publishedAt.UTC(),
+			Title:       article.Title,
+			Text:        article.Text,
+			URL:         article.URL,
+			Site:        article.Site,
+			Publisher:   article.Publisher,
+			ImageURL:    article.Image,
+		})
+
+		if article.Symbol != "" {
+			normalized = append(normalized, records.NewsSecurity{
+				NewsID:      newsID,
+				Symbol:      strings.ToUpper(article.Symbol),
+				PublishedAt: publishedAt.UTC(),
+			})
+		}
+	}
+
+	return normalized, nil
+}
+
+// normalizePrices converts FMP prices into bars, oldest first. A bar repeated
+// in the response is kept once.
+func normalizePrices(request provider.Request, prices []HistoricalPrice) ([]records.Record, error) {
+	resolution, err := records.ParseResolution(request.Parameters[ParameterResolution])
+	if err != nil {
+		return nil, err
+	}
+
+	bars := make([]records.Bar, 0, len(prices))
+	seen := make(map[time.Time]bool, len(prices))
+
+	for _, price := range prices {
+		start, parseError := price.GetTime(resolution)
+		if parseError != nil {
+			return nil, fmt.Errorf("invalid %s bar date %q: %w", resolution, price.Date, parseError)
+		}
+
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+
+		bars = append(bars, records.Bar{
+			Symbol:     symbolOrRequested(price.Symbol, request),
+			Resolution: resolution,
+			Time:       start,
+			Open:       price.Open,
+			High:       price.High,
+			Low:        price.Low,
+			Close:      price.Close,
+			Volume:     price.Volume,
+			Source:     SourceType,
+			// FMP adjusts both daily and intraday bars for splits.
+			SplitAdjusted: true,
+		})
+	}
+
+	slices.SortFunc(bars, func(a, b records.Bar) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	normalized := make([]records.Record, 0, len(bars))
+	for _, bar := range bars {
+		normalized = append(normalized, bar)
+	}
+
+	return normalized, nil
+}
+
+// normalizeStatements converts FMP statements into line items. Statements that
+// report on a period ending outside the request window are skipped.
+func normalizeStatements(request provider.Request, statements []FinancialStatement) ([]records.Record, error) {
+	statement, ok := statementEndpoints[request.Endpoint]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a financial statement endpoint", request.Endpoint)
+	}
+
+	normalized := []records.Record{}
+
+	for _, fmpStatement := range statements {
+		reportDate, err := fmpStatement.GetReportDate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement date %q: %w", fmpStatement.Date, err)
+		}
+
+		if !request.Contains(reportDate) {
+			continue
+		}
+
+		filingDate, err := fmpStatement.GetFilingDate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement filing date %q: %w", fmpStatement.FilingDate, err)
+		}
+
+		for _, item := range slices.Sorted(maps.Keys(fmpStatement.Items)) {
+			normalized = append(normalized, records.StatementItem{
+				Symbol:       symbolOrRequested(fmpStatement.Symbol, request),
+				Statement:    statement,
+				FiscalYear:   fmpStatement.FiscalYear,
+				FiscalPeriod: fmpStatement.Period,
+				ReportDate:   reportDate,
+				FilingDate:   filingDate,
+				Item:         item,
+				Value:        fmpStatement.Items[item],
+				Currency:     fmpStatement.ReportedCurrency,
+				Source:       SourceType,
+			})
+		}
+	}
+
+	return normalized, nil
+}
+
+// normalizeEarnings converts FMP earnings reports into earnings with their
+// surprises. Reports dated outside the request window are skipped.
+func normalizeEarnings(request provider.Request, reports []EarningsReport) ([]records.Record, error) {
+	normalized := make([]records.Record, 0, len(reports))
+
+	for _, report := range reports {
+		reportDate, err := report.GetReportDate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid earnings date %q: %w", report.Date, err)
+		}
+
+		if !request.Contains(reportDate) {
+			continue
+		}
+
+		normalized = append(normalized, records.Earnings{
+			Symbol:                 symbolOrRequested(report.Symbol, request),
+			ReportDate:             reportDate,
+			EPSActual:              report.EPSActual,
+			EPSEstimated:           report.EPSEstimated,
+			RevenueActual:          report.RevenueActual,
+			RevenueEstimated:       report.RevenueEstimated,
+			EPSSurprisePercent:     records.SurprisePercent(report.EPSActual, report.EPSEstimated),
+			RevenueSurprisePercent: records.SurprisePercent(report.RevenueActual, report.RevenueEstimated),
+			Source:                 SourceType,
+		})
+	}
+
+	return normalized, nil
+}
+
+// normalizeSplits converts FMP splits into splits. Splits with an ex-date
+// outside the request window are skipped.
+func normalizeSplits(request provider.Request, splits []StockSplit) ([]records.Record, error) {
+	normalized := make([]records.Record, 0, len(splits))
+
+	for _, split := range splits {
+		exDate, err := time.ParseInLocation(time.DateOnly, split.Date, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid split date %q: %w", split.Date, err)
+		}
+
+		if !request.Contains(exDate) {
+			continue
+		}
+
+		normalized = append(normalized, records.Split{
+			Symbol:      symbolOrRequested(split.Symbol, request),
+			ExDate:      exDate,
+			Numerator:   split.Numerator,
+			Denominator: split.Denominator,
+			Source:      SourceType,
+		})
+	}
+
+	return normalized, nil
+}
+
+// normalizeDividends converts FMP dividends into dividends. Dividends with an
+// ex-date outside the request window are skipped.
+func normalizeDividends(request provider.Request, dividends []StockDividend) ([]records.Record, error) {
+	normalized := make([]records.Record, 0, len(dividends))
+
+	for _, dividend := range dividends {
+		exDate, err := time.ParseInLocation(time.DateOnly, dividend.Date, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dividend date %q: %w", dividend.Date, err)
+		}
+
+		if !request.Contains(exDate) {
+			continue
+		}
+
+		recordDate, recordError := parseOptionalDate(dividend.RecordDate)
+		payDate, payError := parseOptionalDate(dividend.PaymentDate)
+		declarationDate, declarationError := parseOptionalDate(dividend.DeclarationDate)
+		if dateError := errors.Join(recordError, payError, declarationError); dateError != nil {
+			return nil, fmt.Errorf("invalid dividend dates for ex-date %s: %w", dividend.Date, dateError)
+		}
+
+		normalized = append(normalized, records.Dividend{
+			Symbol:          symbolOrRequested(dividend.Symbol, request),
+			ExDate:          exDate,
+			RecordDate:      recordDate,
+			PayDate:         payDate,
+			DeclarationDate: declarationDate,
+			Amount:          dividend.Dividend,
+			Source:          SourceType,
+		})
+	}
+
+	return normalized, nil
+}
+
+// symbolOrRequested returns the symbol FMP reported, falling back to the
+// requested symbol for responses that omit it.
+func symbolOrRequested(symbol string, request provider.Request) string {
+	if symbol == "" {
+		symbol = request.Symbol
+	}
+
+	return strings.ToUpper(symbol)
+}
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return location
+}
@@ -0,0 +1,172 @@
+package fmp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+const (
+	// ParameterPeriod is the spec.source.parameters key selecting annual or
+	// quarterly statements.
+	ParameterPeriod = "period"
+
+	PeriodAnnual  = "annual"
+	PeriodQuarter = "quarter"
+)
+
+// StatementQuery selects the most recent financial statements of a symbol.
+type StatementQuery struct {
+	Symbol string
+	// Period is PeriodAnnual or PeriodQuarter. Empty is annual.
+	Period string
+}
+
+// FinancialStatement is an income statement, balance sheet or cash flow
+// statement as returned by FMP. Every numeric field of the response that is
+// not part of the statement's metadata is a line item.
+type FinancialStatement struct {
+	Symbol           string
+	Date             string
+	ReportedCurrency string
+	CIK              string
+	FilingDate       string
+	AcceptedDate     string
+	FiscalYear       int
+	Period           string
+	Items            map[string]float64
+}
+
+//nolint:gochecknoglobals // gochecknoglobals
+var statementEndpoints = map[string]records.Statement{
+	EndpointIncomeStatement: records.StatementIncome,
+	EndpointBalanceSheet:    records.StatementBalance,
+	EndpointCashFlow:        records.StatementCashFlow,
+}
+
+// Statements returns the statements published for the query's symbol, newest
+// first. The endpoint is one of the statement endpoints of the catalog.
+func (h *HTTPClient) Statements(
+	ctx context.Context,
+	endpoint string,
+	query StatementQuery,
+) (*Result[FinancialStatement], error) {
+	if _, ok := statementEndpoints[endpoint]; !ok {
+		return nil, fmt.Errorf("%s is not a financial statement endpoint", endpoint)
+	}
+
+	entry, err := LookupEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(query.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	// Statements are selected by count rather than by date window.
+	parameters := entry.parameters(time.Time{}, time.Time{})
+	parameters["symbol"] = query.Symbol
+	parameters["period"] = period
+
+	return getList[FinancialStatement](ctx, h, entry.Path, parameters)
+}
+
+func (s *FinancialStatement) UnmarshalJSON(data []byte) error {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	metadata := map[string]*string{
+		"symbol":           &s.Symbol,
+		"date":             &s.Date,
+		"reportedCurrency": &s.ReportedCurrency,
+		"cik":              &s.CIK,
+		"filingDate":       &s.FilingDate,
+		"acceptedDate":     &s.AcceptedDate,
+		"period":           &s.Period,
+	}
+
+	s.Items = make(map[string]float64, len(fields))
+
+	for name, raw := range fields {
+		if target, ok := metadata[name]; ok {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return fmt.Errorf("invalid statement field %s: %w", name, err)
+			}
+			continue
+		}
+
+		if name == "fiscalYear" || name == "calendarYear" {
+			continue
+		}
+
+		value := 0.0
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Links and other text fields are not line items.
+			continue
+		}
+
+		s.Items[name] = value
+	}
+
+	// The fiscal year is preferred, the calendar year only stands in for it
+	// where it is missing.
+	for _, name := range []string{"fiscalYear", "calendarYear"} {
+		year, found, err := parseStatementYear(fields[name])
+		if err != nil {
+			return fmt.Errorf("invalid statement field %s: %w", name, err)
+		}
+
+		if found {
+			s.FiscalYear = year
+			break
+		}
+	}
+
+	return nil
+}
+
+// parseStatementYear parses a year, which FMP has reported both as a string
+// and as a number. A missing, null or empty year is not found.
+func parseStatementYear(raw json.RawMessage) (int, bool, error) {
+	value := strings.Trim(string(raw), `"`)
+	if value == "" || value == "null" {
+		return 0, false, nil
+	}
+
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return year, true, nil
+}
+
+// GetReportDate parses the last day of the period the statement reports on.
+func (s FinancialStatement) GetReportDate() (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, s.Date, time.UTC)
+}
+
+// GetFilingDate parses the filing date, which is zero if FMP has none.
+func (s FinancialStatement) GetFilingDate() (time.Time, error) {
+	return parseOptionalDate(s.FilingDate)
+}
+
+func parsePeriod(period string) (string, error) {
+	switch period {
+	case "", PeriodAnnual:
+		return PeriodAnnual, nil
+	case PeriodQuarter:
+		return PeriodQuarter, nil
+	default:
+		return "", fmt.Errorf("unsupported statement period %q, expected %s or %s", period, PeriodAnnual, PeriodQuarter)
+	}
+}
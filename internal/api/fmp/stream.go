@@ -0,0 +1,241 @@
+package fmp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/api/websocket"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+)
+
+const (
+	// StreamURL is FMP's WebSocket stream of US stock trades and quotes.
+	StreamURL = "wss://websockets.financialmodelingprep.com"
+
+	streamTypeTrade = "T"
+	streamTypeQuote = "Q"
+)
+
+// StreamClient subscribes to FMP's WebSocket stream.
+type StreamClient struct {
+	url    string
+	apiKey secrets.Value
+}
+
+// StreamMessage is a message of the FMP stream. Replies to the login and
+// subscribe events set Event, trades and quotes set Symbol and Type.
+type StreamMessage struct {
+	Event   string `json:"event,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	Symbol    string   `json:"s,omitempty"`
+	Timestamp int64    `json:"t,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	LastPrice *float64 `json:"lp,omitempty"`
+	LastSize  *float64 `json:"ls,omitempty"`
+	AskPrice  *float64 `json:"ap,omitempty"`
+	AskSize   *float64 `json:"as,omitempty"`
+	BidPrice  *float64 `json:"bp,omitempty"`
+	BidSize   *float64 `json:"bs,omitempty"`
+}
+
+type streamEvent struct {
+	Event string         `json:"event"`
+	Data  map[string]any `json:"data"`
+}
+
+func NewStreamClient(streamURL string, apiKey secrets.Value) *StreamClient {
+	return &StreamClient{
+		url:    streamURL,
+		apiKey: apiKey,
+	}
+}
+
+// IsTick returns whether the message is a trade or a quote.
+func (m StreamMessage) IsTick() bool {
+	return m.Symbol != "" && (m.Type == streamTypeTrade || m.Type == streamTypeQuote)
+}
+
+// GetTime returns the time of a tick. FMP has sent epoch times in seconds,
+// milliseconds and nanoseconds, so the unit is inferred from the magnitude.
+func (m StreamMessage) GetTime() time.Time {
+	const (
+		maxSeconds = 1e11
+		maxMillis  = 1e14
+		maxMicros  = 1e17
+	)
+
+	switch t := m.Timestamp; {
+	case t < maxSeconds:
+		return time.Unix(t, 0).UTC()
+	case t < maxMillis:
+		return time.UnixMilli(t).UTC()
+	case t < maxMicros:
+		return time.UnixMicro(t).UTC()
+	default:
+		return time.Unix(0, t).UTC()
+	}
+}
+
+// Stream logs in, subscribes to symbols and calls handle with every tick and
+// its size in bytes, until ctx is done, handle fails or the connection drops.
+func (s *StreamClient) Stream(ctx context.Context, symbols []string, handle func(StreamMessage, int) error) error {
+	const (
+		pingInterval = 30 * time.Second
+		readTimeout  = 3 * pingInterval
+	)
+
+	conn, err := websocket.Dial(ctx, s.url, http.Header{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to FMP stream: %w", err)
+	}
+
+	// Closing the connection is the only way to interrupt a blocked read.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer func() {
+		if stop() {
+			conn.Close()
+		}
+	}()
+
+	conn.SetReadTimeout(readTimeout)
+
+	tickers := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		tickers[i] = strings.ToLower(symbol)
+	}
+
+	events := []streamEvent{
+		{Event: "login", Data: map[string]any{"apiKey": s.apiKey.Reveal()}},
+		{Event: "subscribe", Data: map[string]any{"ticker": tickers}},
+	}
+
+	for _, event := range events {
+		encoded, _ := json.Marshal(event)
+		if writeError := conn.WriteMessage(websocket.OpText, encoded); writeError != nil {
+			return fmt.Errorf("failed to send %s to FMP stream: %w", event.Event, writeError)
+		}
+	}
+
+	pingContext, cancelPings := context.WithCancel(ctx)
+	defer cancelPings()
+
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-pingContext.Done():
+				return
+			case <-ticker.C:
+				if conn.WriteMessage(websocket.OpPing, nil) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, data, readError := conn.ReadMessage()
+		if readError != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("FMP stream disconnected: %w", readError)
+		}
+
+		// One malformed message is not worth reconnecting and missing the
+		// ticks sent meanwhile, so it is skipped.
+		message := StreamMessage{}
+		if unmarshalError := json.Unmarshal(data, &message); unmarshalError != nil {
+			logger.Warnf("Skipping undecodable FMP stream message %.200q: %v", data, unmarshalError)
+			continue
+		}
+
+		if message.Event != "" && message.Status >= http.StatusBadRequest {
+			return &APIError{Path: message.Event, StatusCode: message.Status, Message: message.Message}
+		}
+
+		if !message.IsTick() {
+			continue
+		}
+
+		if handleError := handle(message, len(data)); handleError != nil {
+			return handleError
+		}
+	}
+}
+
+// Subscribe streams the trades and quotes of the subscription's symbols. Only
+// PRICES can be streamed.
+func (p *Provider) Subscribe(
+	ctx context.Context,
+	subscription provider.Subscription,
+	handle func(*provider.Payload) error,
+) error {
+	if p.stream == nil {
+		return fmt.Errorf("source type %s is not configured for streaming", SourceType)
+	}
+
+	if subscription.Endpoint != EndpointPrices {
+		return fmt.Errorf("%w %q for streaming from source type %s",
+			provider.ErrUnsupportedEndpoint, subscription.Endpoint, SourceType)
+	}
+
+	return p.stream.Stream(ctx, subscription.Symbols, func(message StreamMessage, size int) error {
+		request := provider.Request{
+			Endpoint:   subscription.Endpoint,
+			Symbol:     strings.ToUpper(message.Symbol),
+			Parameters: subscription.Parameters,
+		}
+
+		return handle(&provider.Payload{Request: request, Data: []StreamMessage{message}, BytesFetched: int64(size)})
+	})
+}
+
+func normalizeStream(messages []StreamMessage) ([]records.Record, error) {
+	normalized := make([]records.Record, 0, len(messages))
+
+	value := func(field *float64) float64 {
+		if field == nil {
+			return 0
+		}
+		return *field
+	}
+
+	for _, message := range messages {
+		tick := records.Tick{
+			Symbol: strings.ToUpper(message.Symbol),
+			Time:   message.GetTime(),
+			Source: SourceType,
+		}
+
+		switch message.Type {
+		case streamTypeTrade:
+			tick.Kind = records.TickTrade
+			tick.Price = value(message.LastPrice)
+			tick.Size = value(message.LastSize)
+		case streamTypeQuote:
+			tick.Kind = records.TickQuote
+			tick.BidPrice = value(message.BidPrice)
+			tick.BidSize = value(message.BidSize)
+			tick.AskPrice = value(message.AskPrice)
+			tick.AskSize = value(message.AskSize)
+		default:
+			continue
+		}
+
+		normalized = append(normalized, tick)
+	}
+
+	return normalized, nil
+}
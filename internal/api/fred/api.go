@@ -0,0 +1,118 @@
+package fred
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.StatusError interface
+
+// DefaultBaseURL is the FRED API.
+const DefaultBaseURL = "https://api.stlouisfed.org/fred"
+
+type HTTPClient struct {
+	client  httpUtil.HTTPClient
+	apiKey  secrets.Value
+	baseURL string
+}
+
+// APIError is an error reported by FRED.
+type APIError struct {
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+type errorPayload struct {
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// NewHTTPClient returns a client for the FRED API at baseURL, which defaults
+// to DefaultBaseURL. Requests are made with apiKey unless their context
+// carries another key, see secrets.NewContext.
+func NewHTTPClient(client httpUtil.HTTPClient, apiKey secrets.Value, baseURL string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &HTTPClient{
+		client:  client,
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("FRED %s request failed (%d %s): %s",
+		e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+// CacheTTLs returns the HTTP cache TTLs of the FRED API. Series are revised
+// at most daily.
+func CacheTTLs() httpUtil.CacheTTLs {
+	const (
+		observationsCacheTTL = 6 * time.Hour
+	)
+
+	return httpUtil.CacheTTLs{"series/observations": observationsCacheTTL}
+}
+
+// Get requests the FRED path with the given query parameters and decodes the
+// JSON response into target. It returns the size of the response.
+func (h *HTTPClient) Get(ctx context.Context, path string, data map[string]string, target any) (int64, error) {
+	query := url.Values{}
+	for key, value := range data {
+		query.Set(key, value)
+	}
+
+	apiKey := h.apiKey
+	if override, ok := secrets.FromContext(ctx); ok {
+		apiKey = override
+	}
+
+	// FRED only takes the key in the query, which HTTPClient redacts from
+	// the errors it returns.
+	query.Set("api_key", apiKey.Reveal())
+	query.Set("file_type", "json")
+
+	response, err := h.client.Get(ctx, fmt.Sprintf("%s/%s?%s", h.baseURL, path, query.Encode()))
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		payload := errorPayload{}
+		if json.Unmarshal(body, &payload) != nil || payload.ErrorMessage == "" {
+			payload.ErrorMessage = http.StatusText(response.StatusCode)
+		}
+
+		return 0, &APIError{Path: path, StatusCode: response.StatusCode, Message: payload.ErrorMessage}
+	}
+
+	if unmarshalError := json.Unmarshal(body, target); unmarshalError != nil {
+		return 0, fmt.Errorf("failed to decode FRED %s response: %w", path, unmarshalError)
+	}
+
+	return int64(len(body)), nil
+}
@@ -0,0 +1,150 @@
+package fred
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+const (
+	// realtimeStartOfHistory and realtimeEndOfHistory are the bounds FRED
+	// uses for "every vintage".
+	realtimeStartOfHistory = "1776-07-04"
+	realtimeEndOfHistory   = "9999-12-31"
+
+	// maxObservationLimit is the most observations FRED returns per page, and
+	// its default limit.
+	maxObservationLimit = 100000
+)
+
+// ObservationQuery selects the observations of a series. Zero times leave the
+// window open.
+type ObservationQuery struct {
+	SeriesID string
+	From     time.Time
+	To       time.Time
+	// AllVintages requests every published value instead of only the current
+	// ones.
+	AllVintages bool
+	// Limit is the number of observations requested per page. Zero requests
+	// FRED's maximum.
+	Limit int
+}
+
+// Observations is a page of series observations as returned by FRED.
+type Observations struct {
+	Count        int           `json:"count"`
+	Offset       int           `json:"offset"`
+	Limit        int           `json:"limit"`
+	Observations []Observation `json:"observations"`
+	BytesFetched int64         `json:"-"`
+}
+
+// Observation is the value of a series for a date during a realtime period.
+// Value is "." when the observation is missing.
+type Observation struct {
+	RealtimeStart string `json:"realtime_start"`
+	RealtimeEnd   string `json:"realtime_end"`
+	Date          string `json:"date"`
+	Value         string `json:"value"`
+}
+
+// Observations returns the observations of the query's series, oldest first.
+// It requests every page of the query, see AllObservations to consume them a
+// page at a time.
+func (h *HTTPClient) Observations(ctx context.Context, query ObservationQuery) (*Observations, error) {
+	observations := &Observations{Observations: []Observation{}}
+
+	for observation, err := range h.AllObservations(ctx, query, &observations.BytesFetched) {
+		if err != nil {
+			return nil, err
+		}
+
+		observations.Observations = append(observations.Observations, observation)
+	}
+
+	observations.Count = len(observations.Observations)
+	observations.Limit = getObservationLimit(query)
+
+	return observations, nil
+}
+
+// AllObservations returns the observations of the query's series, oldest
+// first, requesting the next page only once the observations of the last were
+// consumed. The bytes of each page are added to bytesFetched, if it is not
+// nil.
+func (h *HTTPClient) AllObservations(
+	ctx context.Context,
+	query ObservationQuery,
+	bytesFetched *int64,
+) iter.Seq2[Observation, error] {
+	pagination := httpUtil.OffsetPagination{Limit: getObservationLimit(query)}
+
+	fetch := func(ctx context.Context, position httpUtil.PagePosition) (httpUtil.Page[Observation], error) {
+		page, err := h.ObservationsPage(ctx, query, position.Offset)
+		if err != nil {
+			return httpUtil.Page[Observation]{}, err
+		}
+
+		if bytesFetched != nil {
+			*bytesFetched += page.BytesFetched
+		}
+
+		return httpUtil.Page[Observation]{Items: page.Observations}, nil
+	}
+
+	return httpUtil.Paginate(ctx, pagination, fetch, httpUtil.PageOptions[Observation]{})
+}
+
+// ObservationsPage returns the page of the observations of the query's series
+// that starts at offset.
+func (h *HTTPClient) ObservationsPage(ctx context.Context, query ObservationQuery, offset int) (*Observations, error) {
+	parameters := map[string]string{
+		"series_id":  query.SeriesID,
+		"sort_order": "asc",
+	}
+
+	if !query.From.IsZero() {
+		parameters["observation_start"] = query.From.UTC().Format(time.DateOnly)
+	}
+
+	if !query.To.IsZero() {
+		parameters["observation_end"] = query.To.UTC().Format(time.DateOnly)
+	}
+
+	if query.AllVintages {
+		parameters["realtime_start"] = realtimeStartOfHistory
+		parameters["realtime_end"] = realtimeEndOfHistory
+	}
+
+	// FRED's defaults, the maximum limit and the first offset, are not sent.
+	if query.Limit > 0 {
+		parameters["limit"] = strconv.Itoa(getObservationLimit(query))
+	}
+
+	if offset > 0 {
+		parameters["offset"] = strconv.Itoa(offset)
+	}
+
+	observations := &Observations{}
+
+	bytesFetched, err := h.Get(ctx, "series/observations", parameters, observations)
+	if err != nil {
+		return nil, err
+	}
+
+	observations.BytesFetched = bytesFetched
+
+	return observations, nil
+}
+
+func getObservationLimit(query ObservationQuery) int {
+	if query.Limit > 0 {
+		return min(query.Limit, maxObservationLimit)
+	}
+
+	return maxObservationLimit
+}
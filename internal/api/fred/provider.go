@@ -0,0 +1,154 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+
+const (
+	SourceType = "FRED"
+
+	EndpointObservations = "OBSERVATIONS"
+
+	// ParameterVintages is the spec.source.parameters key selecting which
+	// published values are collected. Only "all", the default, is supported:
+	// FRED stamps current-only values with the day they were fetched, so
+	// collecting them daily would store a new current vintage every day.
+	ParameterVintages = "vintages"
+
+	VintagesAll = "all"
+)
+
+type Provider struct {
+	client *HTTPClient
+}
+
+func NewProvider(client *HTTPClient) *Provider {
+	return &Provider{
+		client: client,
+	}
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityHistorical, provider.CapabilityLatest, provider.CapabilitySeries}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{EndpointObservations}
+}
+
+func (p *Provider) Limits() provider.RequestLimits {
+	const (
+		requestsPerMinute = 120
+		burst             = 5
+	)
+
+	return provider.RequestLimits{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             burst,
+	}
+}
+
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	if request.Endpoint != EndpointObservations {
+		return nil, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+
+	if request.Series == "" {
+		return nil, fmt.Errorf("source type %s requires a series target", SourceType)
+	}
+
+	if vintages := request.Parameters[ParameterVintages]; vintages != "" && vintages != VintagesAll {
+		return nil, fmt.Errorf("unsupported vintages %q, expected %s", vintages, VintagesAll)
+	}
+
+	observations, err := p.client.Observations(ctx, ObservationQuery{
+		SeriesID:    request.Series,
+		From:        request.From,
+		To:          request.To,
+		AllVintages: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         observations,
+		BytesFetched: observations.BytesFetched,
+	}, nil
+}
+
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	observations, ok := payload.Data.(*Observations)
+	if !ok {
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+
+	normalized := make([]records.Record, 0, len(observations.Observations))
+
+	for _, observation := range observations.Observations {
+		record, err := normalizeObservation(payload.Request.Series, observation)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized = append(normalized, record)
+	}
+
+	return normalized, nil
+}
+
+func normalizeObservation(seriesID string, observation Observation) (records.SeriesObservation, error) {
+	date, err := time.ParseInLocation(time.DateOnly, observation.Date, time.UTC)
+	if err != nil {
+		return records.SeriesObservation{}, fmt.Errorf("invalid observation date %q: %w", observation.Date, err)
+	}
+
+	realtimeStart, err := time.ParseInLocation(time.DateOnly, observation.RealtimeStart, time.UTC)
+	if err != nil {
+		return records.SeriesObservation{}, fmt.Errorf("invalid realtime start %q: %w", observation.RealtimeStart, err)
+	}
+
+	// FRED ends the current vintage at the end of time. Other vintages end on
+	// the last day they were current.
+	realtimeEnd := time.Time{}
+	if observation.RealtimeEnd != realtimeEndOfHistory {
+		lastDay, parseError := time.ParseInLocation(time.DateOnly, observation.RealtimeEnd, time.UTC)
+		if parseError != nil {
+			return records.SeriesObservation{}, fmt.Errorf("invalid realtime end %q: %w", observation.RealtimeEnd, parseError)
+		}
+		realtimeEnd = lastDay.AddDate(0, 0, 1)
+	}
+
+	var value *float64
+	if observation.Value != "." {
+		parsed, parseError := strconv.ParseFloat(observation.Value, 64)
+		if parseError != nil {
+			return records.SeriesObservation{}, fmt.Errorf(
+				"invalid value %q on %s: %w", observation.Value, observation.Date, parseError)
+		}
+		value = &parsed
+	}
+
+	return records.SeriesObservation{
+		SeriesID:      strings.ToUpper(seriesID),
+		Date:          date,
+		RealtimeStart: realtimeStart,
+		RealtimeEnd:   realtimeEnd,
+		Value:         value,
+		Source:        SourceType,
+	}, nil
+}
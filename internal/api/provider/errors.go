@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// StatusError is an error of a request that the provider's server answered
+// with an HTTP status.
+type StatusError interface {
+	error
+	GetStatusCode() int
+}
+
+// IsUnavailable reports whether err is a failure of the provider rather than
+// of the request: its server could not be reached, timed out, limited the
+// rate, or failed with a server error.
+func IsUnavailable(err error) bool {
+	var statusError StatusError
+	if errors.As(err, &statusError) {
+		statusCode := statusError.GetStatusCode()
+		return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+	}
+
+	var urlError *url.Error
+	var netError net.Error
+
+	return errors.As(err, &urlError) || errors.As(err, &netError) || errors.Is(err, context.DeadlineExceeded)
+}
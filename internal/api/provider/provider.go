@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+type Capability string
+
+const (
+	// CapabilityHistorical providers can collect data for a past window.
+	CapabilityHistorical Capability = "historical"
+	// CapabilityLatest providers can collect the most recent data.
+	CapabilityLatest Capability = "latest"
+	// CapabilitySeries providers collect economic series rather than
+	// securities.
+	CapabilitySeries Capability = "series"
+)
+
+// RequestLimits describes the request budget of a provider.
+type RequestLimits struct {
+	RequestsPerMinute float64
+	Burst             int
+}
+
+// Request is a single collection request for one target of a DataCollection.
+type Request struct {
+	Endpoint string
+	// Symbol is set for securities and Series for economic series.
+	Symbol string
+	Series string
+	// SecurityName is the company name of Symbol, if known.
+	SecurityName string
+	Parameters   map[string]string
+
+	// From and To bound the data window. Zero values leave the window open,
+	// in which case providers return their most recent data.
+	From time.Time
+	To   time.Time
+}
+
+// Contains returns whether t is in the [From, To] window of the request, for
+// providers whose sources cannot filter by date.
+func (r Request) Contains(t time.Time) bool {
+	if !r.From.IsZero() && t.Before(r.From) {
+		return false
+	}
+
+	return r.To.IsZero() || !t.After(r.To)
+}
+
+// Payload is the decoded response to a Request, before normalization.
+type Payload struct {
+	Request      Request
+	Data         any
+	BytesFetched int64
+}
+
+// Provider is a source of market data, selected by the spec.source.type of a
+// DataCollection. Workers only use this interface, so adding a data vendor
+// means implementing it and registering the implementation.
+type Provider interface {
+	// Type is the spec.source.type handled by the provider, e.g. "FMP".
+	Type() string
+	Capabilities() []Capability
+	// Endpoints are the spec.source.endpoint values the provider supports.
+	Endpoints() []string
+	Limits() RequestLimits
+	Fetch(ctx context.Context, request Request) (*Payload, error)
+	Normalize(payload *Payload) ([]records.Record, error)
+}
+
+// Streamer is implemented by providers whose data is too large to fetch as a
+// single payload, such as file imports. Stream calls write with one payload
+// per batch, and the worker normalizes, validates and writes each batch before
+// write returns. A provider may treat a batch as committed once write returns
+// nil.
+type Streamer interface {
+	Stream(ctx context.Context, request Request, write func(payload *Payload) error) error
+}
+
+// Subscription requests a stream of the data of every target of a collection.
+type Subscription struct {
+	Endpoint   string
+	Symbols    []string
+	Parameters map[string]string
+}
+
+// Subscriber is implemented by providers that push data over a long-lived
+// connection, for collections with a STREAMING schedule. Subscribe streams
+// until ctx is done or the connection drops, calling handle with a payload per
+// message, and leaves reconnecting to the caller.
+type Subscriber interface {
+	Subscribe(ctx context.Context, subscription Subscription, handle func(payload *Payload) error) error
+}
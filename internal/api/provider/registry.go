@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/zydee3/stockdb/internal/common/crd"
+)
+
+var (
+	ErrUnknownSource       = errors.New("unknown source type")
+	ErrUnsupportedEndpoint = errors.New("unsupported endpoint")
+)
+
+// Registry maps source types to the providers that handle them.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+	}
+}
+
+func (r *Registry) Register(p Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[p.Type()]; exists {
+		return fmt.Errorf("provider for source type %s is already registered", p.Type())
+	}
+
+	r.providers[p.Type()] = p
+
+	return nil
+}
+
+func (r *Registry) Get(sourceType string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, exists := r.providers[sourceType]
+	if !exists {
+		return nil, fmt.Errorf("%w %q, expected one of %v", ErrUnknownSource, sourceType, r.typesLocked())
+	}
+
+	return p, nil
+}
+
+// Types returns the registered source types in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.typesLocked()
+}
+
+// Validate checks that source names a registered provider and an endpoint that
+// provider supports.
+func (r *Registry) Validate(source crd.DataCollectionSource) error {
+	p, err := r.Get(source.Type)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(p.Endpoints(), source.Endpoint) {
+		return fmt.Errorf(
+			"%w %q for source type %s, expected one of %v",
+			ErrUnsupportedEndpoint,
+			source.Endpoint,
+			source.Type,
+			p.Endpoints(),
+		)
+	}
+
+	return nil
+}
+
+func (r *Registry) typesLocked() []string {
+	types := make([]string, 0, len(r.providers))
+	for sourceType := range r.providers {
+		types = append(types, sourceType)
+	}
+	slices.Sort(types)
+
+	return types
+}
@@ -0,0 +1,272 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Item is an entry of an RSS 2.0 or Atom feed.
+type Item struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Categories  []string
+	PublishedAt time.Time
+}
+
+// Feed is a parsed RSS 2.0 or Atom feed.
+type Feed struct {
+	Title string
+	Link  string
+	Items []Item
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Link  string    `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	GUID        string   `xml:"guid"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+var (
+	ErrUnknownFormat = errors.New("not an RSS or Atom feed")
+)
+
+// feedTimeLayouts are the date formats seen in feeds. RSS specifies RFC 822
+// dates, but day names, single digit days and zone names vary in practice.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+}
+
+//nolint:gochecknoglobals // gochecknoglobals
+var markupPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ParseFeed parses an RSS 2.0 or Atom document.
+func ParseFeed(data []byte) (*Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	}
+
+	return nil, fmt.Errorf("%w: root element is <%s>", ErrUnknownFormat, root)
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := newDecoder(data)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrUnknownFormat, err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	// Feeds are often served with HTML entities and a non UTF-8 charset
+	// declaration, which a strict decoder rejects.
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+
+	return decoder
+}
+
+// charsetReader converts Latin-1 feeds to UTF-8 and reads any other charset as
+// UTF-8, which is right for the ASCII most feeds are written in.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+
+		return strings.NewReader(string(runes)), nil
+	}
+
+	return input, nil
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	document := rssDocument{}
+	if err := newDecoder(data).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+	}
+
+	feed := &Feed{
+		Title: strings.TrimSpace(document.Channel.Title),
+		Link:  strings.TrimSpace(document.Channel.Link),
+		Items: make([]Item, 0, len(document.Channel.Items)),
+	}
+
+	for _, item := range document.Channel.Items {
+		feed.Items = append(feed.Items, Item{
+			GUID:        strings.TrimSpace(item.GUID),
+			Title:       cleanText(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Description: cleanText(item.Description),
+			Author:      strings.TrimSpace(firstNonBlank(item.Creator, item.Author)),
+			Categories:  item.Categories,
+			PublishedAt: parseFeedTime(firstNonBlank(item.PubDate, item.Date)),
+		})
+	}
+
+	return feed, nil
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	document := atomDocument{}
+	if err := newDecoder(data).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+	}
+
+	feed := &Feed{
+		Title: strings.TrimSpace(document.Title),
+		Link:  alternateLink(document.Links),
+		Items: make([]Item, 0, len(document.Entries)),
+	}
+
+	for _, entry := range document.Entries {
+		item := Item{
+			GUID:        strings.TrimSpace(entry.ID),
+			Title:       cleanText(entry.Title),
+			Link:        alternateLink(entry.Links),
+			Description: cleanText(firstNonBlank(entry.Summary, entry.Content)),
+			PublishedAt: parseFeedTime(firstNonBlank(entry.Published, entry.Updated)),
+		}
+
+		if len(entry.Authors) > 0 {
+			item.Author = strings.TrimSpace(entry.Authors[0].Name)
+		}
+
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, category.Term)
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// alternateLink returns the link to the page of an Atom feed or entry.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+
+	return ""
+}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+// cleanText strips markup and entities from feed text, which is often HTML.
+func cleanText(value string) string {
+	text := html.UnescapeString(markupPattern.ReplaceAllString(value, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// firstNonBlank returns the first value that is not blank.
+func firstNonBlank(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,103 @@
+package rss
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Matcher decides whether a feed item is about a security, by its symbol or
+// its company name.
+type Matcher struct {
+	symbol *regexp.Regexp
+	name   *regexp.Regexp
+}
+
+// companySuffixes are dropped from company names before matching, since
+// articles rarely spell out "Apple Inc.".
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var companySuffixes = []string{
+	"incorporated", "inc", "corporation", "corp", "company", "co", "limited", "ltd",
+	"plc", "holdings", "group", "sa", "ag", "nv", "llc", "lp",
+}
+
+// NewMatcher returns a Matcher for symbol and, if given, the company name.
+//
+// Symbols are matched as cashtags ($AAPL), exchange prefixed (NASDAQ:AAPL) or
+// in parentheses ((AAPL)). Symbols of three or more letters also match as a
+// standalone uppercase word, which is too noisy for shorter symbols like "A"
+// or "IT". Names match as whole words, case-insensitively if they have several
+// words. A one word name like "Target" is often an ordinary word too, so it
+// only matches capitalized as in name or in uppercase.
+func NewMatcher(symbol string, name string) *Matcher {
+	const (
+		minBareSymbolLength = 3
+	)
+
+	symbol = regexp.QuoteMeta(strings.ToUpper(strings.TrimSpace(symbol)))
+
+	patterns := []string{
+		`\$` + symbol + `\b`,
+		`\b[A-Z]+:\s?` + symbol + `\b`,
+		`\(` + symbol + `\)`,
+	}
+
+	if len(symbol) >= minBareSymbolLength {
+		patterns = append(patterns, `(^|[^\w$:.])`+symbol+`($|[^\w.]|\.(\s|$))`)
+	}
+
+	matcher := &Matcher{
+		symbol: regexp.MustCompile(strings.Join(patterns, "|")),
+	}
+
+	if shortName := shortCompanyName(name); shortName != "" {
+		words := strings.Fields(shortName)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+
+		pattern := `(?i)\b` + strings.Join(words, `\s+`) + `\b`
+		if len(words) == 1 {
+			pattern = `\b(` + words[0] + `|` + strings.ToUpper(words[0]) + `)\b`
+		}
+
+		matcher.name = regexp.MustCompile(pattern)
+	}
+
+	return matcher
+}
+
+// Matches returns whether any of texts mentions the security.
+func (m *Matcher) Matches(texts ...string) bool {
+	for _, text := range texts {
+		if m.symbol.MatchString(text) {
+			return true
+		}
+
+		if m.name != nil && m.name.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shortCompanyName drops punctuation and corporate suffixes from name, e.g.
+// "Apple Inc." becomes "Apple".
+func shortCompanyName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.'
+	})
+
+	for len(words) > 1 {
+		last := strings.ToLower(words[len(words)-1])
+		if !slices.Contains(companySuffixes, last) {
+			break
+		}
+
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,264 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/api/provider.Provider interface
+// + Implements github.com/zydee3/stockdb/internal/api/provider.StatusError interface
+
+const (
+	SourceType = "RSS"
+
+	EndpointNews = "NEWS"
+
+	// ParameterFeeds is the spec.source.parameters key listing the feed URLs
+	// to poll, separated by commas or whitespace.
+	ParameterFeeds = "feeds"
+
+	// feedCacheTTL is how long a fetched feed is reused. A collection makes a
+	// job per security, and every job reads the same feeds.
+	feedCacheTTL = time.Minute
+
+	maxFeedSize = 16 << 20
+)
+
+// Provider polls RSS and Atom feeds and links their articles to the
+// securities they mention.
+type Provider struct {
+	client httpUtil.HTTPClient
+	clock  clock.Clock
+
+	mu    sync.Mutex
+	feeds map[string]cachedFeed
+}
+
+type cachedFeed struct {
+	feed      *Feed
+	fetchedAt time.Time
+}
+
+// FeedItem is an item with the feed it was read from.
+type FeedItem struct {
+	Item
+	FeedURL   string
+	FeedTitle string
+}
+
+// FeedItems are the items of every feed of a request, without duplicates.
+type FeedItems struct {
+	Items []FeedItem
+}
+
+// FeedError is a feed that could not be fetched.
+type FeedError struct {
+	URL        string
+	StatusCode int
+}
+
+func NewProvider(client httpUtil.HTTPClient, clk clock.Clock) *Provider {
+	return &Provider{
+		client: client,
+		clock:  clk,
+		feeds:  map[string]cachedFeed{},
+	}
+}
+
+func (e *FeedError) Error() string {
+	return fmt.Sprintf("feed %s returned %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *FeedError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+func (p *Provider) Type() string {
+	return SourceType
+}
+
+func (p *Provider) Capabilities() []provider.Capability {
+	return []provider.Capability{provider.CapabilityLatest}
+}
+
+func (p *Provider) Endpoints() []string {
+	return []string{EndpointNews}
+}
+
+func (p *Provider) Limits() provider.RequestLimits {
+	const (
+		requestsPerMinute = 60
+		burst             = 5
+	)
+
+	return provider.RequestLimits{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             burst,
+	}
+}
+
+// Fetch reads every configured feed. A feed that fails is skipped as long as
+// another feed could be read.
+func (p *Provider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	if request.Endpoint != EndpointNews {
+		return nil, fmt.Errorf("%w %q for source type %s", provider.ErrUnsupportedEndpoint, request.Endpoint, SourceType)
+	}
+
+	feedURLs := strings.FieldsFunc(request.Parameters[ParameterFeeds], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+
+	if len(feedURLs) == 0 {
+		return nil, fmt.Errorf("source type %s requires the %s parameter", SourceType, ParameterFeeds)
+	}
+
+	items := &FeedItems{}
+	seen := map[string]bool{}
+	bytesFetched := int64(0)
+	failures := []error{}
+
+	for _, feedURL := range feedURLs {
+		feed, size, err := p.getFeed(ctx, feedURL)
+		if err != nil {
+			logger.Warnf("Failed to read feed %s: %v", feedURL, err)
+			failures = append(failures, err)
+			continue
+		}
+
+		bytesFetched += size
+
+		for _, item := range feed.Items {
+			// The same story is often syndicated to several feeds.
+			key := firstNonBlank(item.GUID, item.Link)
+			if key == "" || seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			items.Items = append(items.Items, FeedItem{Item: item, FeedURL: feedURL, FeedTitle: feed.Title})
+		}
+	}
+
+	if len(failures) == len(feedURLs) {
+		return nil, errors.Join(failures...)
+	}
+
+	return &provider.Payload{
+		Request:      request,
+		Data:         items,
+		BytesFetched: bytesFetched,
+	}, nil
+}
+
+// Normalize keeps the items that mention the requested security. Articles are
+// identified by their link, like news from other providers, so an article in a
+// feed and from an API is stored once.
+func (p *Provider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	items, ok := payload.Data.(*FeedItems)
+	if !ok {
+		return nil, fmt.Errorf("cannot normalize %T from source type %s", payload.Data, SourceType)
+	}
+
+	request := payload.Request
+	symbol := strings.ToUpper(request.Symbol)
+	matcher := NewMatcher(symbol, request.SecurityName)
+
+	normalized := []records.Record{}
+
+	for _, item := range items.Items {
+		// Items without a publish time cannot be placed in the window.
+		if item.PublishedAt.IsZero() || !request.Contains(item.PublishedAt) {
+			continue
+		}
+
+		if !matcher.Matches(append([]string{item.Title, item.Description}, item.Categories...)...) {
+			continue
+		}
+
+		articleURL := firstNonBlank(item.Link, item.GUID)
+		newsID := records.NewsID(articleURL)
+
+		normalized = append(normalized,
+			records.NewsArticle{
+				ID:          newsID,
+				Source:      SourceType,
+				PublishedAt: item.PublishedAt,
+				Title:       item.Title,
+				Text:        item.Description,
+				URL:         articleURL,
+				Site:        siteOf(articleURL),
+				Publisher:   firstNonBlank(item.FeedTitle, item.Author),
+			},
+			records.NewsSecurity{
+				NewsID:      newsID,
+				Symbol:      symbol,
+				PublishedAt: item.PublishedAt,
+			},
+		)
+	}
+
+	return normalized, nil
+}
+
+// getFeed returns the feed at feedURL and the bytes fetched to read it, which
+// are zero when the feed was cached.
+func (p *Provider) getFeed(ctx context.Context, feedURL string) (*Feed, int64, error) {
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	cached, exists := p.feeds[feedURL]
+	p.mu.Unlock()
+
+	if exists && now.Sub(cached.fetchedAt) < feedCacheTTL {
+		return cached.feed, 0, nil
+	}
+
+	response, err := p.client.Get(ctx, feedURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, &FeedError{URL: feedURL, StatusCode: response.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxFeedSize))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	feed, err := ParseFeed(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p.mu.Lock()
+	p.feeds[feedURL] = cachedFeed{feed: feed, fetchedAt: now}
+	p.mu.Unlock()
+
+	return feed, int64(len(body)), nil
+}
+
+func siteOf(articleURL string) string {
+	parsed, err := url.Parse(articleURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
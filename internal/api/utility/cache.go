@@ -0,0 +1,435 @@
+package utility
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/logger"
+)
+
+const (
+	// CacheStatusHeader is set on responses served by a Cache, to
+	// CacheStatusHit or CacheStatusRevalidated.
+	CacheStatusHeader = "X-Stockdb-Cache"
+	// CacheStatusHit responses were fresh and did not reach the server.
+	CacheStatusHit = "HIT"
+	// CacheStatusRevalidated responses were confirmed unchanged by a
+	// conditional request.
+	CacheStatusRevalidated = "REVALIDATED"
+
+	cacheEntryExtension = ".entry"
+)
+
+// CacheTTLs are how long responses stay fresh, by endpoint. Keys are
+// path.Match patterns matched against as many trailing segments of the
+// request path as they have, e.g. "income-statement" or
+// "submissions/CIK*.json". The pattern with the most segments wins.
+type CacheTTLs map[string]time.Duration
+
+// Get returns the TTL of the request path urlPath, and false if no pattern
+// matches it.
+func (t CacheTTLs) Get(urlPath string) (time.Duration, bool) {
+	segments := strings.Split(strings.Trim(urlPath, "/"), "/")
+
+	ttl, matched, matchedSegments := time.Duration(0), false, 0
+	for pattern, patternTTL := range t {
+		count := strings.Count(pattern, "/") + 1
+		if count > len(segments) || (matched && count <= matchedSegments) {
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, strings.Join(segments[len(segments)-count:], "/")); ok {
+			ttl, matched, matchedSegments = patternTTL, true, count
+		}
+	}
+
+	return ttl, matched
+}
+
+// Cache keeps GET responses in a directory, a file per request. When the
+// files grow past the size bound, the least recently used are evicted.
+type Cache struct {
+	directory string
+	maxSize   int64
+
+	mu      sync.Mutex
+	entries map[string]*cacheFile
+	size    int64
+}
+
+type cacheFile struct {
+	size     int64
+	accessed time.Time
+}
+
+// cacheEntry is the header line of a cache file, which is followed by the
+// response body.
+type cacheEntry struct {
+	// URL is redacted, it only helps to inspect the cache.
+	URL        string      `json:"url"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	StoredAt   time.Time   `json:"storedAt"`
+
+	body []byte
+}
+
+// OpenCache opens the cache in directory, creating it if needed, and evicts
+// entries until it holds no more than maxSize bytes.
+func OpenCache(directory string, maxSize int64) (*Cache, error) {
+	const (
+		directoryPermissions = 0700
+	)
+
+	if err := os.MkdirAll(directory, directoryPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create HTTP cache: %w", err)
+	}
+
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP cache: %w", err)
+	}
+
+	c := &Cache{
+		directory: directory,
+		maxSize:   maxSize,
+		entries:   map[string]*cacheFile{},
+	}
+
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), cacheEntryExtension)
+		if !ok || file.IsDir() {
+			continue
+		}
+
+		info, infoError := file.Info()
+		if infoError != nil {
+			continue
+		}
+
+		c.entries[key] = &cacheFile{size: info.Size(), accessed: info.ModTime()}
+		c.size += info.Size()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evict()
+
+	return c, nil
+}
+
+// GetSize returns the bytes the cache holds.
+func (c *Cache) GetSize() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// get returns the entry stored under key, or nil if there is none. The entry
+// is looked up and touched under c.mu, but read from disk outside it, so a
+// slow read does not hold up the lookups of other requests.
+func (c *Cache) get(key string, now time.Time) *cacheEntry {
+	c.mu.Lock()
+	file, ok := c.entries[key]
+	if ok {
+		file.accessed = now
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	entry, err := readCacheEntry(c.getPath(key))
+	if err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// The entry may have been evicted or replaced while it was read.
+		if c.entries[key] == file {
+			logger.Warnf("Dropping unreadable HTTP cache entry %s: %v", key, err)
+			c.remove(key)
+		}
+
+		return nil
+	}
+
+	// The modification time orders entries by use when the cache is opened
+	// again.
+	os.Chtimes(c.getPath(key), now, now)
+
+	return entry
+}
+
+// put stores entry under key, evicting older entries to make room. Entries
+// larger than the whole cache are not stored.
+func (c *Cache) put(key string, entry *cacheEntry, now time.Time) error {
+	header, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(header) + 1 + len(entry.body))
+	if size > c.maxSize {
+		return nil
+	}
+
+	temporary, err := os.CreateTemp(c.directory, ".entry-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temporary.Name())
+
+	writer := bufio.NewWriter(temporary)
+	writer.Write(header)
+	writer.WriteByte('\n')
+	writer.Write(entry.body)
+
+	if flushError := writer.Flush(); flushError != nil {
+		temporary.Close()
+		return flushError
+	}
+
+	if closeError := temporary.Close(); closeError != nil {
+		return closeError
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if renameError := os.Rename(temporary.Name(), c.getPath(key)); renameError != nil {
+		return renameError
+	}
+
+	if file, ok := c.entries[key]; ok {
+		c.size -= file.size
+	}
+
+	c.entries[key] = &cacheFile{size: size, accessed: now}
+	c.size += size
+	c.evict()
+
+	return nil
+}
+
+// evict removes the least recently used entries until the cache fits its
+// size bound. The caller holds c.mu.
+func (c *Cache) evict() {
+	for c.size > c.maxSize {
+		// An empty cache holds no bytes, whatever its size drifted to.
+		if len(c.entries) == 0 {
+			c.size = 0
+			return
+		}
+
+		oldest := ""
+		for key, file := range c.entries {
+			if oldest == "" || file.accessed.Before(c.entries[oldest].accessed) {
+				oldest = key
+			}
+		}
+
+		c.remove(oldest)
+	}
+}
+
+// remove deletes the entry stored under key. The caller holds c.mu.
+func (c *Cache) remove(key string) {
+	if err := os.Remove(c.getPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Warnf("Failed to remove HTTP cache entry %s: %v", key, err)
+	}
+
+	c.size -= c.entries[key].size
+	delete(c.entries, key)
+}
+
+func (c *Cache) getPath(key string) string {
+	return filepath.Join(c.directory, key+cacheEntryExtension)
+}
+
+func readCacheEntry(filePath string) (*cacheEntry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	header, body, ok := bytes.Cut(data, []byte{'\n'})
+	if !ok {
+		return nil, errors.New("missing entry header")
+	}
+
+	entry := &cacheEntry{}
+	if unmarshalError := json.Unmarshal(header, entry); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+
+	entry.body = body
+
+	return entry, nil
+}
+
+// getCacheKey identifies the response to request. API keys sent in the URL or
+// in the DefaultSensitiveParameters headers are part of it, so responses are
+// not shared between accounts that may see different data.
+func getCacheKey(request *http.Request) string {
+	hash := sha256.New()
+	io.WriteString(hash, request.Method+" "+request.URL.String())
+
+	for _, name := range DefaultSensitiveParameters {
+		for _, value := range request.Header.Values(name) {
+			fmt.Fprintf(hash, "\n%s: %s", strings.ToLower(name), value)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// doCached serves a GET request from h.Cache while it is fresh, revalidates
+// it with the server once it is stale, and stores new responses.
+func (h *HTTPClient) doCached(request *http.Request) (*http.Response, error) {
+	key := getCacheKey(request)
+	now := h.getClock().Now()
+	ttl, _ := h.CacheTTLs.Get(request.URL.Path)
+
+	entry := h.Cache.get(key, now)
+	if entry != nil && now.Sub(entry.StoredAt) < ttl {
+		return entry.getResponse(request, CacheStatusHit), nil
+	}
+
+	if entry != nil {
+		request = request.Clone(request.Context())
+		entry.setValidators(request.Header)
+	}
+
+	response, err := h.send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil && response.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBodySize))
+		response.Body.Close()
+
+		entry.revalidate(response.Header, now)
+		h.store(key, entry, now)
+
+		return entry.getResponse(request, CacheStatusRevalidated), nil
+	}
+
+	if !isStorable(response, ttl) {
+		return response, nil
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	if h.Cacheable != nil && !h.Cacheable(response, body) {
+		return response, nil
+	}
+
+	h.store(key, &cacheEntry{
+		URL:        RedactURL(request.URL.String()),
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		StoredAt:   now,
+		body:       body,
+	}, now)
+
+	return response, nil
+}
+
+// store writes entry to the cache. A cache that cannot be written only costs
+// requests, so the response is still returned.
+func (h *HTTPClient) store(key string, entry *cacheEntry, now time.Time) {
+	if err := h.Cache.put(key, entry, now); err != nil {
+		logger.Warnf("Failed to cache response of %s: %v", entry.URL, err)
+	}
+}
+
+// isCacheable reports whether the response to request may come from the
+// cache. Requests that carry their own validators or ranges bypass it.
+func isCacheable(request *http.Request) bool {
+	if request.Method != http.MethodGet {
+		return false
+	}
+
+	for _, header := range []string{"Range", "If-None-Match", "If-Modified-Since"} {
+		if request.Header.Get(header) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isStorable reports whether response is worth keeping: it stays fresh for a
+// while, or it can be revalidated.
+func isStorable(response *http.Response, ttl time.Duration) bool {
+	if response.StatusCode != http.StatusOK || strings.Contains(response.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+
+	return ttl > 0 || response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != ""
+}
+
+// setValidators makes a request conditional on the entry having changed.
+func (e *cacheEntry) setValidators(header http.Header) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// revalidate restarts the freshness of the entry and takes the validators a
+// 304 response sent.
+func (e *cacheEntry) revalidate(header http.Header, now time.Time) {
+	for _, name := range []string{"ETag", "Last-Modified", "Cache-Control", "Expires", "Date"} {
+		if value := header.Get(name); value != "" {
+			e.Header.Set(name, value)
+		}
+	}
+
+	e.StoredAt = now
+}
+
+func (e *cacheEntry) getResponse(request *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheStatusHeader, status)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       request,
+	}
+}
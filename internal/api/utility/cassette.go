@@ -0,0 +1,376 @@
+package utility
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	commonUtil "github.com/zydee3/stockdb/internal/common/utility"
+)
+
+type CassetteMode string
+
+const (
+	// CassetteRecord sends requests to the network and records them.
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay serves recorded responses and fails requests that were
+	// not recorded, without touching the network.
+	CassetteReplay CassetteMode = "replay"
+
+	// Redacted replaces secrets in recorded requests and responses.
+	Redacted = secrets.Redacted
+
+	bodyEncodingBase64 = "base64"
+)
+
+// ErrCassetteMiss is returned in replay mode for a request the cassette has
+// no unplayed recording of.
+var ErrCassetteMiss = errors.New("request not found in cassette")
+
+// DefaultSensitiveParameters are the query parameters and headers whose
+// values are redacted from every cassette.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var DefaultSensitiveParameters = []string{
+	"apikey",
+	"api_key",
+	"token",
+	"access_token",
+	"authorization",
+	"x-api-key",
+	"cookie",
+	"set-cookie",
+}
+
+// CassetteOptions configures a Cassette.
+type CassetteOptions struct {
+	Mode CassetteMode
+	// Transport sends requests in record mode. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+	// Secrets are redacted wherever they appear, including response bodies.
+	Secrets []string
+	// SensitiveParameters are query parameter and header names, matched
+	// case-insensitively, whose values are redacted in addition to
+	// DefaultSensitiveParameters.
+	SensitiveParameters []string
+}
+
+// Cassette is an http.RoundTripper that records request and response pairs
+// to a file and replays them offline. Secrets are redacted before anything
+// is recorded, so a request is matched on its redacted method, URL and body.
+// Each recording is replayed once, in the order it was recorded.
+type Cassette struct {
+	path      string
+	mode      CassetteMode
+	transport http.RoundTripper
+	secrets   []string
+	sensitive map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	played       []bool
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+	// BodyEncoding is "base64" for bodies that are not UTF-8 text.
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+}
+
+type cassetteFile struct {
+	// Note says how a cassette was made if it was not recorded from the API.
+	// Recording the cassette again drops it.
+	Note         string        `json:"note,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// OpenCassette opens the cassette at path. In replay mode the file must
+// exist, in record mode it is replaced when the cassette is saved.
+func OpenCassette(path string, options CassetteOptions) (*Cassette, error) {
+	cassette := &Cassette{
+		path:      path,
+		mode:      options.Mode,
+		transport: options.Transport,
+		sensitive: map[string]bool{},
+	}
+
+	if cassette.transport == nil {
+		cassette.transport = http.DefaultTransport
+	}
+
+	for _, secret := range options.Secrets {
+		if secret != "" {
+			cassette.secrets = append(cassette.secrets, secret)
+		}
+	}
+
+	for _, name := range slices.Concat(DefaultSensitiveParameters, options.SensitiveParameters) {
+		cassette.sensitive[strings.ToLower(name)] = true
+	}
+
+	switch options.Mode {
+	case CassetteRecord:
+	case CassetteReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+
+		file := cassetteFile{}
+		if unmarshalError := json.Unmarshal(data, &file); unmarshalError != nil {
+			return nil, fmt.Errorf("failed to decode cassette %s: %w", path, unmarshalError)
+		}
+
+		cassette.interactions = file.Interactions
+		cassette.played = make([]bool, len(file.Interactions))
+	default:
+		return nil, fmt.Errorf("unsupported cassette mode %q, expected %s or %s",
+			options.Mode, CassetteRecord, CassetteReplay)
+	}
+
+	return cassette, nil
+}
+
+// NewHTTPClient returns an HTTPClient that sends its requests through the
+// cassette. It does not retry, so each request is recorded once.
+func (c *Cassette) NewHTTPClient() HTTPClient {
+	return HTTPClient{
+		Client: &http.Client{Transport: c},
+	}
+}
+
+func (c *Cassette) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := RecordedRequest{
+		Method: request.Method,
+		URL:    c.redactURL(request.URL),
+		Header: c.redactHeader(request.Header),
+		Body:   c.redact(string(body)),
+	}
+
+	if c.mode == CassetteReplay {
+		return c.replay(request, recorded)
+	}
+
+	response, err := c.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     c.redactHeader(response.Header),
+		},
+	}
+
+	if utf8.Valid(responseBody) {
+		interaction.Response.Body = c.redact(string(responseBody))
+	} else {
+		interaction.Response.Body = base64.StdEncoding.EncodeToString(responseBody)
+		interaction.Response.BodyEncoding = bodyEncodingBase64
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.played = append(c.played, true)
+	c.mu.Unlock()
+
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	return response, nil
+}
+
+// Save writes what was recorded to the cassette file. It does nothing in
+// replay mode.
+func (c *Cassette) Save() error {
+	const (
+		cassetteDirPerm  = 0755
+		cassetteFilePerm = 0644
+	)
+
+	if c.mode != CassetteRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if dirError := os.MkdirAll(filepath.Dir(c.path), cassetteDirPerm); dirError != nil {
+		return dirError
+	}
+
+	return commonUtil.WriteFileAtomic(c.path, append(data, '\n'), cassetteFilePerm)
+}
+
+// Unplayed returns the recordings that have not been replayed, so a test can
+// check that every request it expected was made.
+func (c *Cassette) Unplayed() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	unplayed := []Interaction{}
+	for i, interaction := range c.interactions {
+		if !c.played[i] {
+			unplayed = append(unplayed, interaction)
+		}
+	}
+
+	return unplayed
+}
+
+func (c *Cassette) replay(request *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, interaction := range c.interactions {
+		if c.played[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+
+		body := []byte(interaction.Response.Body)
+		if interaction.Response.BodyEncoding == bodyEncodingBase64 {
+			decoded, err := base64.StdEncoding.DecodeString(interaction.Response.Body)
+			if err != nil {
+				return nil, fmt.Errorf("invalid body of recorded %s %s: %w", recorded.Method, recorded.URL, err)
+			}
+			body = decoded
+		}
+
+		c.played[i] = true
+
+		status := interaction.Response.StatusCode
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w %s: %s %s", ErrCassetteMiss, c.path, recorded.Method, recorded.URL)
+}
+
+// matches compares requests by method, URL and body. Query parameters may be
+// in any order.
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	if r.Method != other.Method || r.Body != other.Body {
+		return false
+	}
+
+	left, leftError := url.Parse(r.URL)
+	right, rightError := url.Parse(other.URL)
+	if leftError != nil || rightError != nil {
+		return r.URL == other.URL
+	}
+
+	return left.Scheme == right.Scheme &&
+		left.Host == right.Host &&
+		left.Path == right.Path &&
+		left.Query().Encode() == right.Query().Encode()
+}
+
+func (c *Cassette) redact(text string) string {
+	for _, secret := range c.secrets {
+		text = strings.ReplaceAll(text, secret, Redacted)
+	}
+
+	return text
+}
+
+func (c *Cassette) redactURL(requestURL *url.URL) string {
+	redacted := redactQuery(requestURL, func(name string) bool {
+		return c.sensitive[strings.ToLower(name)]
+	})
+
+	return c.redact(redacted.String())
+}
+
+func (c *Cassette) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redacted := http.Header{}
+	for name, values := range header {
+		if c.sensitive[strings.ToLower(name)] {
+			redacted[name] = []string{Redacted}
+			continue
+		}
+
+		for _, value := range values {
+			redacted.Add(name, c.redact(value))
+		}
+	}
+
+	return redacted
+}
+
+// readRequestBody reads the body of request and replaces it so the request
+// can still be sent.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
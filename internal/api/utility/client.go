@@ -2,32 +2,273 @@ package utility
 
 import (
 	"context"
+	"errors"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+)
+
+const (
+	// DefaultMaxRetryWaitTime caps the wait before a retry, see
+	// RetryPolicy.MaxWaitTime.
+	DefaultMaxRetryWaitTime = time.Minute
+
+	// IdempotencyKeyHeader marks a POST or PATCH request as safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// maxDrainedBodySize is how much of a response that is retried is read so
+	// its connection can be reused.
+	maxDrainedBodySize = 64 << 10
 )
 
+// DefaultRetryStatusCodes are the statuses of failures that a later attempt
+// may not have: timeouts, rate limits and server errors.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var DefaultRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// HTTPClient sends requests and retries the ones that fail with a transport
+// error or a retryable status. A request that is still failing after its
+// retries returns its last response, so callers handle the status as usual.
+// GET requests are answered from Cache when it is set.
 type HTTPClient struct {
-	Client        *http.Client
-	RetryCount    int
+	Client *http.Client
+	// RetryCount is how many times a failed request is retried. Zero sends
+	// each request once.
+	RetryCount int
+	// RetryWaitTime is the backoff before the first retry. It doubles with
+	// each further retry, up to RetryPolicy.MaxWaitTime, and is jittered so
+	// clients that failed together do not retry together.
 	RetryWaitTime time.Duration
+	RetryPolicy   RetryPolicy
+	// Clock times the waits between retries and the freshness of cached
+	// responses. Nil uses the real clock.
+	Clock clock.Clock
+	// Cache stores GET responses on disk. Nil disables caching.
+	Cache *Cache
+	// CacheTTLs are how long cached responses are served without asking the
+	// server. Responses of other endpoints are only cached when they can be
+	// revalidated, with ETag or Last-Modified.
+	CacheTTLs CacheTTLs
+	// Cacheable vetoes storing a response that Cache would keep, for APIs
+	// that report errors in the body of a 200 response. Nil stores them all.
+	Cacheable func(response *http.Response, body []byte) bool
+	// Budget is waited on before every request sent to the server, retries
+	// and revalidations included. Responses served from Cache do not spend
+	// it. Nil does not pace requests.
+	Budget *rate.Limiter
+}
+
+// RetryPolicy decides which failed requests are retried. The zero value
+// retries DefaultRetryStatusCodes for idempotent requests.
+type RetryPolicy struct {
+	// StatusCodes are the response statuses that are retried. Nil retries
+	// DefaultRetryStatusCodes.
+	StatusCodes []int
+	// MaxWaitTime caps the backoff. A Retry-After longer than it is not
+	// waited for, the response is returned instead. Zero is
+	// DefaultMaxRetryWaitTime.
+	MaxWaitTime time.Duration
+	// RetryUnsafeMethods retries POST and PATCH requests without an
+	// IdempotencyKeyHeader, which may then take effect more than once.
+	RetryUnsafeMethods bool
 }
 
 func (h *HTTPClient) Do(request *http.Request) (*http.Response, error) {
-	var response *http.Response
-	var err error
+	if h.Cache != nil && isCacheable(request) {
+		return h.doCached(request)
+	}
+
+	return h.send(request)
+}
+
+// send sends request to the server, retrying it as RetryPolicy allows.
+func (h *HTTPClient) send(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	retryable := h.RetryPolicy.allows(request)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+
+		if h.Budget != nil {
+			if err := h.Budget.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := h.Client.Do(request)
+		if !retryable || attempt >= h.RetryCount || ctx.Err() != nil {
+			return response, redactError(err)
+		}
 
-	retries := h.RetryCount
-	for retries > 0 {
-		response, err = h.Client.Do(request)
-		if err == nil {
+		wait, retry := h.retryWait(attempt, response, err)
+		if !retry {
+			return response, redactError(err)
+		}
+
+		if response != nil {
+			io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBodySize))
+			response.Body.Close()
+		}
+
+		if waitError := h.wait(ctx, wait); waitError != nil {
+			return nil, waitError
+		}
+	}
+}
+
+// GetBackoff returns the jittered wait before retry attempt, counted from 0:
+// a random duration between half and all of RetryWaitTime doubled attempt
+// times.
+func (h *HTTPClient) GetBackoff(attempt int) time.Duration {
+	maxWait := h.RetryPolicy.getMaxWaitTime()
+
+	backoff := h.RetryWaitTime
+	for range attempt {
+		if backoff >= maxWait/2 {
+			backoff = maxWait
 			break
 		}
-		time.Sleep(h.RetryWaitTime)
-		retries--
+		backoff *= 2
+	}
+
+	backoff = min(backoff, maxWait)
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+
+	return half + rand.N(backoff-half+1) //nolint:gosec // jitter does not need a secure source
+}
+
+// ParseRetryAfter returns the wait a Retry-After header asks for, given in
+// seconds or as an HTTP date. It returns false for a value that is neither.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// retryWait returns how long to wait before retrying a request that got
+// response or err, and false if it should not be retried.
+func (h *HTTPClient) retryWait(attempt int, response *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		return h.GetBackoff(attempt), true
+	}
+
+	if !slices.Contains(h.RetryPolicy.getStatusCodes(), response.StatusCode) {
+		return 0, false
+	}
+
+	if header := response.Header.Get("Retry-After"); header != "" {
+		if wait, ok := ParseRetryAfter(header, h.getClock().Now()); ok {
+			return wait, wait <= h.RetryPolicy.getMaxWaitTime()
+		}
+	}
+
+	return h.GetBackoff(attempt), true
+}
+
+// wait sleeps for d, or until ctx is done.
+func (h *HTTPClient) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := h.getClock().NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+func (h *HTTPClient) getClock() clock.Clock {
+	if h.Clock == nil {
+		return clock.NewRealClock()
+	}
+
+	return h.Clock
+}
+
+// allows reports whether request may be sent again: its method is
+// idempotent, or it carries an idempotency key, and its body can be read
+// again.
+func (p RetryPolicy) allows(request *http.Request) bool {
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return p.RetryUnsafeMethods || request.Header.Get(IdempotencyKeyHeader) != ""
+	}
+}
+
+func (p RetryPolicy) getStatusCodes() []int {
+	if p.StatusCodes == nil {
+		return DefaultRetryStatusCodes
+	}
+
+	return p.StatusCodes
+}
+
+func (p RetryPolicy) getMaxWaitTime() time.Duration {
+	if p.MaxWaitTime <= 0 {
+		return DefaultMaxRetryWaitTime
+	}
+
+	return p.MaxWaitTime
+}
+
+// redactError removes API keys from the URL transport errors quote.
+func redactError(err error) error {
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		urlError.URL = RedactURL(urlError.URL)
 	}
 
-	return response, err
+	return err
 }
 
 func (h *HTTPClient) Get(ctx context.Context, url string) (*http.Response, error) {
@@ -0,0 +1,146 @@
+package utility
+
+import (
+	"context"
+	"iter"
+)
+
+// PagePosition locates a page of a paged endpoint. Which fields are set
+// depends on the Pagination: the page number, the offset of its first item,
+// or the cursor the previous page returned.
+type PagePosition struct {
+	Number int
+	Offset int
+	Cursor string
+}
+
+// Page is a page of items. NextCursor is the cursor of the following page,
+// for endpoints that page by cursor.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// PageFetcher requests the page at position.
+type PageFetcher[T any] func(ctx context.Context, position PagePosition) (Page[T], error)
+
+// Pagination is a style of paging an endpoint: where the first page is, and
+// where the page after a page with a number of items is, if there is one.
+type Pagination interface {
+	GetFirst() PagePosition
+	GetNext(position PagePosition, items int, nextCursor string) (PagePosition, bool)
+}
+
+// PageNumberPagination pages by number from FirstPage, PageSize items per
+// page. A shorter page is the last.
+type PageNumberPagination struct {
+	FirstPage int
+	PageSize  int
+}
+
+// OffsetPagination pages by the offset of the first item, Limit items per
+// page. A shorter page is the last.
+type OffsetPagination struct {
+	Limit int
+}
+
+// CursorPagination pages by cursor. Each page returns the cursor of the next,
+// and the last returns none.
+type CursorPagination struct {
+	// FirstCursor is the cursor of the first page, often empty.
+	FirstCursor string
+}
+
+// PageOptions end paging before the last page.
+type PageOptions[T any] struct {
+	// MaxPages bounds the pages requested. Zero requests every page.
+	MaxPages int
+	// Stop ends paging at the first item it returns true for, which is not
+	// yielded, such as the first item before the start of a window of an
+	// endpoint that lists newest first.
+	Stop func(item T) bool
+}
+
+// Paginate returns the items of the pages of an endpoint. A page is requested
+// only once the items of the page before were consumed, so at most a page is
+// held in memory. Paging ends after the last page, an empty page, MaxPages,
+// or the item Stop returns true for. An error ends it too, and is yielded
+// with the zero item.
+func Paginate[T any](
+	ctx context.Context,
+	pagination Pagination,
+	fetch PageFetcher[T],
+	options PageOptions[T],
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		position := pagination.GetFirst()
+
+		for pages := 1; ; pages++ {
+			page, err := fetch(ctx, position)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if options.Stop != nil && options.Stop(item) {
+					return
+				}
+
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(page.Items) == 0 || (options.MaxPages > 0 && pages >= options.MaxPages) {
+				return
+			}
+
+			next, ok := pagination.GetNext(position, len(page.Items), page.NextCursor)
+			if !ok {
+				return
+			}
+
+			position = next
+		}
+	}
+}
+
+func (p PageNumberPagination) GetFirst() PagePosition {
+	return PagePosition{Number: p.FirstPage}
+}
+
+func (p PageNumberPagination) GetNext(position PagePosition, items int, _ string) (PagePosition, bool) {
+	if items < p.PageSize {
+		return PagePosition{}, false
+	}
+
+	return PagePosition{Number: position.Number + 1}, true
+}
+
+func (p OffsetPagination) GetFirst() PagePosition {
+	return PagePosition{}
+}
+
+func (p OffsetPagination) GetNext(position PagePosition, items int, _ string) (PagePosition, bool) {
+	if items < p.Limit {
+		return PagePosition{}, false
+	}
+
+	return PagePosition{Offset: position.Offset + items}, true
+}
+
+func (p CursorPagination) GetFirst() PagePosition {
+	return PagePosition{Cursor: p.FirstCursor}
+}
+
+// GetNext ends paging on a page that returns no cursor, or its own, which
+// would otherwise be requested forever.
+func (p CursorPagination) GetNext(position PagePosition, _ int, nextCursor string) (PagePosition, bool) {
+	if nextCursor == "" || nextCursor == position.Cursor {
+		return PagePosition{}, false
+	}
+
+	return PagePosition{Cursor: nextCursor}, true
+}
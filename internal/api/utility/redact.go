@@ -0,0 +1,46 @@
+package utility
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RedactURL replaces the values of the DefaultSensitiveParameters in the query
+// of rawURL, and any user info, with Redacted. It is how URLs with API keys
+// in them are safe to log and put in errors.
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Redacted
+	}
+
+	return redactQuery(parsed, isDefaultSensitive).String()
+}
+
+func isDefaultSensitive(name string) bool {
+	for _, sensitive := range DefaultSensitiveParameters {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactQuery returns a copy of requestURL without user info, and with the
+// values of the query parameters that are sensitive replaced.
+func redactQuery(requestURL *url.URL, sensitive func(name string) bool) *url.URL {
+	redacted := *requestURL
+	redacted.User = nil
+
+	query := redacted.Query()
+	for name := range query {
+		if sensitive(name) {
+			query[name] = []string{Redacted}
+		}
+	}
+
+	redacted.RawQuery = query.Encode()
+
+	return &redacted
+}
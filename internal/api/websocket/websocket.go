@@ -0,0 +1,439 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // The handshake is specified with SHA-1.
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xa
+
+	CloseNormal = 1000
+
+	// MaxMessageSize bounds the messages a Conn reads.
+	MaxMessageSize = 16 << 20
+
+	handshakeGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+var (
+	ErrHandshake       = errors.New("websocket handshake failed")
+	ErrMessageTooLarge = errors.New("websocket message too large")
+	ErrProtocol        = errors.New("websocket protocol error")
+)
+
+// CloseError is returned by ReadMessage when the peer closes the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Conn is a WebSocket connection. It implements as much of RFC 6455 as
+// streaming data sources need: text and binary messages, fragmentation, pings
+// and closes. Reads must come from a single goroutine, writes may come from
+// any.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// client connections mask the frames they send.
+	client bool
+	// readTimeout bounds the wait for each frame when set.
+	readTimeout time.Duration
+
+	writeMu sync.Mutex
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Reason)
+}
+
+// Dial opens a WebSocket connection to a ws:// or wss:// URL. ctx bounds the
+// dial and the handshake only.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	address := endpoint.Host
+	switch endpoint.Scheme {
+	case "ws":
+		endpoint.Scheme = "http"
+		if endpoint.Port() == "" {
+			address = net.JoinHostPort(endpoint.Hostname(), "80")
+		}
+	case "wss":
+		endpoint.Scheme = "https"
+		if endpoint.Port() == "" {
+			address = net.JoinHostPort(endpoint.Hostname(), "443")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme %q", endpoint.Scheme)
+	}
+
+	dialer := &net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: endpoint.Hostname(), MinVersion: tls.VersionTLS12})
+		if handshakeError := tlsConn.HandshakeContext(ctx); handshakeError != nil {
+			conn.Close()
+			return nil, handshakeError
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Unblock the handshake if ctx is cancelled before it completes.
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	reader, err := clientHandshake(conn, endpoint, header)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	return &Conn{conn: conn, reader: reader, client: true}, nil
+}
+
+func clientHandshake(conn net.Conn, endpoint *url.URL, header http.Header) (*bufio.Reader, error) {
+	const (
+		keySize = 16
+	)
+
+	nonce := make([]byte, keySize)
+	_, _ = rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        endpoint,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       endpoint.Host,
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	request.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := request.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		response.Body.Close()
+		return nil, fmt.Errorf("%w: server returned %s", ErrHandshake, response.Status)
+	}
+
+	if response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, fmt.Errorf("%w: invalid Sec-WebSocket-Accept", ErrHandshake)
+	}
+
+	return reader, nil
+}
+
+// Upgrade answers a WebSocket handshake and takes over its connection.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	if r.Method != http.MethodGet || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		!headerContains(r.Header, "Upgrade", "websocket") || !headerContains(r.Header, "Connection", "upgrade") {
+		http.Error(w, "expected a websocket handshake", http.StatusBadRequest)
+		return nil, ErrHandshake
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket upgrade is not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("%w: response cannot be hijacked", ErrHandshake)
+	}
+
+	conn, buffer, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+
+	if _, writeError := conn.Write([]byte(response)); writeError != nil {
+		conn.Close()
+		return nil, writeError
+	}
+
+	return &Conn{conn: conn, reader: buffer.Reader}, nil
+}
+
+// ReadMessage returns the opcode and payload of the next text or binary
+// message. Pings are answered while reading, and a close from the peer is
+// returned as a *CloseError.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	opcode := -1
+	message := []byte{}
+
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case OpPing:
+			if pongError := c.WriteMessage(OpPong, payload); pongError != nil {
+				return 0, nil, pongError
+			}
+			continue
+
+		case OpPong:
+			continue
+
+		case OpClose:
+			closeError := &CloseError{Code: CloseNormal}
+			if len(payload) >= 2 {
+				closeError.Code = int(binary.BigEndian.Uint16(payload))
+				closeError.Reason = string(payload[2:])
+			}
+
+			_ = c.WriteMessage(OpClose, payload)
+
+			return 0, nil, closeError
+
+		case OpContinuation:
+			if opcode < 0 {
+				return 0, nil, fmt.Errorf("%w: continuation without a message", ErrProtocol)
+			}
+
+		case OpText, OpBinary:
+			if opcode >= 0 {
+				return 0, nil, fmt.Errorf("%w: new message inside a fragmented message", ErrProtocol)
+			}
+			opcode = frameOpcode
+
+		default:
+			return 0, nil, fmt.Errorf("%w: unknown opcode %d", ErrProtocol, frameOpcode)
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			return 0, nil, ErrMessageTooLarge
+		}
+
+		message = append(message, payload...)
+
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single frame.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	const (
+		finBit          = 0x80
+		maskBit         = 0x80
+		maxShortLength  = 125
+		maxMediumLength = 0xffff
+		mediumLength    = 126
+		longLength      = 127
+		maskSize        = 4
+	)
+
+	header := []byte{finBit | byte(opcode)}
+
+	maskFlag := byte(0)
+	if c.client {
+		maskFlag = maskBit
+	}
+
+	switch length := len(data); {
+	case length <= maxShortLength:
+		header = append(header, maskFlag|byte(length))
+	case length <= maxMediumLength:
+		header = append(header, maskFlag|mediumLength)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, maskFlag|longLength)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	payload := data
+	if c.client {
+		mask := make([]byte, maskSize)
+		_, _ = rand.Read(mask)
+		header = append(header, mask...)
+
+		payload = make([]byte, len(data))
+		for i, b := range data {
+			payload[i] = b ^ mask[i%maskSize]
+		}
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.conn.Write(append(header, payload...))
+
+	return err
+}
+
+// Close sends a normal close frame and closes the connection without waiting
+// for the peer to answer.
+func (c *Conn) Close() error {
+	const (
+		closeWriteTimeout = time.Second
+	)
+
+	c.conn.SetWriteDeadline(time.Now().Add(closeWriteTimeout))
+	_ = c.WriteMessage(OpClose, binary.BigEndian.AppendUint16(nil, CloseNormal))
+
+	return c.conn.Close()
+}
+
+// SetReadTimeout makes reads fail when no frame, including a pong, arrives
+// within timeout. Zero waits forever.
+func (c *Conn) SetReadTimeout(timeout time.Duration) {
+	c.readTimeout = timeout
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	const (
+		finBit         = 0x80
+		opcodeMask     = 0x0f
+		maskBit        = 0x80
+		lengthMask     = 0x7f
+		mediumLength   = 126
+		longLength     = 127
+		maskSize       = 4
+		maxControlSize = 125
+	)
+
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&finBit != 0
+	opcode := int(header[0] & opcodeMask)
+	masked := header[1]&maskBit != 0
+	length := uint64(header[1] & lengthMask)
+
+	switch length {
+	case mediumLength:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+
+	case longLength:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+
+	if length > MaxMessageSize {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	if opcode >= OpClose && (length > maxControlSize || !fin) {
+		return false, 0, nil, fmt.Errorf("%w: invalid control frame", ErrProtocol)
+	}
+
+	mask := make([]byte, maskSize)
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%maskSize]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + handshakeGUID)) //nolint:gosec // The handshake is specified with SHA-1.
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
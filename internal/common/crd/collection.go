@@ -24,16 +24,46 @@ type DataCollectionSource struct {
 	Type       string            `yaml:"type"`
 	Endpoint   string            `yaml:"endpoint"`
 	Parameters map[string]string `yaml:"parameters,omitempty"`
+	// Secret names the secret holding the API key to collect with, in place
+	// of the provider's default. Keys are never written in a manifest.
+	Secret string `yaml:"secret,omitempty"`
 }
 
+// DataCollectionTargets are what a collection collects data for. Sources of
+// market data take securities, and sources of economic data take series.
 type DataCollectionTargets struct {
-	Securities []DataCollectionSecurity `yaml:"securities"`
+	Securities []DataCollectionSecurity `yaml:"securities,omitempty"`
+	Series     []DataCollectionSeries   `yaml:"series,omitempty"`
 }
 
 type DataCollectionSecurity struct {
 	Symbol string `yaml:"symbol"`
+	// Name is the company name, used by sources that match free text, such as
+	// news feeds, to the security.
+	Name string `yaml:"name,omitempty"`
 }
 
+// DataCollectionSeries is an economic time series, identified by the ID its
+// source uses, e.g. "CPIAUCSL" on FRED.
+type DataCollectionSeries struct {
+	ID string `yaml:"id"`
+}
+
+// ScheduleStreaming collections keep a subscription open for their targets
+// instead of being split into jobs.
+const ScheduleStreaming = "STREAMING"
+
+// ScheduleRecurring collections are collected again every Frequency, which is
+// one of the Frequency constants or a duration such as "15m".
+const ScheduleRecurring = "RECURRING"
+
+const (
+	FrequencyMinute = "MINUTE"
+	FrequencyHourly = "HOURLY"
+	FrequencyDaily  = "DAILY"
+	FrequencyWeekly = "WEEKLY"
+)
+
 type DataCollectionSchedule struct {
 	Type      string `yaml:"type"                json:"type"`
 	Frequency string `yaml:"frequency,omitempty" json:"frequency,omitempty"`
@@ -72,6 +102,10 @@ func (dc *DataCollection) GetSecurities() []DataCollectionSecurity {
 	return dc.Spec.Targets.Securities
 }
 
+func (dc *DataCollection) GetSeries() []DataCollectionSeries {
+	return dc.Spec.Targets.Series
+}
+
 func (dc *DataCollection) GetOptions() DataCollectionOptions {
 	return dc.Spec.Options
 }
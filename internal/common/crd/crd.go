@@ -7,6 +7,7 @@ type CRD interface {
 	GetSource() DataCollectionSource
 	GetSchedule() DataCollectionSchedule
 	GetSecurities() []DataCollectionSecurity
+	GetSeries() []DataCollectionSeries
 	GetOptions() DataCollectionOptions
 	GetJobCount() int
 	Split(batchSize int) []CRD
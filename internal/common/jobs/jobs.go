@@ -9,10 +9,73 @@ import (
 // Note: Oscar
 // We dont need a JobType here because we can use the CRD Kind as the identity.
 
-// Job is a struct containing the job being handled by the manager.
+const (
+	StatusPending  = "pending"
+	StatusRetrying = "retrying"
+	// StatusDeferred jobs wait for the circuit of their provider to close.
+	StatusDeferred  = "deferred"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Job is a struct containing the job being handled by the manager. Each job
+// collects one target of its CRD, either a security Symbol, with its company
+// SecurityName when the CRD gives one, or a Series. StartTime and EndTime bound
+// the window of data it collects.
 type Job struct {
-	CRD       crd.CRD   `json:"crd"`
-	StartTime time.Time `json:"startTime"`
-	EndTime   time.Time `json:"endTime"`
-	Status    string    `json:"status"`
+	ID           string    `json:"id"`
+	CRD          crd.CRD   `json:"crd"`
+	Symbol       string    `json:"symbol"`
+	SecurityName string    `json:"securityName,omitempty"`
+	Series       string    `json:"series,omitempty"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+
+	// ProviderSymbol is the symbol the source knows the security by in the
+	// job's window, when it is not Symbol, such as a former ticker or another
+	// share class separator. Records are still stored under Symbol.
+	ProviderSymbol string `json:"providerSymbol,omitempty"`
+
+	// NotBefore is the earliest time the job may be handed to a worker. The
+	// zero value means the job is runnable immediately.
+	NotBefore time.Time `json:"notBefore"`
+}
+
+// GetCollection returns the name of the CRD the job was created from.
+func (j Job) GetCollection() string {
+	if j.CRD == nil {
+		return ""
+	}
+
+	return j.CRD.GetName()
+}
+
+// GetTarget returns the security symbol or series ID the job collects.
+func (j Job) GetTarget() string {
+	if j.Series != "" {
+		return j.Series
+	}
+
+	return j.Symbol
+}
+
+// GetProviderSymbol returns the symbol to request the job's security by.
+func (j Job) GetProviderSymbol() string {
+	if j.ProviderSymbol != "" {
+		return j.ProviderSymbol
+	}
+
+	return j.Symbol
+}
+
+// GetPriority returns the priority of the CRD the job was created from. Higher
+// values are more urgent.
+func (j Job) GetPriority() int {
+	if j.CRD == nil {
+		return 0
+	}
+
+	return j.CRD.GetOptions().Priority
 }
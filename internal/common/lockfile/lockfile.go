@@ -0,0 +1,130 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/utility"
+)
+
+// Lock is an exclusive, advisory lock on a file that also records the PID of
+// the process holding it. The kernel drops the lock when the holding process
+// exits, so a lock file left behind by a crashed process is reclaimed by the
+// next Acquire.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// LockedError is returned by Acquire when another live process holds the lock.
+type LockedError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("lock %s is held by another process", e.Path)
+	}
+
+	return fmt.Sprintf("lock %s is held by another process (pid %d)", e.Path, e.PID)
+}
+
+// Acquire takes the lock at path without blocking, creating the file and its
+// parent directory if needed.
+func Acquire(path string) (*Lock, error) {
+	const (
+		lockDirPerm  = 0755
+		lockFilePerm = 0644
+	)
+
+	if err := utility.CreateParentDir(path, lockDirPerm); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, lockFilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	previousPID, _ := readPID(file)
+
+	//nolint:gosec // File descriptors fit in an int on all supported platforms.
+	if flockError := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); flockError != nil {
+		file.Close()
+
+		if errors.Is(flockError, syscall.EWOULDBLOCK) {
+			return nil, &LockedError{Path: path, PID: previousPID}
+		}
+
+		return nil, fmt.Errorf("failed to lock %s: %w", path, flockError)
+	}
+
+	// A PID left in an unlocked file means the previous holder did not shut
+	// down cleanly.
+	if previousPID != 0 && previousPID != os.Getpid() {
+		logger.Warnf("Reclaiming stale lock %s left by process %d", path, previousPID)
+	}
+
+	if writeError := writePID(file, os.Getpid()); writeError != nil {
+		file.Close()
+		return nil, writeError
+	}
+
+	return &Lock{file: file, path: path}, nil
+}
+
+// Release clears the recorded PID and drops the lock. The file itself is left
+// in place, since removing it could let two processes lock different files
+// under the same path.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	truncateError := l.file.Truncate(0)
+	closeError := l.file.Close()
+	l.file = nil
+
+	return errors.Join(truncateError, closeError)
+}
+
+func (l *Lock) Path() string {
+	return l.path
+}
+
+func readPID(file *os.File) (int, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+
+	text := strings.TrimSpace(string(content))
+	if text == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(text)
+}
+
+func writePID(file *os.File, pid int) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := file.WriteAt([]byte(strconv.Itoa(pid)+"\n"), 0); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
@@ -1,17 +1,79 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"sync/atomic"
 )
 
 //nolint:gochecknoglobals // gochecknoglobals
 var logger = setupLogger()
 
+//nolint:gochecknoglobals // gochecknoglobals
+var redactor atomic.Pointer[func(string) string]
+
 func setupLogger() *slog.Logger {
 	// TODO: Oscar - Load logging info from config file and cli options
-	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return slog.New(redactingHandler{Handler: slog.NewTextHandler(os.Stdout, nil)})
+}
+
+// SetRedactor makes every message and attribute pass through redact before it
+// is logged, so secrets in errors and URLs are not written to the log.
+func SetRedactor(redact func(string) string) {
+	redactor.Store(&redact)
+}
+
+type redactingHandler struct {
+	slog.Handler
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redact := redactor.Load()
+	if redact == nil {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	redacted := slog.NewRecord(record.Time, record.Level, (*redact)(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr, *redact))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if redact := redactor.Load(); redact != nil {
+		for i, attr := range attrs {
+			attrs[i] = redactAttr(attr, *redact)
+		}
+	}
+
+	return redactingHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr, redact func(string) string) slog.Attr {
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindString, slog.KindAny:
+		return slog.String(attr.Key, redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, 0, len(group))
+		for _, member := range group {
+			redacted = append(redacted, redactAttr(member, redact))
+		}
+		return slog.Group(attr.Key, redacted...)
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
 }
 
 func Debug(msg string, args ...any) {
@@ -0,0 +1,86 @@
+package records
+
+import (
+	"errors"
+	"time"
+)
+
+// Split is a stock split. A 4-for-1 split has a Numerator of 4 and a
+// Denominator of 1, so each share before the ex-date is Numerator /
+// Denominator shares after it.
+type Split struct {
+	Symbol string `json:"symbol"`
+	// ExDate is the first trading date on the split-adjusted basis, at
+	// midnight UTC.
+	ExDate      time.Time `json:"exDate"`
+	Numerator   float64   `json:"numerator"`
+	Denominator float64   `json:"denominator"`
+	Source      string    `json:"source"`
+}
+
+// Dividend is a cash dividend per share. Amount is as declared, not adjusted
+// for later splits. Dates the provider does not have are zero.
+type Dividend struct {
+	Symbol string `json:"symbol"`
+	// ExDate is the first trading date without the dividend, at midnight
+	// UTC.
+	ExDate          time.Time `json:"exDate"`
+	RecordDate      time.Time `json:"recordDate"`
+	PayDate         time.Time `json:"payDate"`
+	DeclarationDate time.Time `json:"declarationDate"`
+	Amount          float64   `json:"amount"`
+	Source          string    `json:"source"`
+}
+
+// GetRatio returns the number of shares after the split per share before it.
+func (s Split) GetRatio() float64 {
+	return s.Numerator / s.Denominator
+}
+
+func (s Split) GetAnchor() string {
+	return s.Symbol
+}
+
+func (s Split) GetTimestamp() time.Time {
+	return s.ExDate
+}
+
+func (s Split) GetKey() string {
+	return s.ExDate.UTC().Format(time.DateOnly)
+}
+
+func (s Split) Validate() error {
+	if s.Symbol == "" || s.ExDate.IsZero() {
+		return errors.New("split is missing its symbol or ex-date")
+	}
+
+	if s.Numerator <= 0 || s.Denominator <= 0 {
+		return errors.New("split ratio must be positive")
+	}
+
+	return nil
+}
+
+func (d Dividend) GetAnchor() string {
+	return d.Symbol
+}
+
+func (d Dividend) GetTimestamp() time.Time {
+	return d.ExDate
+}
+
+func (d Dividend) GetKey() string {
+	return d.ExDate.UTC().Format(time.DateOnly)
+}
+
+func (d Dividend) Validate() error {
+	if d.Symbol == "" || d.ExDate.IsZero() {
+		return errors.New("dividend is missing its symbol or ex-date")
+	}
+
+	if d.Amount <= 0 {
+		return errors.New("dividend amount must be positive")
+	}
+
+	return nil
+}
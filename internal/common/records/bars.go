@@ -0,0 +1,132 @@
+package records
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Resolution is the length of the period covered by a bar.
+type Resolution string
+
+const (
+	Resolution1Min  Resolution = "1min"
+	Resolution5Min  Resolution = "5min"
+	Resolution15Min Resolution = "15min"
+	Resolution30Min Resolution = "30min"
+	Resolution1Hour Resolution = "1hour"
+	Resolution4Hour Resolution = "4hour"
+	Resolution1Day  Resolution = "1day"
+)
+
+// Bar is an OHLCV price bar. Time is the start of the bar in UTC. Daily bars
+// are stamped at midnight UTC of their trading date, so the date reads the
+// same in every timezone.
+type Bar struct {
+	Symbol     string     `json:"symbol"`
+	Resolution Resolution `json:"resolution"`
+	Time       time.Time  `json:"time"`
+	Open       float64    `json:"open"`
+	High       float64    `json:"high"`
+	Low        float64    `json:"low"`
+	Close      float64    `json:"close"`
+	Volume     float64    `json:"volume"`
+	Source     string     `json:"source"`
+	// SplitAdjusted is set for bars the provider already adjusted for splits,
+	// such as FMP's, so their prices and volumes are on the latest share
+	// basis rather than as they traded.
+	SplitAdjusted bool `json:"splitAdjusted,omitempty"`
+}
+
+// Resolutions returns every supported resolution, finest first.
+func Resolutions() []Resolution {
+	return []Resolution{
+		Resolution1Min,
+		Resolution5Min,
+		Resolution15Min,
+		Resolution30Min,
+		Resolution1Hour,
+		Resolution4Hour,
+		Resolution1Day,
+	}
+}
+
+// ParseResolution validates a resolution from a manifest. An empty value is
+// daily.
+func ParseResolution(value string) (Resolution, error) {
+	if value == "" {
+		return Resolution1Day, nil
+	}
+
+	resolution := Resolution(value)
+	if !slices.Contains(Resolutions(), resolution) {
+		return "", fmt.Errorf("unsupported resolution %q, expected one of %v", value, Resolutions())
+	}
+
+	return resolution, nil
+}
+
+func (r Resolution) IsIntraday() bool {
+	return r != Resolution1Day
+}
+
+// GetDuration returns the length of the period covered by a bar.
+func (r Resolution) GetDuration() time.Duration {
+	switch r {
+	case Resolution1Min:
+		return time.Minute
+	case Resolution5Min:
+		return 5 * time.Minute
+	case Resolution15Min:
+		return 15 * time.Minute
+	case Resolution30Min:
+		return 30 * time.Minute
+	case Resolution1Hour:
+		return time.Hour
+	case Resolution4Hour:
+		return 4 * time.Hour
+	case Resolution1Day:
+		return 24 * time.Hour
+	}
+
+	return 0
+}
+
+func (b Bar) GetAnchor() string {
+	return b.Symbol
+}
+
+func (b Bar) GetTimestamp() time.Time {
+	return b.Time
+}
+
+// GetKey identifies a bar by resolution and start time, so bars collected by
+// overlapping windows replace each other.
+func (b Bar) GetKey() string {
+	return string(b.Resolution) + "|" + b.Time.UTC().Format(time.RFC3339)
+}
+
+func (b Bar) Validate() error {
+	if b.Symbol == "" {
+		return errors.New("bar has no symbol")
+	}
+
+	if !slices.Contains(Resolutions(), b.Resolution) {
+		return fmt.Errorf("bar has an invalid resolution %q", b.Resolution)
+	}
+
+	if b.Time.IsZero() {
+		return errors.New("bar has no time")
+	}
+
+	if b.High < b.Low || b.Open < b.Low || b.Open > b.High || b.Close < b.Low || b.Close > b.High {
+		return fmt.Errorf("bar at %s has inconsistent prices", b.Time.Format(time.RFC3339))
+	}
+
+	if b.Low <= 0 || b.Volume < 0 {
+		return fmt.Errorf("bar at %s has a non-positive price or negative volume", b.Time.Format(time.RFC3339))
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package records
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Earnings is an earnings report of a security. Upcoming reports have
+// estimates but no actuals, so the same record serves as the earnings
+// calendar. Values the provider does not have are nil.
+type Earnings struct {
+	Symbol string `json:"symbol"`
+	// ReportDate is the date of the report, at midnight UTC.
+	ReportDate       time.Time `json:"reportDate"`
+	EPSActual        *float64  `json:"epsActual,omitempty"`
+	EPSEstimated     *float64  `json:"epsEstimated,omitempty"`
+	RevenueActual    *float64  `json:"revenueActual,omitempty"`
+	RevenueEstimated *float64  `json:"revenueEstimated,omitempty"`
+	// EPSSurprisePercent and RevenueSurprisePercent are the difference
+	// between the actual and the estimate as a percentage of the estimate.
+	EPSSurprisePercent     *float64 `json:"epsSurprisePercent,omitempty"`
+	RevenueSurprisePercent *float64 `json:"revenueSurprisePercent,omitempty"`
+	Source                 string   `json:"source"`
+}
+
+// SurprisePercent returns how far actual beat estimated as a percentage of the
+// magnitude of estimated, or nil if either is unknown or estimated is zero.
+func SurprisePercent(actual *float64, estimated *float64) *float64 {
+	const (
+		percent = 100
+	)
+
+	if actual == nil || estimated == nil || *estimated == 0 {
+		return nil
+	}
+
+	surprise := (*actual - *estimated) / math.Abs(*estimated) * percent
+	return &surprise
+}
+
+// IsReported returns whether the earnings have been released.
+func (e Earnings) IsReported() bool {
+	return e.EPSActual != nil || e.RevenueActual != nil
+}
+
+func (e Earnings) GetAnchor() string {
+	return e.Symbol
+}
+
+func (e Earnings) GetTimestamp() time.Time {
+	return e.ReportDate
+}
+
+// GetKey identifies a report by its date, so estimates are replaced by the
+// actuals once they are released.
+func (e Earnings) GetKey() string {
+	return e.ReportDate.UTC().Format(time.DateOnly)
+}
+
+func (e Earnings) Validate() error {
+	if e.Symbol == "" {
+		return errors.New("earnings have no symbol")
+	}
+
+	if e.ReportDate.IsZero() {
+		return errors.New("earnings have no report date")
+	}
+
+	return nil
+}
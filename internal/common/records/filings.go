@@ -0,0 +1,98 @@
+package records
+
+import (
+	"errors"
+	"time"
+)
+
+// Filing is a document filed with the SEC, such as a 10-K.
+type Filing struct {
+	Symbol          string    `json:"symbol"`
+	CIK             string    `json:"cik"`
+	AccessionNumber string    `json:"accessionNumber"`
+	Form            string    `json:"form"`
+	FilingDate      time.Time `json:"filingDate"`
+	// ReportDate is the end of the period the filing reports on, zero for
+	// filings that do not report on a period.
+	ReportDate      time.Time `json:"reportDate"`
+	AcceptedAt      time.Time `json:"acceptedAt"`
+	PrimaryDocument string    `json:"primaryDocument,omitempty"`
+	Source          string    `json:"source"`
+}
+
+// Fact is a tagged XBRL value from a filing, such as the revenue of a fiscal
+// year. PeriodStart is zero for values at an instant, such as total assets.
+type Fact struct {
+	Symbol          string    `json:"symbol"`
+	CIK             string    `json:"cik"`
+	Taxonomy        string    `json:"taxonomy"`
+	Concept         string    `json:"concept"`
+	Unit            string    `json:"unit"`
+	Value           float64   `json:"value"`
+	PeriodStart     time.Time `json:"periodStart"`
+	PeriodEnd       time.Time `json:"periodEnd"`
+	FiscalYear      int       `json:"fiscalYear"`
+	FiscalPeriod    string    `json:"fiscalPeriod"`
+	Form            string    `json:"form"`
+	FilingDate      time.Time `json:"filingDate"`
+	AccessionNumber string    `json:"accessionNumber"`
+	Frame           string    `json:"frame,omitempty"`
+	Source          string    `json:"source"`
+}
+
+func (f Filing) GetAnchor() string {
+	return f.Symbol
+}
+
+func (f Filing) GetTimestamp() time.Time {
+	return f.FilingDate
+}
+
+func (f Filing) GetKey() string {
+	return f.AccessionNumber
+}
+
+func (f Filing) Validate() error {
+	if f.Symbol == "" || f.AccessionNumber == "" {
+		return errors.New("filing is missing its symbol or accession number")
+	}
+
+	if f.FilingDate.IsZero() {
+		return errors.New("filing has no filing date")
+	}
+
+	return nil
+}
+
+func (f Fact) GetAnchor() string {
+	return f.Symbol
+}
+
+func (f Fact) GetTimestamp() time.Time {
+	return f.PeriodEnd
+}
+
+// GetKey identifies a fact by concept, unit, period and the filing it is
+// from, since later filings repeat earlier values.
+func (f Fact) GetKey() string {
+	return HashKey(
+		f.Taxonomy,
+		f.Concept,
+		f.Unit,
+		f.PeriodStart.Format(time.DateOnly),
+		f.PeriodEnd.Format(time.DateOnly),
+		f.AccessionNumber,
+	)
+}
+
+func (f Fact) Validate() error {
+	if f.Symbol == "" || f.Taxonomy == "" || f.Concept == "" || f.Unit == "" {
+		return errors.New("fact is missing its symbol, concept or unit")
+	}
+
+	if f.PeriodEnd.IsZero() || f.AccessionNumber == "" {
+		return errors.New("fact is missing its period or filing")
+	}
+
+	return nil
+}
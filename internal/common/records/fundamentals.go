@@ -0,0 +1,65 @@
+package records
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Statement is a kind of financial statement.
+type Statement string
+
+const (
+	StatementIncome   Statement = "income"
+	StatementBalance  Statement = "balance"
+	StatementCashFlow Statement = "cashflow"
+)
+
+// StatementItem is a single line item of a financial statement, such as the
+// revenue on an income statement. FiscalPeriod is "FY" for annual statements
+// and "Q1" to "Q4" for quarterly statements.
+type StatementItem struct {
+	Symbol       string    `json:"symbol"`
+	Statement    Statement `json:"statement"`
+	FiscalYear   int       `json:"fiscalYear"`
+	FiscalPeriod string    `json:"fiscalPeriod"`
+	// ReportDate is the last day of the period the statement reports on.
+	ReportDate time.Time `json:"reportDate"`
+	// FilingDate is when the statement was filed, which is when the item
+	// became known to the market.
+	FilingDate time.Time `json:"filingDate"`
+	Item       string    `json:"item"`
+	Value      float64   `json:"value"`
+	Currency   string    `json:"currency,omitempty"`
+	Source     string    `json:"source"`
+}
+
+func (s StatementItem) GetAnchor() string {
+	return s.Symbol
+}
+
+func (s StatementItem) GetTimestamp() time.Time {
+	return s.ReportDate
+}
+
+// GetKey identifies an item by statement, fiscal period and name, so a
+// restated statement replaces the earlier values.
+func (s StatementItem) GetKey() string {
+	return string(s.Statement) + "|" + strconv.Itoa(s.FiscalYear) + "|" + s.FiscalPeriod + "|" + s.Item
+}
+
+func (s StatementItem) Validate() error {
+	if s.Symbol == "" || s.Statement == "" || s.Item == "" {
+		return errors.New("statement item is missing its symbol, statement or name")
+	}
+
+	if s.FiscalYear == 0 || s.FiscalPeriod == "" {
+		return errors.New("statement item has no fiscal period")
+	}
+
+	if s.ReportDate.IsZero() {
+		return errors.New("statement item has no report date")
+	}
+
+	return nil
+}
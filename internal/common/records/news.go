@@ -0,0 +1,81 @@
+package records
+
+import (
+	"errors"
+	"time"
+)
+
+// NewsArticle is a news fact. Articles are not owned by a single security, the
+// securities they mention are linked through NewsSecurity records.
+type NewsArticle struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Title       string    `json:"title"`
+	Text        string    `json:"text,omitempty"`
+	URL         string    `json:"url"`
+	Site        string    `json:"site,omitempty"`
+	Publisher   string    `json:"publisher,omitempty"`
+	ImageURL    string    `json:"imageUrl,omitempty"`
+}
+
+// NewsSecurity links a news article to a security it is about.
+type NewsSecurity struct {
+	NewsID      string    `json:"newsId"`
+	Symbol      string    `json:"symbol"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// NewsID derives the ID of an article from its URL, so the same article
+// collected twice, or by two providers, is stored once.
+func NewsID(url string) string {
+	return HashKey("news", url)
+}
+
+func (n NewsArticle) GetAnchor() string {
+	return ""
+}
+
+func (n NewsArticle) GetTimestamp() time.Time {
+	return n.PublishedAt
+}
+
+func (n NewsArticle) GetKey() string {
+	return n.ID
+}
+
+func (n NewsArticle) Validate() error {
+	if n.ID == "" {
+		return errors.New("news article has no id")
+	}
+
+	if n.URL == "" {
+		return errors.New("news article has no url")
+	}
+
+	if n.PublishedAt.IsZero() {
+		return errors.New("news article has no publish time")
+	}
+
+	return nil
+}
+
+func (n NewsSecurity) GetAnchor() string {
+	return n.Symbol
+}
+
+func (n NewsSecurity) GetTimestamp() time.Time {
+	return n.PublishedAt
+}
+
+func (n NewsSecurity) GetKey() string {
+	return n.NewsID
+}
+
+func (n NewsSecurity) Validate() error {
+	if n.NewsID == "" || n.Symbol == "" {
+		return errors.New("news security link is missing its article or symbol")
+	}
+
+	return nil
+}
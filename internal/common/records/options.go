@@ -0,0 +1,151 @@
+package records
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OptionType string
+
+const (
+	OptionCall OptionType = "call"
+	OptionPut  OptionType = "put"
+)
+
+// OptionContract is a listed option. Options are securities of their own,
+// identified by their OCC symbol, and are anchored to their underlying so the
+// chain of a security can be found from its symbol.
+type OptionContract struct {
+	// Symbol is the OCC symbol without padding, e.g. AAPL250620C00200000.
+	Symbol     string     `json:"symbol"`
+	Underlying string     `json:"underlying"`
+	Type       OptionType `json:"type"`
+	Strike     float64    `json:"strike"`
+	// Expiration is the expiration date at midnight UTC.
+	Expiration time.Time `json:"expiration"`
+	Source     string    `json:"source"`
+}
+
+// OptionQuote is a snapshot of an option's market at Time. ImpliedVolatility
+// is annualized, 0.25 being 25%.
+type OptionQuote struct {
+	Symbol            string    `json:"symbol"`
+	Underlying        string    `json:"underlying"`
+	Time              time.Time `json:"time"`
+	Bid               float64   `json:"bid"`
+	BidSize           float64   `json:"bidSize"`
+	Ask               float64   `json:"ask"`
+	AskSize           float64   `json:"askSize"`
+	Last              float64   `json:"last"`
+	Volume            float64   `json:"volume"`
+	OpenInterest      float64   `json:"openInterest"`
+	ImpliedVolatility float64   `json:"impliedVolatility"`
+	UnderlyingPrice   float64   `json:"underlyingPrice"`
+	Source            string    `json:"source"`
+}
+
+// ParseOCCSymbol parses an OCC option symbol: the option root, the expiration
+// as YYMMDD, C or P, and the strike in thousandths padded to eight digits.
+// Padding between the root and the expiration is allowed. The returned
+// contract has no underlying or source.
+func ParseOCCSymbol(symbol string) (OptionContract, error) {
+	const (
+		suffixLength = 15
+		dateLength   = 6
+		strikeScale  = 1000
+	)
+
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if len(symbol) <= suffixLength {
+		return OptionContract{}, fmt.Errorf("invalid OCC symbol %q", symbol)
+	}
+
+	suffix := symbol[len(symbol)-suffixLength:]
+	root := strings.TrimSpace(symbol[:len(symbol)-suffixLength])
+
+	expiration, err := time.ParseInLocation("060102", suffix[:dateLength], time.UTC)
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("invalid expiration in OCC symbol %q: %w", symbol, err)
+	}
+
+	contract := OptionContract{Symbol: root + suffix, Expiration: expiration}
+
+	switch suffix[dateLength] {
+	case 'C':
+		contract.Type = OptionCall
+	case 'P':
+		contract.Type = OptionPut
+	default:
+		return OptionContract{}, fmt.Errorf("invalid option type in OCC symbol %q", symbol)
+	}
+
+	strike, err := strconv.ParseUint(suffix[dateLength+1:], 10, 64)
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("invalid strike in OCC symbol %q: %w", symbol, err)
+	}
+
+	contract.Strike = float64(strike) / strikeScale
+
+	return contract, nil
+}
+
+func (o OptionContract) GetAnchor() string {
+	return o.Underlying
+}
+
+func (o OptionContract) GetTimestamp() time.Time {
+	return o.Expiration
+}
+
+func (o OptionContract) GetKey() string {
+	return o.Symbol
+}
+
+func (o OptionContract) Validate() error {
+	if o.Symbol == "" || o.Underlying == "" {
+		return errors.New("option contract is missing its symbol or underlying")
+	}
+
+	if o.Type != OptionCall && o.Type != OptionPut {
+		return fmt.Errorf("option %s has an invalid type %q", o.Symbol, o.Type)
+	}
+
+	if o.Strike <= 0 || o.Expiration.IsZero() {
+		return fmt.Errorf("option %s is missing its strike or expiration", o.Symbol)
+	}
+
+	return nil
+}
+
+func (o OptionQuote) GetAnchor() string {
+	return o.Symbol
+}
+
+func (o OptionQuote) GetTimestamp() time.Time {
+	return o.Time
+}
+
+func (o OptionQuote) GetKey() string {
+	return o.Time.UTC().Format(time.RFC3339)
+}
+
+func (o OptionQuote) Validate() error {
+	if o.Symbol == "" || o.Time.IsZero() {
+		return errors.New("option quote is missing its symbol or time")
+	}
+
+	for _, value := range []float64{o.Bid, o.BidSize, o.Ask, o.AskSize, o.Last, o.Volume, o.OpenInterest} {
+		if value < 0 {
+			return fmt.Errorf("option quote of %s at %s has a negative value", o.Symbol, o.Time.Format(time.RFC3339))
+		}
+	}
+
+	if o.ImpliedVolatility < 0 {
+		return fmt.Errorf("option quote of %s has a negative implied volatility", o.Symbol)
+	}
+
+	return nil
+}
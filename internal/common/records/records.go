@@ -0,0 +1,76 @@
+package records
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Record is a normalized fact produced by a data provider. Records are
+// partitioned by their anchor, usually a security symbol, and ordered by
+// timestamp. Two records with the same anchor and key are the same fact, so
+// writing a record again replaces the earlier copy.
+type Record interface {
+	GetAnchor() string
+	GetTimestamp() time.Time
+	GetKey() string
+	Validate() error
+}
+
+// HashKey derives a stable key from the identifying fields of a record.
+func HashKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Reanchor returns record with the symbol it was collected under replaced by
+// anchor, the symbol it is stored under, such as when a security was
+// collected under its former ticker. Other symbols are left as they are.
+func Reanchor(record Record, symbol string, anchor string) Record {
+	switch row := record.(type) {
+	case Bar:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Tick:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case NewsSecurity:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case StatementItem:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Earnings:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Split:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Dividend:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Filing:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case Fact:
+		row.Symbol = replaceSymbol(row.Symbol, symbol, anchor)
+		return row
+	case OptionContract:
+		row.Underlying = replaceSymbol(row.Underlying, symbol, anchor)
+		return row
+	case OptionQuote:
+		row.Underlying = replaceSymbol(row.Underlying, symbol, anchor)
+		return row
+	default:
+		return record
+	}
+}
+
+func replaceSymbol(value string, symbol string, anchor string) string {
+	if strings.EqualFold(value, symbol) {
+		return anchor
+	}
+
+	return value
+}
@@ -0,0 +1,56 @@
+package records
+
+import (
+	"errors"
+	"time"
+)
+
+// SeriesObservation is a value of an economic time series, such as CPI for a
+// month, as published during [RealtimeStart, RealtimeEnd). A revised value is
+// a separate observation of the same date with a later RealtimeStart, so every
+// vintage of the series is kept.
+type SeriesObservation struct {
+	SeriesID string `json:"seriesId"`
+	// Date is the start of the period observed, at midnight UTC.
+	Date          time.Time `json:"date"`
+	RealtimeStart time.Time `json:"realtimeStart"`
+	// RealtimeEnd is zero while the value is current.
+	RealtimeEnd time.Time `json:"realtimeEnd"`
+	// Value is nil when the source published the observation as missing.
+	Value  *float64 `json:"value"`
+	Source string   `json:"source"`
+}
+
+// IsCurrentAt returns whether the observation was the published value at t.
+func (o SeriesObservation) IsCurrentAt(t time.Time) bool {
+	if t.Before(o.RealtimeStart) {
+		return false
+	}
+
+	return o.RealtimeEnd.IsZero() || t.Before(o.RealtimeEnd)
+}
+
+func (o SeriesObservation) GetAnchor() string {
+	return o.SeriesID
+}
+
+func (o SeriesObservation) GetTimestamp() time.Time {
+	return o.Date
+}
+
+// GetKey identifies an observation by date and vintage.
+func (o SeriesObservation) GetKey() string {
+	return o.Date.UTC().Format(time.DateOnly) + "|" + o.RealtimeStart.UTC().Format(time.DateOnly)
+}
+
+func (o SeriesObservation) Validate() error {
+	if o.SeriesID == "" {
+		return errors.New("series observation has no series")
+	}
+
+	if o.Date.IsZero() || o.RealtimeStart.IsZero() {
+		return errors.New("series observation is missing its date or vintage")
+	}
+
+	return nil
+}
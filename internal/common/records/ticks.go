@@ -0,0 +1,74 @@
+package records
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type TickKind string
+
+const (
+	TickTrade TickKind = "trade"
+	TickQuote TickKind = "quote"
+)
+
+// Tick is a trade or a quote update pushed by a streaming source. Trades set
+// Price and Size, quotes set the bid and ask.
+type Tick struct {
+	Symbol   string    `json:"symbol"`
+	Kind     TickKind  `json:"kind"`
+	Time     time.Time `json:"time"`
+	Price    float64   `json:"price,omitempty"`
+	Size     float64   `json:"size,omitempty"`
+	BidPrice float64   `json:"bidPrice,omitempty"`
+	BidSize  float64   `json:"bidSize,omitempty"`
+	AskPrice float64   `json:"askPrice,omitempty"`
+	AskSize  float64   `json:"askSize,omitempty"`
+	Source   string    `json:"source"`
+}
+
+func (t Tick) GetAnchor() string {
+	return t.Symbol
+}
+
+func (t Tick) GetTimestamp() time.Time {
+	return t.Time
+}
+
+// GetKey identifies a tick by its time and contents. Ticks rarely share a
+// timestamp, and identical ticks at the same time cannot be told apart.
+func (t Tick) GetKey() string {
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+
+	return HashKey(string(t.Kind), t.Time.UTC().Format(time.RFC3339Nano),
+		format(t.Price), format(t.Size), format(t.BidPrice), format(t.BidSize), format(t.AskPrice), format(t.AskSize))
+}
+
+func (t Tick) Validate() error {
+	if t.Symbol == "" {
+		return errors.New("tick has no symbol")
+	}
+
+	if t.Time.IsZero() {
+		return errors.New("tick has no time")
+	}
+
+	switch t.Kind {
+	case TickTrade:
+		if t.Price <= 0 || t.Size < 0 {
+			return fmt.Errorf("trade at %s has a non-positive price or negative size", t.Time.Format(time.RFC3339Nano))
+		}
+	case TickQuote:
+		if t.BidPrice < 0 || t.AskPrice < 0 || t.BidSize < 0 || t.AskSize < 0 {
+			return fmt.Errorf("quote at %s has a negative price or size", t.Time.Format(time.RFC3339Nano))
+		}
+	default:
+		return fmt.Errorf("tick has an invalid kind %q", t.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in logs, errors and URLs.
+const Redacted = "REDACTED"
+
+var (
+	// ErrNotFound is returned for a secret no source has.
+	ErrNotFound = errors.New("secret not found")
+	// ErrInvalidName is returned for a name that is not lowercase letters,
+	// digits, dashes, dots and underscores.
+	ErrInvalidName = errors.New("invalid secret name")
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Value is a secret such as an API key. It formats, marshals and logs as
+// Redacted, so it only leaves the process through Reveal.
+type Value string
+
+func (v Value) Reveal() string {
+	return string(v)
+}
+
+func (v Value) String() string {
+	return Redacted
+}
+
+func (v Value) GoString() string {
+	return Redacted
+}
+
+func (v Value) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+// Source is somewhere secrets are kept. Lookup returns ErrNotFound for a
+// secret the source does not have.
+type Source interface {
+	Lookup(name string) (Value, error)
+}
+
+// Resolver looks secrets up by name in its sources, in order, and remembers
+// every value it returns so Redact can remove it from text. Whether a rotated
+// secret is seen depends on the source: Directory reads its file on every
+// lookup, while the environment is fixed when the process starts and Store
+// is decrypted when it is opened.
+type Resolver struct {
+	sources []Source
+
+	mu       sync.RWMutex
+	resolved map[Value]bool
+}
+
+func NewResolver(sources ...Source) *Resolver {
+	return &Resolver{
+		sources:  sources,
+		resolved: map[Value]bool{},
+	}
+}
+
+// ValidateName returns ErrInvalidName for a name that cannot refer to a
+// secret. Names are also file names, so they cannot contain a path.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w %q, expected lowercase letters, digits, dashes, dots and underscores", ErrInvalidName, name)
+	}
+
+	return nil
+}
+
+// Resolve returns the secret name from the first source that has it. A nil
+// resolver has no secrets.
+func (r *Resolver) Resolve(name string) (Value, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	if r != nil {
+		for _, source := range r.sources {
+			value, err := source.Lookup(name)
+			if errors.Is(err, ErrNotFound) || (err == nil && value == "") {
+				continue
+			} else if err != nil {
+				return "", fmt.Errorf("failed to read secret %q: %w", name, err)
+			}
+
+			r.mu.Lock()
+			r.resolved[value] = true
+			r.mu.Unlock()
+
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+}
+
+// Redact replaces every secret the resolver has returned in text.
+func (r *Resolver) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for value := range r.resolved {
+		text = strings.ReplaceAll(text, value.Reveal(), Redacted)
+	}
+
+	return text
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying value, the secret a provider should
+// authenticate the requests made with the context with, in place of its
+// default.
+func NewContext(ctx context.Context, value Value) context.Context {
+	return context.WithValue(ctx, contextKey{}, value)
+}
+
+// FromContext returns the secret of NewContext, if ctx has one.
+func FromContext(ctx context.Context) (Value, bool) {
+	value, ok := ctx.Value(contextKey{}).(Value)
+	return value, ok && value != ""
+}
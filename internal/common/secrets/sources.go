@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/common/secrets.Source interface
+
+const (
+	// EnvironmentPrefix prefixes the environment variables the daemon reads
+	// secrets from, e.g. STOCKDB_FMP_API_KEY for fmp-api-key.
+	EnvironmentPrefix = "STOCKDB_"
+
+	// CredentialsDirectoryEnvironmentVariable is set by systemd to the
+	// directory of a service's credentials, see LoadCredential= in
+	// systemd.exec(5).
+	CredentialsDirectoryEnvironmentVariable = "CREDENTIALS_DIRECTORY"
+)
+
+// Environment reads secrets from environment variables named Prefix followed
+// by the upper case name, with dashes and dots as underscores.
+type Environment struct {
+	Prefix string
+}
+
+// GetVariable returns the environment variable holding the secret name.
+func (e Environment) GetVariable(name string) string {
+	return e.Prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+func (e Environment) Lookup(name string) (Value, error) {
+	value, ok := os.LookupEnv(e.GetVariable(name))
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return Value(value), nil
+}
+
+// Directory reads each secret from the file named after it in Path, such as
+// the credentials systemd passes a service. A trailing newline is not part of
+// the secret.
+type Directory struct {
+	Path string
+}
+
+func (d Directory) Lookup(name string) (Value, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(d.Path, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", d.Path, err)
+	}
+
+	return Value(strings.TrimRight(string(data), "\r\n")), nil
+}
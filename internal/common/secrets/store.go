@@ -0,0 +1,211 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zydee3/stockdb/internal/common/utility"
+)
+
+// + Implements github.com/zydee3/stockdb/internal/common/secrets.Source interface
+
+const (
+	// KeySize is the size of a store key, for AES-256.
+	KeySize = 32
+
+	storeVersion  = 1
+	storeFilePerm = 0600
+)
+
+// storeAdditionalData binds the ciphertext to the store format, so it cannot
+// be passed off as other data encrypted with the same key.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var storeAdditionalData = []byte("stockdb-secrets-v1")
+
+// Store is a local file of secrets encrypted with AES-GCM. The key is kept
+// apart from the store, e.g. as a systemd credential, so a copy of the state
+// directory does not give the secrets away. The file is decrypted once by
+// OpenStore, so changes saved by another process are read on the next open.
+type Store struct {
+	path string
+	aead cipher.AEAD
+
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+type storeFile struct {
+	Version    int    `json:"version"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// GenerateKeyFile writes a new random key to path. It does not replace an
+// existing key, which would make its store unreadable.
+func GenerateKeyFile(path string) error {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, storeFilePerm)
+	if err != nil {
+		return err
+	}
+
+	_, writeError := file.WriteString(base64.StdEncoding.EncodeToString(key) + "\n")
+
+	return errors.Join(writeError, file.Close())
+}
+
+// ReadKeyFile reads a key written by GenerateKeyFile.
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key in %s, expected %d base64 encoded bytes", path, KeySize)
+	}
+
+	return key, nil
+}
+
+// OpenStore opens the store at path with key. A missing store is empty until
+// it is saved.
+func OpenStore(path string, key []byte) (*Store, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret store key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		path:    path,
+		aead:    aead,
+		secrets: map[string]string{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read secret store: %w", err)
+	}
+
+	file := storeFile{}
+	if unmarshalError := json.Unmarshal(data, &file); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to decode secret store %s: %w", path, unmarshalError)
+	}
+
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported secret store version %d in %s", file.Version, path)
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, storeAdditionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret store %s, the key does not match it: %w", path, err)
+	}
+
+	if unmarshalError := json.Unmarshal(plaintext, &store.secrets); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to decode secret store %s: %w", path, unmarshalError)
+	}
+
+	return store, nil
+}
+
+func (s *Store) Lookup(name string) (Value, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return Value(value), nil
+}
+
+// Names returns the names of the secrets in the store, sorted.
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(s.secrets))
+}
+
+// Set adds or replaces a secret. It is not written until Save.
+func (s *Store) Set(name string, value Value) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	if value == "" {
+		return fmt.Errorf("secret %q is empty", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets[name] = value.Reveal()
+
+	return nil
+}
+
+// Delete removes a secret. It is not written until Save.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.secrets[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+
+	delete(s.secrets, name)
+
+	return nil
+}
+
+// Save encrypts the store with a new nonce and replaces the file.
+func (s *Store) Save() error {
+	s.mu.RLock()
+	plaintext, err := json.Marshal(s.secrets)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, randError := rand.Read(nonce); randError != nil {
+		return randError
+	}
+
+	data, err := json.Marshal(storeFile{
+		Version:    storeVersion,
+		Nonce:      nonce,
+		Ciphertext: s.aead.Seal(nil, nonce, plaintext, storeAdditionalData),
+	})
+	if err != nil {
+		return err
+	}
+
+	return utility.WriteFileAtomic(s.path, append(data, '\n'), storeFilePerm)
+}
@@ -0,0 +1,151 @@
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	figiLength  = 12
+	cusipLength = 9
+	isinLength  = 12
+)
+
+// validateIdentifiers checks the identifiers security gives, so a typo is
+// reported when the master is loaded instead of resolving nothing.
+func validateIdentifiers(security Security) error {
+	if security.FIGI != "" && !isFIGI(security.FIGI) {
+		return fmt.Errorf("invalid FIGI %q", security.FIGI)
+	}
+
+	if security.CUSIP != "" && !isCUSIP(security.CUSIP) {
+		return fmt.Errorf("invalid CUSIP %q", security.CUSIP)
+	}
+
+	if security.ISIN != "" && !isISIN(security.ISIN) {
+		return fmt.Errorf("invalid ISIN %q", security.ISIN)
+	}
+
+	return nil
+}
+
+// isFIGI reports whether value is a FIGI: 12 characters, the third a "G",
+// without vowels, ending in a check digit.
+func isFIGI(value string) bool {
+	if len(value) != figiLength || value[2] != 'G' || strings.ContainsAny(value, "AEIOU") {
+		return false
+	}
+
+	return isAlphanumeric(value) && hasCheckDigit(value, false)
+}
+
+// isCUSIP reports whether value is a CUSIP: 9 characters ending in a check
+// digit. Private placements use "*", "@" and "#".
+func isCUSIP(value string) bool {
+	if len(value) != cusipLength {
+		return false
+	}
+
+	for _, r := range value {
+		if !isAlphanumericRune(r) && !strings.ContainsRune("*@#", r) {
+			return false
+		}
+	}
+
+	return hasCheckDigit(value, false)
+}
+
+// isISIN reports whether value is an ISIN: a country code, 9 characters and a
+// check digit.
+func isISIN(value string) bool {
+	if len(value) != isinLength || !isAlphanumeric(value) {
+		return false
+	}
+
+	for _, r := range value[:2] {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return hasCheckDigit(value, true)
+}
+
+// hasCheckDigit reports whether the last character of value is the check
+// digit of the others, which is the Luhn algorithm over their values, letters
+// counting from 10. ISINs expand letters to two digits before doubling, while
+// CUSIPs and FIGIs double whole values.
+func hasCheckDigit(value string, expandLetters bool) bool {
+	const (
+		base = 10
+	)
+
+	check := value[len(value)-1]
+	if check < '0' || check > '9' {
+		return false
+	}
+
+	digits := []int{}
+	for _, r := range value[:len(value)-1] {
+		v := getCharacterValue(r)
+		if expandLetters && v >= base {
+			digits = append(digits, v/base, v%base)
+		} else {
+			digits = append(digits, v)
+		}
+	}
+
+	sum := 0
+	for i, v := range digits {
+		// Doubling starts from the digit next to the check digit for ISINs,
+		// and from the second character for CUSIPs and FIGIs.
+		double := (len(digits)-i)%2 == 1
+		if !expandLetters {
+			double = i%2 == 1
+		}
+
+		if double {
+			v *= 2
+		}
+
+		sum += v/base + v%base
+	}
+
+	return (base-sum%base)%base == int(check-'0')
+}
+
+func getCharacterValue(r rune) int {
+	const (
+		letterOffset = 10
+		cusipStar    = 36
+		cusipAt      = 37
+		cusipHash    = 38
+	)
+
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'A' && r <= 'Z':
+		return int(r-'A') + letterOffset
+	case r == '*':
+		return cusipStar
+	case r == '@':
+		return cusipAt
+	default:
+		return cusipHash
+	}
+}
+
+func isAlphanumeric(value string) bool {
+	for _, r := range value {
+		if !isAlphanumericRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlphanumericRune(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z')
+}
@@ -0,0 +1,378 @@
+package symbols
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotListed is returned for a window in which a security had no symbol.
+var ErrNotListed = errors.New("security is not listed")
+
+// File is the YAML file the securities master is loaded from.
+type File struct {
+	Securities []Security `yaml:"securities"`
+}
+
+// Security is a security of the master. Its ID does not change when the
+// security is renamed, so records of the security are stored under it. It is
+// usually the current ticker.
+type Security struct {
+	ID   string `yaml:"id"             json:"id"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// FIGI, CUSIP and ISIN are optional identifiers of the security, which
+	// resolve to it like its symbols.
+	FIGI  string `yaml:"figi,omitempty"  json:"figi,omitempty"`
+	CUSIP string `yaml:"cusip,omitempty" json:"cusip,omitempty"`
+	ISIN  string `yaml:"isin,omitempty"  json:"isin,omitempty"`
+
+	// Symbols is the ticker history of the security, oldest first.
+	Symbols []Listing `yaml:"symbols" json:"symbols"`
+}
+
+// Listing is a ticker of a security for a period. From is the first day the
+// ticker was used, and To the first day it was not, both dates. An empty From
+// is the start of the security's history, and an empty To is a ticker still
+// in use.
+type Listing struct {
+	Symbol string `yaml:"symbol"         json:"symbol"`
+	From   string `yaml:"from,omitempty" json:"from,omitempty"`
+	To     string `yaml:"to,omitempty"   json:"to,omitempty"`
+	// Aliases are the ticker as sources that spell it differently know it,
+	// by source type, e.g. {"FMP": "BRK-B"}.
+	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+
+	from time.Time
+	to   time.Time
+}
+
+// Segment is the part of a window in which a security traded under Symbol,
+// as the source it was requested for spells it. Zero times are open bounds.
+type Segment struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+}
+
+// Master resolves the symbols collections and providers use to the securities
+// they denote. Sources disagree on share class separators, such as BRK.B and
+// BRK-B, and tickers are reused after a rename, so symbols resolve for a time.
+// A nil Master knows no securities.
+type Master struct {
+	securities map[string]*Security
+	// listings are the securities by the normalized symbols and aliases they
+	// were listed under.
+	listings map[string][]listingEntry
+	// identifiers are the securities by ID, FIGI, CUSIP and ISIN.
+	identifiers map[string]*Security
+}
+
+type listingEntry struct {
+	security *Security
+	listing  *Listing
+}
+
+// LoadMaster loads the securities master from the YAML file at path.
+func LoadMaster(path string) (*Master, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := File{}
+	if unmarshalError := yaml.Unmarshal(data, &file); unmarshalError != nil {
+		return nil, fmt.Errorf("failed to parse securities master %s: %w", path, unmarshalError)
+	}
+
+	master, err := NewMaster(file.Securities)
+	if err != nil {
+		return nil, fmt.Errorf("invalid securities master %s: %w", path, err)
+	}
+
+	return master, nil
+}
+
+// NewMaster validates securities and returns their master. Identifiers must
+// be well formed, and no symbol may denote two securities at once.
+func NewMaster(securities []Security) (*Master, error) {
+	m := &Master{
+		securities:  map[string]*Security{},
+		listings:    map[string][]listingEntry{},
+		identifiers: map[string]*Security{},
+	}
+
+	for i := range securities {
+		security, err := parseSecurity(securities[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := m.securities[security.ID]; exists {
+			return nil, fmt.Errorf("duplicate security %s", security.ID)
+		}
+
+		m.securities[security.ID] = security
+
+		for _, identifier := range []string{security.ID, security.FIGI, security.CUSIP, security.ISIN} {
+			if identifier == "" {
+				continue
+			}
+
+			if other, exists := m.identifiers[identifier]; exists && other != security {
+				return nil, fmt.Errorf("identifier %s of security %s is also used by %s", identifier, security.ID, other.ID)
+			}
+
+			m.identifiers[identifier] = security
+		}
+
+		for j := range security.Symbols {
+			if err = m.addListing(security, &security.Symbols[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Resolve returns the security symbol denotes at time at, or the current one
+// if at is zero. Symbols match whatever share class separator they use, and
+// IDs, FIGIs, CUSIPs and ISINs resolve too. A symbol no longer in use at at
+// resolves to the security that last used it.
+func (m *Master) Resolve(symbol string, at time.Time) (Security, bool) {
+	if m == nil {
+		return Security{}, false
+	}
+
+	key := NormalizeSymbol(symbol)
+	entries := m.listings[key]
+
+	for _, entry := range entries {
+		if entry.listing.contains(at) {
+			return *entry.security, true
+		}
+	}
+
+	if security, exists := m.identifiers[key]; exists {
+		return *security, true
+	}
+
+	if len(entries) == 0 {
+		return Security{}, false
+	}
+
+	latest := slices.MaxFunc(entries, func(a, b listingEntry) int {
+		return a.listing.from.Compare(b.listing.from)
+	})
+
+	return *latest.security, true
+}
+
+// GetSymbol returns the ticker of the security at time at, or its current
+// ticker if at is zero. It returns the ID if the security had no ticker then.
+func (s Security) GetSymbol(at time.Time) string {
+	for _, listing := range s.Symbols {
+		if listing.contains(at) {
+			return listing.Symbol
+		}
+	}
+
+	return s.ID
+}
+
+// GetSegments splits the window [from, to] by the tickers of the security,
+// spelled as source does. A window without a start is left to the source,
+// which usually returns recent data, so it is collected under the ticker in
+// use at its end only. It returns ErrNotListed if the security had no ticker
+// in the window.
+func (s Security) GetSegments(source string, from time.Time, to time.Time) ([]Segment, error) {
+	segments := []Segment{}
+
+	for _, listing := range s.Symbols {
+		if from.IsZero() {
+			if listing.contains(to) {
+				segments = append(segments, Segment{Symbol: listing.getAlias(source), To: to})
+			}
+
+			continue
+		}
+
+		if !listing.overlaps(from, to) {
+			continue
+		}
+
+		segment := Segment{Symbol: listing.getAlias(source), From: from, To: to}
+		if listing.from.After(from) {
+			segment.From = listing.from
+		}
+
+		// The window is inclusive and a listing ends the day before To.
+		if end := listing.getEnd(); !end.IsZero() && (to.IsZero() || end.Before(to)) {
+			segment.To = end
+		}
+
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("%w: %s has no symbol in the window", ErrNotListed, s.ID)
+	}
+
+	return segments, nil
+}
+
+// NormalizeSymbol returns symbol in upper case with "." as its share class
+// separator, as the master stores symbols.
+func NormalizeSymbol(symbol string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '/', ' ':
+			return '.'
+		default:
+			return r
+		}
+	}, strings.ToUpper(strings.TrimSpace(symbol)))
+}
+
+func (m *Master) addListing(security *Security, listing *Listing) error {
+	keys := []string{NormalizeSymbol(listing.Symbol)}
+	for _, alias := range listing.Aliases {
+		if key := NormalizeSymbol(alias); !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		for _, entry := range m.listings[key] {
+			if entry.security != security && entry.listing.overlaps(listing.from, listing.getEnd()) {
+				return fmt.Errorf("symbol %s denotes both %s and %s", key, entry.security.ID, security.ID)
+			}
+		}
+
+		m.listings[key] = append(m.listings[key], listingEntry{security: security, listing: listing})
+	}
+
+	return nil
+}
+
+// parseSecurity checks security and returns a copy with its symbols
+// normalized and ordered, and their dates parsed.
+func parseSecurity(security Security) (*Security, error) {
+	parsed := security
+	parsed.ID = NormalizeSymbol(security.ID)
+	parsed.FIGI = strings.ToUpper(strings.TrimSpace(security.FIGI))
+	parsed.CUSIP = strings.ToUpper(strings.TrimSpace(security.CUSIP))
+	parsed.ISIN = strings.ToUpper(strings.TrimSpace(security.ISIN))
+
+	if parsed.ID == "" {
+		return nil, errors.New("security without an id")
+	}
+
+	if len(security.Symbols) == 0 {
+		return nil, fmt.Errorf("security %s has no symbols", parsed.ID)
+	}
+
+	if err := validateIdentifiers(parsed); err != nil {
+		return nil, fmt.Errorf("security %s: %w", parsed.ID, err)
+	}
+
+	parsed.Symbols = make([]Listing, 0, len(security.Symbols))
+	for _, listing := range security.Symbols {
+		listing, err := parseListing(listing)
+		if err != nil {
+			return nil, fmt.Errorf("security %s: %w", parsed.ID, err)
+		}
+
+		parsed.Symbols = append(parsed.Symbols, listing)
+	}
+
+	slices.SortFunc(parsed.Symbols, func(a, b Listing) int {
+		return a.from.Compare(b.from)
+	})
+
+	for i := 1; i < len(parsed.Symbols); i++ {
+		previous, listing := parsed.Symbols[i-1], parsed.Symbols[i]
+		if previous.to.IsZero() || previous.to.After(listing.from) {
+			return nil, fmt.Errorf("security %s: symbols %s and %s overlap", parsed.ID, previous.Symbol, listing.Symbol)
+		}
+	}
+
+	return &parsed, nil
+}
+
+func parseListing(listing Listing) (Listing, error) {
+	listing.Symbol = NormalizeSymbol(listing.Symbol)
+	if listing.Symbol == "" {
+		return Listing{}, errors.New("listing without a symbol")
+	}
+
+	aliases := make(map[string]string, len(listing.Aliases))
+	for source, alias := range listing.Aliases {
+		aliases[strings.ToUpper(source)] = strings.TrimSpace(alias)
+	}
+
+	listing.Aliases = aliases
+
+	var err error
+	if listing.from, err = parseDate(listing.From); err != nil {
+		return Listing{}, fmt.Errorf("symbol %s: invalid from: %w", listing.Symbol, err)
+	}
+
+	if listing.to, err = parseDate(listing.To); err != nil {
+		return Listing{}, fmt.Errorf("symbol %s: invalid to: %w", listing.Symbol, err)
+	}
+
+	if !listing.from.IsZero() && !listing.to.IsZero() && !listing.from.Before(listing.to) {
+		return Listing{}, fmt.Errorf("symbol %s: from %s is not before to %s", listing.Symbol, listing.From, listing.To)
+	}
+
+	return listing, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+// contains reports whether the listing was in use at time at, or is still in
+// use if at is zero.
+func (l *Listing) contains(at time.Time) bool {
+	if at.IsZero() {
+		return l.to.IsZero()
+	}
+
+	return (l.from.IsZero() || !at.Before(l.from)) && (l.to.IsZero() || at.Before(l.to))
+}
+
+// overlaps reports whether the listing was in use at some time of the window
+// [from, to]. Zero times are open bounds.
+func (l *Listing) overlaps(from time.Time, to time.Time) bool {
+	if !l.to.IsZero() && !from.IsZero() && !from.Before(l.to) {
+		return false
+	}
+
+	return l.from.IsZero() || to.IsZero() || !to.Before(l.from)
+}
+
+// getEnd returns the last time the listing was in use, zero if it still is.
+func (l *Listing) getEnd() time.Time {
+	if l.to.IsZero() {
+		return time.Time{}
+	}
+
+	return l.to.Add(-time.Nanosecond)
+}
+
+func (l *Listing) getAlias(source string) string {
+	return cmp.Or(l.Aliases[source], l.Symbol)
+}
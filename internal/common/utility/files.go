@@ -0,0 +1,35 @@
+package utility
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file at path with data. The data is written to
+// a temporary file in the same directory and renamed over path, so readers
+// see either the old or the new file and never a partial one.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, writeError := temp.Write(data); writeError != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return writeError
+	}
+
+	if closeError := errors.Join(temp.Chmod(perm), temp.Sync(), temp.Close()); closeError != nil {
+		os.Remove(temp.Name())
+		return closeError
+	}
+
+	if renameError := os.Rename(temp.Name(), path); renameError != nil {
+		os.Remove(temp.Name())
+		return renameError
+	}
+
+	return nil
+}
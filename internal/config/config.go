@@ -4,4 +4,67 @@ import "time"
 
 const (
 	DaemonShutdownTimeout time.Duration = 30 * time.Second
+	JobQueueSize          uint          = 1024
+
+	StateDirectory  = "/var/lib/stockdb"
+	LockFileName    = "stockd.pid"
+	HistoryFileName = "history.jsonl"
+
+	// ImportProgressFileName records how far file imports got.
+	ImportProgressFileName = "imports.json"
+
+	// SecretStoreFileName is the encrypted secret store, and
+	// SecretStoreKeyCredential the systemd credential holding its key.
+	SecretStoreFileName      = "secrets.enc"
+	SecretStoreKeyCredential = "stockdb-secrets-key"
+
+	// SecuritiesFileName is the securities master, which resolves the symbols
+	// of collections to securities.
+	SecuritiesFileName = "securities.yaml"
+
+	// HTTPCacheDirectoryName holds cached provider responses, at most
+	// HTTPCacheSize bytes of them.
+	HTTPCacheDirectoryName       = "http-cache"
+	HTTPCacheSize          int64 = 256 << 20
+
+	// ApplyTimeout bounds how long stockctl apply waits for room in a full
+	// job queue.
+	ApplyTimeout time.Duration = 30 * time.Second
+
+	HistoryRetention          time.Duration = 90 * 24 * time.Hour
+	HistoryCompactionInterval time.Duration = time.Hour
+)
+
+const (
+	WorkerCount = 4
+
+	// RetryBaseDelay is the backoff before the first retry of a failed job. It
+	// doubles with each further attempt, up to RetryMaxDelay.
+	RetryBaseDelay time.Duration = 5 * time.Second
+	RetryMaxDelay  time.Duration = 10 * time.Minute
+
+	// StreamReconnectBaseDelay is the wait before reconnecting a dropped
+	// stream. It doubles with each failed reconnect, up to
+	// StreamReconnectMaxDelay.
+	StreamReconnectBaseDelay time.Duration = time.Second
+	StreamReconnectMaxDelay  time.Duration = time.Minute
+	// StreamFlushInterval is how often bars aggregated from a stream are
+	// checked for completion.
+	StreamFlushInterval time.Duration = time.Second
+
+	// CircuitWindowSize is how many of the latest jobs of a provider endpoint
+	// decide whether it is failing. Its circuit opens once
+	// CircuitFailureRate of them failed, and at least
+	// CircuitMinimumRequests ran. An open circuit defers jobs for
+	// CircuitOpenDuration, doubling while the endpoint keeps failing up to
+	// CircuitMaxOpenDuration.
+	CircuitWindowSize                    = 20
+	CircuitMinimumRequests               = 5
+	CircuitFailureRate                   = 0.5
+	CircuitOpenDuration    time.Duration = 30 * time.Second
+	CircuitMaxOpenDuration time.Duration = 10 * time.Minute
+
+	HTTPTimeout       time.Duration = 30 * time.Second
+	HTTPRetryCount                  = 3
+	HTTPRetryWaitTime time.Duration = time.Second
 )
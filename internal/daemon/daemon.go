@@ -1,40 +1,101 @@
 package daemon
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/lockfile"
 	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	"github.com/zydee3/stockdb/internal/common/symbols"
 	"github.com/zydee3/stockdb/internal/common/version"
 	daemonConfig "github.com/zydee3/stockdb/internal/config"
+	"github.com/zydee3/stockdb/internal/factory"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/storage"
 	"github.com/zydee3/stockdb/internal/unix/server"
+	"github.com/zydee3/stockdb/internal/unix/server/handlers"
 	"github.com/zydee3/stockdb/internal/unix/socket"
 )
 
+// Options configures a Daemon.
+type Options struct {
+	// StateDirectory holds the daemon's lock file and persistent state.
+	StateDirectory string
+
+	// HistoryRetention is how long finished jobs are kept in the job history.
+	// Zero keeps them forever.
+	HistoryRetention time.Duration
+
+	// SocketPath is where the daemon listens for stockctl. It defaults to
+	// socket.SocketPath.
+	SocketPath string
+
+	// SecuritiesPath is the securities master. It defaults to
+	// config.SecuritiesFileName in the state directory, and symbols are used
+	// as given if that file does not exist.
+	SecuritiesPath string
+
+	Providers ProviderOptions
+	Secrets   SecretOptions
+}
+
 type Daemon struct {
+	options       Options
 	ctx           context.Context
 	cancelFunc    context.CancelFunc
 	serviceGroup  sync.WaitGroup
 	errors        chan error
 	shutdownTimer *time.Timer
+	jobQueue      jobqueue.InspectableFullJobQueue
+	delayedQueue  jobqueue.InspectableInputJobQueue
+	lock          *lockfile.Lock
+	history       *history.Store
+	breakers      *breaker.Set
+	providers     *provider.Registry
+	secrets       *secrets.Resolver
+	symbols       *symbols.Master
+	store         *storage.Store
+	streams       *factory.Streams
+	manager       *factory.Manager
 }
 
-func NewDaemon(ctx context.Context) *Daemon {
+func NewDaemon(ctx context.Context, options Options) *Daemon {
 	const (
 		errorChannelSize = 10
 	)
 	ctx, cancel := context.WithCancel(ctx)
+	jobQueue := jobqueue.NewPriorityJobQueue(ctx, daemonConfig.JobQueueSize)
 	return &Daemon{
-		ctx:        ctx,
-		cancelFunc: cancel,
-		errors:     make(chan error, errorChannelSize), // Buffer for component errors
+		options:      options,
+		ctx:          ctx,
+		cancelFunc:   cancel,
+		errors:       make(chan error, errorChannelSize), // Buffer for component errors
+		jobQueue:     jobQueue,
+		delayedQueue: jobqueue.NewDelayedJobQueue(ctx, jobQueue, clock.NewRealClock()),
+		breakers: breaker.NewSet(breaker.Options{
+			WindowSize:      daemonConfig.CircuitWindowSize,
+			MinimumRequests: daemonConfig.CircuitMinimumRequests,
+			FailureRate:     daemonConfig.CircuitFailureRate,
+			OpenDuration:    daemonConfig.CircuitOpenDuration,
+			MaxOpenDuration: daemonConfig.CircuitMaxOpenDuration,
+		}, clock.NewRealClock()),
+		store: storage.NewStore(),
 	}
 }
 
@@ -42,9 +103,67 @@ func (d *Daemon) Start() error {
 	pid := os.Getpid()
 	logger.Infof("Starting Daemon (PID: %d)", pid)
 
+	// Take the instance lock before touching the socket, which a second daemon
+	// would otherwise delete from under the running one.
+	lock, err := lockfile.Acquire(filepath.Join(d.options.StateDirectory, daemonConfig.LockFileName))
+	if err != nil {
+		var lockedError *lockfile.LockedError
+		if errors.As(err, &lockedError) {
+			return fmt.Errorf("another stockd is already running: %w", err)
+		}
+		return fmt.Errorf("failed to acquire instance lock: %w", err)
+	}
+
+	d.lock = lock
+
+	historyPath := filepath.Join(d.options.StateDirectory, daemonConfig.HistoryFileName)
+	jobHistory, err := history.Open(historyPath, d.options.HistoryRetention, clock.NewRealClock())
+	if err != nil {
+		d.releaseLock()
+		return fmt.Errorf("failed to open job history: %w", err)
+	}
+
+	d.history = jobHistory
+
+	resolver, err := newSecretResolver(d.options.StateDirectory, d.options.Secrets)
+	if err != nil {
+		d.closeHistory()
+		d.releaseLock()
+		return fmt.Errorf("failed to open secrets: %w", err)
+	}
+
+	d.secrets = resolver
+	logger.SetRedactor(resolver.Redact)
+
+	providers, err := newProviderRegistry(d.options.StateDirectory, d.options.Providers, d.secrets)
+	if err != nil {
+		d.closeHistory()
+		d.releaseLock()
+		return fmt.Errorf("failed to register providers: %w", err)
+	}
+
+	master, err := loadSecuritiesMaster(d.options)
+	if err != nil {
+		d.closeHistory()
+		d.releaseLock()
+		return fmt.Errorf("failed to load securities master: %w", err)
+	}
+
+	d.providers = providers
+	d.symbols = master
+	d.streams = factory.NewStreams(d.ctx, factory.StreamDependencies{
+		Providers: d.providers,
+		Store:     d.store,
+		Symbols:   d.symbols,
+		Clock:     clock.NewRealClock(),
+	})
+	d.manager = factory.NewManager(d.delayedQueue, d.providers, d.streams, d.symbols)
+
 	services := []func(){
 		d.runSocketServer,
-		// todo: add other services for manager and worker
+		d.runHistoryCompaction,
+		d.runWorkers,
+		d.runStreams,
 	}
 
 	// Initialize and start each service
@@ -60,7 +179,16 @@ func (d *Daemon) Start() error {
 func (d *Daemon) runSocketServer() {
 	defer d.serviceGroup.Done()
 
-	err := server.StartServer(d.ctx, socket.SocketPath)
+	deps := handlers.Dependencies{
+		Manager:  d.manager,
+		JobQueue: jobqueue.NewInspectableGroup(d.jobQueue, d.delayedQueue),
+		History:  d.history,
+		Breakers: d.breakers,
+		Store:    d.store,
+		Symbols:  d.symbols,
+	}
+
+	err := server.StartServer(d.ctx, cmp.Or(d.options.SocketPath, socket.SocketPath), deps)
 
 	// If the context is cancelled, it means the daemon is shutting down
 	// and we don't want to report that as an error.
@@ -69,6 +197,66 @@ func (d *Daemon) runSocketServer() {
 	}
 }
 
+// runWorkers runs the worker pool. The request budgets of providers are spent
+// by their HTTP clients, so jobs answered from the HTTP cache are not held
+// back by them.
+func (d *Daemon) runWorkers() {
+	defer d.serviceGroup.Done()
+
+	deps := factory.WorkerDependencies{
+		Jobs:      d.jobQueue,
+		Retries:   d.delayedQueue,
+		Providers: d.providers,
+		Store:     d.store,
+		History:   d.history,
+		Secrets:   d.secrets,
+		Breakers:  d.breakers,
+		Clock:     clock.NewRealClock(),
+	}
+
+	var workerGroup sync.WaitGroup
+	for id := range daemonConfig.WorkerCount {
+		workerGroup.Add(1)
+		go func() {
+			defer workerGroup.Done()
+			if err := factory.NewWorker(id, deps).Run(d.ctx); err != nil && d.ctx.Err() == nil {
+				d.errors <- fmt.Errorf("worker %d error: %w", id, err)
+			}
+		}()
+	}
+
+	workerGroup.Wait()
+}
+
+// runStreams keeps the daemon running until every streaming collection has
+// flushed its buffered bars after shutdown.
+func (d *Daemon) runStreams() {
+	defer d.serviceGroup.Done()
+
+	<-d.ctx.Done()
+	d.streams.Wait()
+}
+
+// runHistoryCompaction periodically drops job history entries that are past
+// the retention period.
+func (d *Daemon) runHistoryCompaction() {
+	defer d.serviceGroup.Done()
+
+	ticker := time.NewTicker(daemonConfig.HistoryCompactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.history.Compact(); err != nil {
+				logger.Errorf("failed to compact job history: %v", err)
+			}
+		}
+	}
+}
+
 func (d *Daemon) Run() error {
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -116,16 +304,103 @@ func (d *Daemon) Shutdown() error {
 		// todo add handling here
 	}
 
+	d.closeHistory()
+	d.releaseLock()
+
 	return nil
 }
 
-func Init() {
-	cmd := &cli.Command{
+// loadSecuritiesMaster loads the securities master of options. Without one,
+// the symbols of collections are used as given.
+func loadSecuritiesMaster(options Options) (*symbols.Master, error) {
+	if options.SecuritiesPath != "" {
+		return symbols.LoadMaster(options.SecuritiesPath)
+	}
+
+	master, err := symbols.LoadMaster(filepath.Join(options.StateDirectory, daemonConfig.SecuritiesFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return master, err
+}
+
+func (d *Daemon) closeHistory() {
+	if err := d.history.Close(); err != nil {
+		logger.Errorf("failed to close job history: %v", err)
+	}
+}
+
+func (d *Daemon) releaseLock() {
+	if err := d.lock.Release(); err != nil {
+		logger.Errorf("failed to release instance lock: %v", err)
+	}
+}
+
+// NewCommand returns the stockd command, which runs the daemon until it is
+// stopped.
+func NewCommand() *cli.Command {
+	return &cli.Command{
 		Name:        "stockd",
 		Description: "Daemon for StockDB",
 		Version:     version.GetVersion(),
-		Action: func(ctx context.Context, _ *cli.Command) error {
-			d := NewDaemon(ctx)
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "state-dir",
+				Usage: "directory for the instance lock and persistent state",
+				Value: daemonConfig.StateDirectory,
+			},
+			&cli.DurationFlag{
+				Name:  "history-retention",
+				Usage: "how long finished jobs are kept in the job history, 0 keeps them forever",
+				Value: daemonConfig.HistoryRetention,
+			},
+			&cli.StringFlag{
+				Name:  "socket",
+				Usage: "path of the socket stockctl connects to",
+				Value: socket.SocketPath,
+			},
+			&cli.StringFlag{
+				Name:  "securities",
+				Usage: "securities master file, defaults to " + daemonConfig.SecuritiesFileName + " in the state directory",
+			},
+			&cli.StringFlag{Name: "fmp-url", Usage: "base URL of the FMP API"},
+			&cli.StringFlag{Name: "fmp-stream-url", Usage: "URL of the FMP WebSocket stream"},
+			&cli.StringFlag{Name: "fred-url", Usage: "base URL of the FRED API"},
+			&cli.StringFlag{Name: "edgar-url", Usage: "base URL of the EDGAR APIs"},
+			&cli.StringFlag{
+				Name:  "edgar-tickers-url",
+				Usage: "URL of the EDGAR ticker list, defaults to /files/company_tickers.json under --edgar-url",
+			},
+			&cli.StringFlag{Name: "cboe-url", Usage: "base URL of Cboe's delayed quotes"},
+			&cli.Int64Flag{
+				Name:  "http-cache-size",
+				Usage: "bytes of provider responses cached in the state directory, a negative size disables the cache",
+				Value: daemonConfig.HTTPCacheSize,
+			},
+			&cli.StringFlag{Name: "secrets-dir", Usage: "directory with a file per secret, named after the secret"},
+			&cli.StringFlag{Name: "secrets-key", Usage: "key file of the encrypted secret store in the state directory"},
+		},
+		Commands: []*cli.Command{
+			&secretsCommand,
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			d := NewDaemon(ctx, Options{
+				StateDirectory:   cmd.String("state-dir"),
+				HistoryRetention: cmd.Duration("history-retention"),
+				SocketPath:       cmd.String("socket"),
+				SecuritiesPath:   cmd.String("securities"),
+				Providers: ProviderOptions{
+					FMPBaseURL:      cmd.String("fmp-url"),
+					FMPStreamURL:    cmd.String("fmp-stream-url"),
+					FREDBaseURL:     cmd.String("fred-url"),
+					EDGARBaseURL:    cmd.String("edgar-url"),
+					EDGARTickersURL: cmd.String("edgar-tickers-url"),
+					CboeBaseURL:     cmd.String("cboe-url"),
+					HTTPCacheSize:   cmd.Int64("http-cache-size"),
+				},
+				Secrets: secretOptionsFromCommand(cmd),
+			})
 
 			if err := d.Run(); err != nil {
 				return cli.Exit(err, 1)
@@ -134,8 +409,10 @@ func Init() {
 			return nil
 		},
 	}
+}
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
+func Init() {
+	if err := NewCommand().Run(context.Background(), os.Args); err != nil {
 		logger.Error("%w", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,211 @@
+package daemon
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/zydee3/stockdb/internal/api/cboe"
+	"github.com/zydee3/stockdb/internal/api/edgar"
+	"github.com/zydee3/stockdb/internal/api/file"
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/fred"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/api/rss"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	daemonConfig "github.com/zydee3/stockdb/internal/config"
+)
+
+const (
+	edgarUserAgentEnvironmentVariable = "STOCKDB_EDGAR_USER_AGENT"
+
+	// fmpAPIKeySecret and fredAPIKeySecret are the secrets providers use for
+	// collections that do not name one, e.g. STOCKDB_FMP_API_KEY. They are
+	// resolved once, when the daemon starts.
+	fmpAPIKeySecret  = "fmp-api-key"
+	fredAPIKeySecret = "fred-api-key"
+)
+
+// ProviderOptions points providers at other servers than their public APIs,
+// such as a mirror or a fake server in tests. Empty URLs use each provider's
+// default.
+type ProviderOptions struct {
+	FMPBaseURL   string
+	FMPStreamURL string
+	FREDBaseURL  string
+	EDGARBaseURL string
+	// EDGARTickersURL defaults to the ticker list under EDGARBaseURL if that
+	// is set, since SEC serves it from another host than the APIs.
+	EDGARTickersURL string
+	CboeBaseURL     string
+
+	// HTTPCacheSize bounds the provider responses cached in the state
+	// directory, in bytes. Zero is daemonConfig.HTTPCacheSize and a negative
+	// size disables the cache.
+	HTTPCacheSize int64
+}
+
+// newProviderRegistry registers every data provider the daemon can collect
+// from. Providers that keep state keep it in stateDirectory.
+func newProviderRegistry(
+	stateDirectory string,
+	options ProviderOptions,
+	resolver *secrets.Resolver,
+) (*provider.Registry, error) {
+	cache, err := openHTTPCache(stateDirectory, options.HTTPCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := httpUtil.HTTPClient{
+		Client:        &http.Client{Timeout: daemonConfig.HTTPTimeout},
+		RetryCount:    daemonConfig.HTTPRetryCount,
+		RetryWaitTime: daemonConfig.HTTPRetryWaitTime,
+		Cache:         cache,
+	}
+
+	registry := provider.NewRegistry()
+
+	fmpAPIKey, err := resolveDefaultSecret(resolver, fmpAPIKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	fredAPIKey, err := resolveDefaultSecret(resolver, fredAPIKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	fmpBudget := newBudget()
+	fmpClient := fmp.NewHTTPClient(withBudget(httpClient, fmp.CacheTTLs(), fmpBudget), fmpAPIKey, options.FMPBaseURL)
+	fmpStream := fmp.NewStreamClient(cmp.Or(options.FMPStreamURL, fmp.StreamURL), fmpAPIKey)
+	if err := register(registry, fmp.NewProvider(fmpClient, fmpStream), fmpBudget); err != nil {
+		return nil, err
+	}
+
+	// SEC blocks requests without a contact User-Agent, so EDGAR is only
+	// available once one is configured. The client paces itself to SEC's
+	// limit.
+	edgarClient, err := edgar.NewHTTPClient(withBudget(httpClient, edgar.CacheTTLs(), nil), edgar.ClientOptions{
+		UserAgent:  os.Getenv(edgarUserAgentEnvironmentVariable),
+		BaseURL:    options.EDGARBaseURL,
+		TickersURL: getEDGARTickersURL(options),
+	})
+	if err != nil {
+		logger.Warnf("EDGAR source is disabled, set %s to enable it: %v", edgarUserAgentEnvironmentVariable, err)
+	} else if registerError := registry.Register(edgar.NewProvider(edgarClient)); registerError != nil {
+		return nil, registerError
+	}
+
+	fredBudget := newBudget()
+	fredClient := fred.NewHTTPClient(withBudget(httpClient, fred.CacheTTLs(), fredBudget), fredAPIKey, options.FREDBaseURL)
+	if err := register(registry, fred.NewProvider(fredClient), fredBudget); err != nil {
+		return nil, err
+	}
+
+	// Feeds and option chains are only cached when they can be revalidated.
+	rssBudget := newBudget()
+	rssProvider := rss.NewProvider(withBudget(httpClient, nil, rssBudget), clock.NewRealClock())
+	if err := register(registry, rssProvider, rssBudget); err != nil {
+		return nil, err
+	}
+
+	cboeBudget := newBudget()
+	cboeClient := cboe.NewHTTPClient(withBudget(httpClient, nil, cboeBudget), options.CboeBaseURL)
+	if err := register(registry, cboe.NewProvider(cboeClient), cboeBudget); err != nil {
+		return nil, err
+	}
+
+	importProgress, err := file.OpenProgress(filepath.Join(stateDirectory, daemonConfig.ImportProgressFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import progress: %w", err)
+	}
+
+	if registerError := registry.Register(file.NewProvider(importProgress)); registerError != nil {
+		return nil, registerError
+	}
+
+	return registry, nil
+}
+
+// getEDGARTickersURL returns the configured ticker list URL, or the path of
+// SEC's ticker list under a configured EDGAR base URL.
+func getEDGARTickersURL(options ProviderOptions) string {
+	if options.EDGARTickersURL != "" || options.EDGARBaseURL == "" {
+		return options.EDGARTickersURL
+	}
+
+	return strings.TrimSuffix(options.EDGARBaseURL, "/") + edgar.TickersPath
+}
+
+// resolveDefaultSecret returns the secret name, or an empty value if it is
+// not set, so the provider is only usable by collections naming a secret.
+func resolveDefaultSecret(resolver *secrets.Resolver, name string) (secrets.Value, error) {
+	value, err := resolver.Resolve(name)
+	if errors.Is(err, secrets.ErrNotFound) {
+		logger.Warnf("Secret %s is not set, set %s or reference a secret in each collection",
+			name, secrets.Environment{Prefix: secrets.EnvironmentPrefix}.GetVariable(name))
+		return "", nil
+	}
+
+	return value, err
+}
+
+// openHTTPCache opens the cache of provider responses in stateDirectory, or
+// returns nil if size disables it.
+func openHTTPCache(stateDirectory string, size int64) (*httpUtil.Cache, error) {
+	if size < 0 {
+		return nil, nil
+	}
+
+	cache, err := httpUtil.OpenCache(
+		filepath.Join(stateDirectory, daemonConfig.HTTPCacheDirectoryName),
+		cmp.Or(size, daemonConfig.HTTPCacheSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HTTP cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// withBudget returns a copy of httpClient that caches responses for ttls and
+// waits on budget before each request it sends to the server.
+func withBudget(httpClient httpUtil.HTTPClient, ttls httpUtil.CacheTTLs, budget *rate.Limiter) httpUtil.HTTPClient {
+	httpClient.CacheTTLs = ttls
+	httpClient.Budget = budget
+
+	return httpClient
+}
+
+// newBudget returns an unlimited request budget, which register limits once
+// the provider spending it is known.
+func newBudget() *rate.Limiter {
+	return rate.NewLimiter(rate.Inf, 1)
+}
+
+// register adds p to registry and limits budget, which its HTTP client
+// spends, to the request limits of p. The budget is spent per request rather
+// than per job, so responses served from the HTTP cache do not count against
+// it.
+func register(registry *provider.Registry, p provider.Provider, budget *rate.Limiter) error {
+	const (
+		secondsPerMinute = 60
+	)
+
+	if limits := p.Limits(); limits.RequestsPerMinute > 0 {
+		budget.SetLimit(rate.Limit(limits.RequestsPerMinute / secondsPerMinute))
+		budget.SetBurst(max(limits.Burst, 1))
+	}
+
+	return registry.Register(p)
+}
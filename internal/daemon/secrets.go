@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	daemonConfig "github.com/zydee3/stockdb/internal/config"
+)
+
+// SecretOptions configures where the daemon reads secrets from. A secret is
+// read from the first of the environment, systemd's credentials, Directory
+// and the encrypted store that has it.
+type SecretOptions struct {
+	// Directory holds a file per secret, named after the secret.
+	Directory string
+	// KeyFile holds the key of the encrypted store in the state directory.
+	// It defaults to the systemd credential
+	// config.SecretStoreKeyCredential. The store is not read without a key.
+	KeyFile string
+}
+
+// newSecretResolver returns the resolver of every secret the daemon uses.
+func newSecretResolver(stateDirectory string, options SecretOptions) (*secrets.Resolver, error) {
+	sources := []secrets.Source{secrets.Environment{Prefix: secrets.EnvironmentPrefix}}
+
+	if credentials := os.Getenv(secrets.CredentialsDirectoryEnvironmentVariable); credentials != "" {
+		sources = append(sources, secrets.Directory{Path: credentials})
+	}
+
+	if options.Directory != "" {
+		sources = append(sources, secrets.Directory{Path: options.Directory})
+	}
+
+	keyFile := secretStoreKeyFile(options)
+	if keyFile != "" {
+		store, err := openSecretStore(stateDirectory, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, store)
+	}
+
+	return secrets.NewResolver(sources...), nil
+}
+
+// secretStoreKeyFile returns the configured key file, or the systemd
+// credential if it exists.
+func secretStoreKeyFile(options SecretOptions) string {
+	if options.KeyFile != "" {
+		return options.KeyFile
+	}
+
+	credentials := os.Getenv(secrets.CredentialsDirectoryEnvironmentVariable)
+	if credentials == "" {
+		return ""
+	}
+
+	keyFile := filepath.Join(credentials, daemonConfig.SecretStoreKeyCredential)
+	if _, err := os.Stat(keyFile); err != nil {
+		return ""
+	}
+
+	return keyFile
+}
+
+func openSecretStore(stateDirectory string, keyFile string) (*secrets.Store, error) {
+	key, err := secrets.ReadKeyFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret store key: %w", err)
+	}
+
+	return secrets.OpenStore(filepath.Join(stateDirectory, daemonConfig.SecretStoreFileName), key)
+}
+
+// secretsCommand manages the encrypted secret store. It works on the store
+// file directly, so secrets never pass through the daemon's socket. A running
+// daemon has read the store when it started, so it must be restarted to use
+// changed secrets.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var secretsCommand = cli.Command{
+	Name:        "secrets",
+	Description: `Manage the encrypted secret store in the state directory.`,
+	Commands: []*cli.Command{
+		{
+			Name:        "init",
+			Description: `Generate the key of the secret store at --secrets-key.`,
+			Action:      onSecretsInit,
+		},
+		{
+			Name:        "set",
+			ArgsUsage:   "<name>",
+			Description: `Store the secret read from standard input under a name.`,
+			Action:      onSecretsSet,
+		},
+		{
+			Name:        "delete",
+			ArgsUsage:   "<name>",
+			Description: `Delete a secret from the store.`,
+			Action:      onSecretsDelete,
+		},
+		{
+			Name:        "ls",
+			Description: `List the names of the stored secrets.`,
+			Action:      onSecretsList,
+		},
+	},
+}
+
+func secretOptionsFromCommand(cmd *cli.Command) SecretOptions {
+	return SecretOptions{
+		Directory: cmd.String("secrets-dir"),
+		KeyFile:   cmd.String("secrets-key"),
+	}
+}
+
+func openSecretStoreFromCommand(cmd *cli.Command) (*secrets.Store, error) {
+	keyFile := secretStoreKeyFile(secretOptionsFromCommand(cmd))
+	if keyFile == "" {
+		return nil, errors.New("the secret store needs a key, set --secrets-key")
+	}
+
+	return openSecretStore(cmd.String("state-dir"), keyFile)
+}
+
+func onSecretsInit(_ context.Context, cmd *cli.Command) error {
+	keyFile := cmd.String("secrets-key")
+	if keyFile == "" {
+		return cli.Exit("set --secrets-key to the file to write the key to", 1)
+	}
+
+	if err := secrets.GenerateKeyFile(keyFile); err != nil {
+		return cli.Exit(fmt.Errorf("failed to generate key: %w", err), 1)
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "Wrote the secret store key to %s\n", keyFile)
+
+	return nil
+}
+
+func onSecretsSet(_ context.Context, cmd *cli.Command) error {
+	name := cmd.Args().First()
+	if err := secrets.ValidateName(name); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	store, err := openSecretStoreFromCommand(cmd)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	// Secrets are read from standard input so they stay out of the shell
+	// history and the process list.
+	value, err := io.ReadAll(cmd.Root().Reader)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("failed to read secret: %w", err), 1)
+	}
+
+	if setError := store.Set(name, secrets.Value(strings.TrimRight(string(value), "\r\n"))); setError != nil {
+		return cli.Exit(setError, 1)
+	}
+
+	if saveError := store.Save(); saveError != nil {
+		return cli.Exit(fmt.Errorf("failed to save secret store: %w", saveError), 1)
+	}
+
+	return nil
+}
+
+func onSecretsDelete(_ context.Context, cmd *cli.Command) error {
+	store, err := openSecretStoreFromCommand(cmd)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if deleteError := store.Delete(cmd.Args().First()); deleteError != nil {
+		return cli.Exit(deleteError, 1)
+	}
+
+	if saveError := store.Save(); saveError != nil {
+		return cli.Exit(fmt.Errorf("failed to save secret store: %w", saveError), 1)
+	}
+
+	return nil
+}
+
+func onSecretsList(_ context.Context, cmd *cli.Command) error {
+	store, err := openSecretStoreFromCommand(cmd)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	for _, name := range store.Names() {
+		fmt.Fprintln(cmd.Root().Writer, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package factory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// barAggregator builds bars from the trades of a stream. Each symbol has one
+// open bar, which is completed when a trade of a later period arrives or the
+// period ends.
+type barAggregator struct {
+	resolution records.Resolution
+	source     string
+
+	mu   sync.Mutex
+	open map[string]*records.Bar
+	// closed is the end of the last completed bar of each symbol. Later
+	// trades for a completed period are dropped rather than starting a
+	// partial bar that would replace it.
+	closed map[string]time.Time
+}
+
+func newBarAggregator(resolution records.Resolution, source string) *barAggregator {
+	return &barAggregator{
+		resolution: resolution,
+		source:     source,
+		open:       map[string]*records.Bar{},
+		closed:     map[string]time.Time{},
+	}
+}
+
+// add adds the trades among ticks to their bars and returns the bars they
+// completed. Trades of periods that were already completed are dropped.
+func (a *barAggregator) add(ticks []records.Tick) []records.Bar {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	completed := []records.Bar{}
+
+	for _, tick := range ticks {
+		if tick.Kind != records.TickTrade {
+			continue
+		}
+
+		start := tick.Time.UTC().Truncate(a.resolution.GetDuration())
+
+		bar, exists := a.open[tick.Symbol]
+		if (exists && start.Before(bar.Time)) || start.Before(a.closed[tick.Symbol]) {
+			continue
+		}
+
+		if exists && start.After(bar.Time) {
+			completed = append(completed, a.complete(bar))
+			exists = false
+		}
+
+		if !exists {
+			a.open[tick.Symbol] = &records.Bar{
+				Symbol:     tick.Symbol,
+				Resolution: a.resolution,
+				Time:       start,
+				Open:       tick.Price,
+				High:       tick.Price,
+				Low:        tick.Price,
+				Close:      tick.Price,
+				Volume:     tick.Size,
+				Source:     a.source,
+			}
+			continue
+		}
+
+		bar.High = max(bar.High, tick.Price)
+		bar.Low = min(bar.Low, tick.Price)
+		bar.Close = tick.Price
+		bar.Volume += tick.Size
+	}
+
+	return completed
+}
+
+// flush returns the open bars whose period ended by now, or every open bar if
+// now is zero.
+func (a *barAggregator) flush(now time.Time) []records.Bar {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	completed := []records.Bar{}
+
+	for symbol, bar := range a.open {
+		if now.IsZero() || !bar.Time.Add(a.resolution.GetDuration()).After(now) {
+			completed = append(completed, a.complete(bar))
+			delete(a.open, symbol)
+		}
+	}
+
+	return completed
+}
+
+func (a *barAggregator) complete(bar *records.Bar) records.Bar {
+	a.closed[bar.Symbol] = bar.Time.Add(a.resolution.GetDuration())
+	return *bar
+}
@@ -0,0 +1,250 @@
+package breaker
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+)
+
+// State is whether a circuit lets requests through.
+type State string
+
+const (
+	// StateClosed circuits let every request through.
+	StateClosed State = "closed"
+	// StateOpen circuits reject requests until their open duration passes.
+	StateOpen State = "open"
+	// StateHalfOpen circuits let a single probe through, which closes the
+	// circuit if it succeeds and opens it again if it fails.
+	StateHalfOpen State = "half-open"
+)
+
+// Result is how a request counts toward the circuit of its endpoint.
+type Result int
+
+const (
+	// ResultSuccess is a request the provider answered, even if the answer
+	// was an error of the request such as an unknown symbol.
+	ResultSuccess Result = iota
+	// ResultFailure is a request that failed because the provider is
+	// unavailable.
+	ResultFailure
+	// ResultIgnored is a request that did not finish, such as one cancelled
+	// on shutdown. It says nothing about the provider.
+	ResultIgnored
+)
+
+// Options configures the circuits of a Set.
+type Options struct {
+	// WindowSize is how many of the latest results the failure rate is
+	// computed over.
+	WindowSize int
+	// MinimumRequests is how many results the window needs before the
+	// circuit can open.
+	MinimumRequests int
+	// FailureRate opens the circuit once the share of failures in the window
+	// reaches it.
+	FailureRate float64
+	// OpenDuration is how long a circuit stays open before a probe is let
+	// through. It doubles each time a probe fails, up to MaxOpenDuration.
+	OpenDuration    time.Duration
+	MaxOpenDuration time.Duration
+}
+
+// Key identifies a circuit: the endpoint of a source type.
+type Key struct {
+	Source   string `json:"source"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Status describes a circuit.
+type Status struct {
+	Key
+	State State `json:"state"`
+	// Failures and Requests are the results in the window.
+	Failures int `json:"failures"`
+	Requests int `json:"requests"`
+	// OpenedAt is when the circuit last opened, and RetryAt when an open
+	// circuit lets a probe through.
+	OpenedAt time.Time `json:"openedAt"`
+	RetryAt  time.Time `json:"retryAt"`
+}
+
+// Set keeps a circuit breaker per provider endpoint, so an outage of one
+// provider does not hold back jobs of another. A nil Set, and the zero Key,
+// let every request through.
+type Set struct {
+	options Options
+	clock   clock.Clock
+
+	mu       sync.Mutex
+	circuits map[Key]*circuit
+}
+
+type circuit struct {
+	state State
+	// results is a ring of the latest results, true for failures.
+	results      []bool
+	next         int
+	openDuration time.Duration
+	openedAt     time.Time
+	retryAt      time.Time
+	probing      bool
+}
+
+func NewSet(options Options, clk clock.Clock) *Set {
+	return &Set{
+		options:  options,
+		clock:    clk,
+		circuits: map[Key]*circuit{},
+	}
+}
+
+// Allow reports whether a request to key may be sent now. If not, it returns
+// when to try again.
+func (s *Set) Allow(key Key) (bool, time.Time) {
+	if s == nil || key == (Key{}) {
+		return true, time.Time{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCircuit(key)
+	now := s.clock.Now()
+
+	if c.state == StateOpen && !now.Before(c.retryAt) {
+		c.state = StateHalfOpen
+	}
+
+	switch c.state {
+	case StateOpen:
+		return false, c.retryAt
+	case StateHalfOpen:
+		// Requests wait for the probe to return.
+		if c.probing {
+			return false, now.Add(s.options.OpenDuration)
+		}
+
+		c.probing = true
+		return true, time.Time{}
+	default:
+		return true, time.Time{}
+	}
+}
+
+// Record counts the result of a request Allow let through.
+func (s *Set) Record(key Key, result Result) {
+	if s == nil || key == (Key{}) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCircuit(key)
+	now := s.clock.Now()
+
+	if c.state == StateHalfOpen {
+		switch result {
+		case ResultSuccess:
+			c.close()
+		case ResultFailure:
+			c.open(now, min(c.openDuration*2, max(s.options.MaxOpenDuration, s.options.OpenDuration)))
+		case ResultIgnored:
+			c.probing = false
+		}
+
+		return
+	}
+
+	if c.state == StateOpen || result == ResultIgnored {
+		return
+	}
+
+	c.results[c.next%len(c.results)] = result == ResultFailure
+	c.next++
+
+	requests, failures := c.count()
+	if requests >= s.options.MinimumRequests && float64(failures) >= s.options.FailureRate*float64(requests) {
+		c.open(now, s.options.OpenDuration)
+	}
+}
+
+// Statuses returns the circuits of the endpoints that were requested,
+// ordered by source and endpoint.
+func (s *Set) Statuses() []Status {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	statuses := make([]Status, 0, len(s.circuits))
+	for key, c := range s.circuits {
+		status := Status{Key: key, State: c.state, OpenedAt: c.openedAt}
+		status.Requests, status.Failures = c.count()
+
+		if c.state == StateOpen {
+			status.RetryAt = c.retryAt
+			if !now.Before(c.retryAt) {
+				status.State = StateHalfOpen
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Endpoint, b.Endpoint))
+	})
+
+	return statuses
+}
+
+func (s *Set) getCircuit(key Key) *circuit {
+	c, ok := s.circuits[key]
+	if !ok {
+		c = &circuit{state: StateClosed, results: make([]bool, max(s.options.WindowSize, 1))}
+		s.circuits[key] = c
+	}
+
+	return c
+}
+
+func (c *circuit) open(now time.Time, duration time.Duration) {
+	c.state = StateOpen
+	c.openDuration = duration
+	c.openedAt = now
+	c.retryAt = now.Add(duration)
+	c.probing = false
+}
+
+func (c *circuit) close() {
+	c.state = StateClosed
+	c.openDuration = 0
+	c.retryAt = time.Time{}
+	c.probing = false
+	c.next = 0
+	clear(c.results)
+}
+
+// count returns the number of results in the window and how many failed.
+func (c *circuit) count() (int, int) {
+	requests := min(c.next, len(c.results))
+
+	failures := 0
+	for _, failed := range c.results[:requests] {
+		if failed {
+			failures++
+		}
+	}
+
+	return requests, failures
+}
@@ -0,0 +1,274 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/utility"
+)
+
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeCancelled Outcome = "cancelled"
+)
+
+// Entry records a single finished job.
+type Entry struct {
+	JobID        string        `json:"jobId"`
+	Collection   string        `json:"collection"`
+	Source       string        `json:"source,omitempty"`
+	Endpoint     string        `json:"endpoint,omitempty"`
+	Symbol       string        `json:"symbol,omitempty"`
+	Series       string        `json:"series,omitempty"`
+	WindowStart  time.Time     `json:"windowStart"`
+	WindowEnd    time.Time     `json:"windowEnd"`
+	Attempts     int           `json:"attempts"`
+	FinishedAt   time.Time     `json:"finishedAt"`
+	Duration     time.Duration `json:"duration"`
+	RowsWritten  int           `json:"rowsWritten"`
+	BytesFetched int64         `json:"bytesFetched"`
+	Outcome      Outcome       `json:"outcome"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Query selects history entries. Zero values match every entry.
+type Query struct {
+	Collection string
+	// Symbol matches the security symbol or the series ID of an entry.
+	Symbol     string
+	Since      time.Time
+	FailedOnly bool
+	Limit      int
+}
+
+// Store is an append-only job history kept as JSON lines in a single file.
+// Entries older than the retention period are dropped when the file is
+// compacted.
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	retention time.Duration
+	clock     clock.Clock
+	entries   []Entry
+}
+
+func (q Query) Matches(entry Entry) bool {
+	if q.Collection != "" && entry.Collection != q.Collection {
+		return false
+	}
+
+	if q.Symbol != "" && entry.Symbol != q.Symbol && entry.Series != q.Symbol {
+		return false
+	}
+
+	if !q.Since.IsZero() && entry.FinishedAt.Before(q.Since) {
+		return false
+	}
+
+	if q.FailedOnly && entry.Outcome == OutcomeSucceeded {
+		return false
+	}
+
+	return true
+}
+
+// Open loads the history at path, creating it if needed. A retention of zero
+// keeps entries forever.
+func Open(path string, retention time.Duration, clk clock.Clock) (*Store, error) {
+	const (
+		historyDirPerm = 0755
+	)
+
+	if err := utility.CreateParentDir(path, historyDirPerm); err != nil {
+		return nil, err
+	}
+
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		path:      path,
+		retention: retention,
+		clock:     clk,
+		entries:   entries,
+	}
+
+	if compactError := store.Compact(); compactError != nil {
+		return nil, compactError
+	}
+
+	return store, nil
+}
+
+func (s *Store) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("history store is closed")
+	}
+
+	if _, writeError := s.file.Write(append(line, '\n')); writeError != nil {
+		return fmt.Errorf("failed to append to history: %w", writeError)
+	}
+
+	s.entries = append(s.entries, entry)
+
+	return nil
+}
+
+// Query returns the matching entries, most recently finished first.
+func (s *Store) Query(query Query) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.cutoff()
+
+	matched := []Entry{}
+	for _, entry := range slices.Backward(s.entries) {
+		if query.Limit > 0 && len(matched) >= query.Limit {
+			break
+		}
+
+		if entry.FinishedAt.Before(cutoff) {
+			continue
+		}
+
+		if query.Matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	return matched
+}
+
+// Compact rewrites the history file without the entries that are past the
+// retention period.
+func (s *Store) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.cutoff()
+
+	kept := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if !entry.FinishedAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+
+	if s.file != nil && len(kept) == len(s.entries) {
+		return nil
+	}
+
+	if err := s.rewrite(kept); err != nil {
+		return err
+	}
+
+	if expired := len(s.entries) - len(kept); expired > 0 {
+		logger.Infof("Removed %d expired entries from job history", expired)
+	}
+
+	s.entries = kept
+
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+func (s *Store) cutoff() time.Time {
+	if s.retention <= 0 {
+		return time.Time{}
+	}
+
+	return s.clock.Now().Add(-s.retention)
+}
+
+// rewrite atomically replaces the history file with entries and reopens it
+// for appending.
+func (s *Store) rewrite(entries []Entry) error {
+	const (
+		historyFilePerm = 0644
+	)
+
+	data := bytes.Buffer{}
+	encoder := json.NewEncoder(&data)
+	for _, entry := range entries {
+		if encodeError := encoder.Encode(entry); encodeError != nil {
+			return encodeError
+		}
+	}
+
+	if writeError := utility.WriteFileAtomic(s.path, data.Bytes(), historyFilePerm); writeError != nil {
+		return writeError
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, historyFilePerm)
+	s.file = file
+
+	return err
+}
+
+func readEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	entries := []Entry{}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, bufio.MaxScanTokenSize*16)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		entry := Entry{}
+		if unmarshalError := json.Unmarshal(scanner.Bytes(), &entry); unmarshalError != nil {
+			// A torn final line from a crash should not make the history
+			// unreadable.
+			logger.Warnf("Skipping unreadable job history line %d: %v", lineNumber, unmarshalError)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,228 @@
+package jobqueue
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/logger"
+)
+
+type delayedItem struct {
+	job      jobs.Job
+	sequence uint64
+}
+
+// delayedHeap orders jobs by NotBefore, then by insertion order.
+type delayedHeap []*delayedItem
+
+func (h delayedHeap) Len() int {
+	return len(h)
+}
+
+func (h delayedHeap) Less(i, j int) bool {
+	if !h[i].job.NotBefore.Equal(h[j].job.NotBefore) {
+		return h[i].job.NotBefore.Before(h[j].job.NotBefore)
+	}
+
+	return h[i].sequence < h[j].sequence
+}
+
+func (h delayedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *delayedHeap) Push(x any) {
+	item, _ := x.(*delayedItem)
+	*h = append(*h, item)
+}
+
+func (h *delayedHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// delayedJobQueue holds jobs until their NotBefore time and then adds them to
+// the target queue. Jobs that are already due are added to the target
+// immediately.
+type delayedJobQueue struct {
+	target   InputJobQueue
+	clock    clock.Clock
+	mu       sync.Mutex
+	items    delayedHeap
+	sequence uint64
+
+	// changed is closed and replaced whenever a job is added or drained.
+	changed chan struct{}
+}
+
+func (d *delayedJobQueue) Add(ctx context.Context, jobDefinition jobs.Job) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if !jobDefinition.NotBefore.After(d.clock.Now()) {
+		return d.target.Add(ctx, jobDefinition)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sequence++
+	heap.Push(&d.items, &delayedItem{job: jobDefinition, sequence: d.sequence})
+	d.notifyLocked()
+
+	return nil
+}
+
+func (d *delayedJobQueue) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.items.Len()
+}
+
+// Peek returns the delayed jobs matching filter in the order they become due.
+func (d *delayedJobQueue) Peek(filter Filter) []jobs.Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	matched := []jobs.Job{}
+	for _, item := range d.sortedLocked() {
+		if filter.Limit > 0 && len(matched) >= filter.Limit {
+			break
+		}
+
+		if filter.Matches(item.job) {
+			matched = append(matched, item.job)
+		}
+	}
+
+	return matched
+}
+
+func (d *delayedJobQueue) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := Stats{
+		ByPriority:   make(map[int]int),
+		ByCollection: make(map[string]int),
+	}
+
+	for _, item := range d.items {
+		stats.Total++
+		stats.ByPriority[item.job.GetPriority()]++
+		stats.ByCollection[item.job.GetCollection()]++
+	}
+
+	return stats
+}
+
+func (d *delayedJobQueue) Drain(filter Filter) []jobs.Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	drained := []jobs.Job{}
+	kept := delayedHeap{}
+	for _, item := range d.sortedLocked() {
+		if filter.Matches(item.job) && (filter.Limit <= 0 || len(drained) < filter.Limit) {
+			drained = append(drained, item.job)
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	d.items = kept
+	heap.Init(&d.items)
+	d.notifyLocked()
+
+	return drained
+}
+
+func (d *delayedJobQueue) run(ctx context.Context) {
+	for {
+		for _, job := range d.popDue() {
+			if err := d.target.Add(ctx, job); err != nil {
+				logger.Debugf("Failed to release delayed job %s: %v", job.ID, err)
+				return
+			}
+		}
+
+		d.mu.Lock()
+		changed := d.changed
+		var timer clock.Timer
+		var timerChannel <-chan time.Time
+		if d.items.Len() > 0 {
+			timer = d.clock.NewTimer(d.items[0].job.NotBefore.Sub(d.clock.Now()))
+			timerChannel = timer.C()
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-timerChannel:
+		case <-changed:
+		case <-ctx.Done():
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (d *delayedJobQueue) popDue() []jobs.Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+
+	due := []jobs.Job{}
+	for d.items.Len() > 0 && !d.items[0].job.NotBefore.After(now) {
+		item, _ := heap.Pop(&d.items).(*delayedItem)
+		due = append(due, item.job)
+	}
+
+	return due
+}
+
+func (d *delayedJobQueue) notifyLocked() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+func (d *delayedJobQueue) sortedLocked() delayedHeap {
+	sorted := make(delayedHeap, len(d.items))
+	copy(sorted, d.items)
+	sort.Sort(sorted)
+
+	return sorted
+}
+
+// NewDelayedJobQueue creates a queue that releases each job into targetJQ once
+// the clock reaches the job's NotBefore time. Jobs still waiting when ctx is
+// done are not released.
+func NewDelayedJobQueue(ctx context.Context, targetJQ InputJobQueue, clk clock.Clock) InspectableInputJobQueue {
+	queue := &delayedJobQueue{
+		target:  targetJQ,
+		clock:   clk,
+		items:   delayedHeap{},
+		changed: make(chan struct{}),
+	}
+
+	go queue.run(ctx)
+
+	return queue
+}
@@ -0,0 +1,81 @@
+package jobqueue
+
+import (
+	"github.com/zydee3/stockdb/internal/common/jobs"
+)
+
+// inspectableGroup presents several queues, such as a ready queue and the
+// delayed queue feeding it, as one.
+type inspectableGroup struct {
+	queues []InspectableJobQueue
+}
+
+func (g *inspectableGroup) Len() int {
+	total := 0
+	for _, queue := range g.queues {
+		total += queue.Len()
+	}
+
+	return total
+}
+
+// Peek returns the matching jobs of each queue in turn.
+func (g *inspectableGroup) Peek(filter Filter) []jobs.Job {
+	matched := []jobs.Job{}
+	for _, queue := range g.queues {
+		queueFilter := filter
+		if filter.Limit > 0 {
+			queueFilter.Limit = filter.Limit - len(matched)
+			if queueFilter.Limit <= 0 {
+				break
+			}
+		}
+
+		matched = append(matched, queue.Peek(queueFilter)...)
+	}
+
+	return matched
+}
+
+func (g *inspectableGroup) Stats() Stats {
+	stats := Stats{
+		ByPriority:   make(map[int]int),
+		ByCollection: make(map[string]int),
+	}
+
+	for _, queue := range g.queues {
+		queueStats := queue.Stats()
+		stats.Total += queueStats.Total
+		for priority, count := range queueStats.ByPriority {
+			stats.ByPriority[priority] += count
+		}
+		for collection, count := range queueStats.ByCollection {
+			stats.ByCollection[collection] += count
+		}
+	}
+
+	return stats
+}
+
+func (g *inspectableGroup) Drain(filter Filter) []jobs.Job {
+	drained := []jobs.Job{}
+	for _, queue := range g.queues {
+		queueFilter := filter
+		if filter.Limit > 0 {
+			queueFilter.Limit = filter.Limit - len(drained)
+			if queueFilter.Limit <= 0 {
+				break
+			}
+		}
+
+		drained = append(drained, queue.Drain(queueFilter)...)
+	}
+
+	return drained
+}
+
+func NewInspectableGroup(queues ...InspectableJobQueue) InspectableJobQueue {
+	return &inspectableGroup{
+		queues: queues,
+	}
+}
@@ -18,3 +18,47 @@ type InputJobQueue interface {
 type OutputJobQueue interface {
 	GetOutputChannel() (<-chan jobs.Job, error)
 }
+
+// InspectableJobQueue exposes the jobs that are pending in a queue, i.e. jobs
+// that have been added but not yet received by a worker.
+type InspectableJobQueue interface {
+	Len() int
+	Peek(filter Filter) []jobs.Job
+	Stats() Stats
+	Drain(filter Filter) []jobs.Job
+}
+
+type InspectableInputJobQueue interface {
+	InputJobQueue
+	InspectableJobQueue
+}
+
+type InspectableFullJobQueue interface {
+	FullJobQueue
+	InspectableJobQueue
+}
+
+// Filter selects pending jobs. Zero values match every job.
+type Filter struct {
+	Collection string
+	Priority   *int
+	Limit      int
+}
+
+type Stats struct {
+	Total        int            `json:"total"`
+	ByPriority   map[int]int    `json:"byPriority"`
+	ByCollection map[string]int `json:"byCollection"`
+}
+
+func (f Filter) Matches(job jobs.Job) bool {
+	if f.Collection != "" && job.GetCollection() != f.Collection {
+		return false
+	}
+
+	if f.Priority != nil && job.GetPriority() != *f.Priority {
+		return false
+	}
+
+	return true
+}
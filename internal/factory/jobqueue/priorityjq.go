@@ -0,0 +1,281 @@
+package jobqueue
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/logger"
+)
+
+type priorityItem struct {
+	job      jobs.Job
+	sequence uint64
+}
+
+// priorityHeap orders jobs by descending priority, then by insertion order.
+type priorityHeap []*priorityItem
+
+func (h priorityHeap) Len() int {
+	return len(h)
+}
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].job.GetPriority() != h[j].job.GetPriority() {
+		return h[i].job.GetPriority() > h[j].job.GetPriority()
+	}
+
+	return h[i].sequence < h[j].sequence
+}
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *priorityHeap) Push(x any) {
+	item, _ := x.(*priorityItem)
+	*h = append(*h, item)
+}
+
+func (h *priorityHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// priorityJobQueue keeps pending jobs in a heap so they can be inspected and
+// removed, and hands them to workers through a dispatcher goroutine.
+//
+// The dispatcher pops the most urgent job into the in-flight slot and offers it
+// on the output channel. Whenever the queue changes while the offer is pending,
+// the job is pushed back so a more urgent job can overtake it, or a drain can
+// remove it. A job is only ever removed from the queue by exactly one of a
+// worker receive or a drain.
+type priorityJobQueue struct {
+	mu       sync.Mutex
+	items    priorityHeap
+	capacity int
+	sequence uint64
+
+	// changed is closed and replaced whenever the queue changes.
+	changed chan struct{}
+
+	// inflight is the job currently being offered to workers, and settled is
+	// closed once that offer has either been delivered or withdrawn.
+	inflight *priorityItem
+	settled  chan struct{}
+
+	// paused stops the dispatcher from taking new jobs while a drain runs.
+	paused int
+
+	outputChannel chan jobs.Job
+}
+
+func (p *priorityJobQueue) Add(ctx context.Context, jobDefinition jobs.Job) error {
+	for {
+		if ctx.Err() != nil {
+			logger.Debugf("Failed to add job definition to priority job queue: %v", ctx.Err())
+			return ctx.Err()
+		}
+
+		p.mu.Lock()
+		if p.lenLocked() < p.capacity {
+			p.sequence++
+			heap.Push(&p.items, &priorityItem{job: jobDefinition, sequence: p.sequence})
+			p.notifyLocked()
+			p.mu.Unlock()
+			return nil
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (p *priorityJobQueue) GetOutputChannel() (<-chan jobs.Job, error) {
+	return p.outputChannel, nil
+}
+
+func (p *priorityJobQueue) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lenLocked()
+}
+
+// Peek returns the pending jobs matching filter in the order they will be
+// handed to workers.
+func (p *priorityJobQueue) Peek(filter Filter) []jobs.Job {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	matched := []jobs.Job{}
+	for _, item := range p.sortedLocked() {
+		if filter.Limit > 0 && len(matched) >= filter.Limit {
+			break
+		}
+
+		if filter.Matches(item.job) {
+			matched = append(matched, item.job)
+		}
+	}
+
+	return matched
+}
+
+func (p *priorityJobQueue) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := Stats{
+		ByPriority:   make(map[int]int),
+		ByCollection: make(map[string]int),
+	}
+
+	for _, item := range p.allLocked() {
+		stats.Total++
+		stats.ByPriority[item.job.GetPriority()]++
+		stats.ByCollection[item.job.GetCollection()]++
+	}
+
+	return stats
+}
+
+// Drain removes and returns the pending jobs matching filter. Jobs that a
+// worker has already received are never returned.
+func (p *priorityJobQueue) Drain(filter Filter) []jobs.Job {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Stop the dispatcher from taking new jobs and wait for the current offer
+	// to settle, so the in-flight job is either delivered or back in the heap.
+	p.paused++
+	for p.inflight != nil {
+		settled := p.settled
+		p.notifyLocked()
+		p.mu.Unlock()
+		<-settled
+		p.mu.Lock()
+	}
+
+	drained := []jobs.Job{}
+	kept := priorityHeap{}
+	for _, item := range p.sortedLocked() {
+		if filter.Matches(item.job) && (filter.Limit <= 0 || len(drained) < filter.Limit) {
+			drained = append(drained, item.job)
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	p.items = kept
+	heap.Init(&p.items)
+
+	p.paused--
+	p.notifyLocked()
+
+	return drained
+}
+
+func (p *priorityJobQueue) dispatch(ctx context.Context) {
+	defer close(p.outputChannel)
+
+	for {
+		p.mu.Lock()
+		if p.paused > 0 || p.items.Len() == 0 {
+			changed := p.changed
+			p.mu.Unlock()
+
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		item, _ := heap.Pop(&p.items).(*priorityItem)
+		p.inflight = item
+		p.settled = make(chan struct{})
+		changed := p.changed
+		p.mu.Unlock()
+
+		delivered := false
+		select {
+		case p.outputChannel <- item.job:
+			delivered = true
+		case <-changed:
+		case <-ctx.Done():
+		}
+
+		p.mu.Lock()
+		if !delivered {
+			heap.Push(&p.items, item)
+		}
+		p.inflight = nil
+		close(p.settled)
+		p.notifyLocked()
+		p.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (p *priorityJobQueue) notifyLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+func (p *priorityJobQueue) lenLocked() int {
+	if p.inflight != nil {
+		return p.items.Len() + 1
+	}
+
+	return p.items.Len()
+}
+
+func (p *priorityJobQueue) allLocked() []*priorityItem {
+	items := make([]*priorityItem, 0, p.lenLocked())
+	items = append(items, p.items...)
+	if p.inflight != nil {
+		items = append(items, p.inflight)
+	}
+
+	return items
+}
+
+func (p *priorityJobQueue) sortedLocked() []*priorityItem {
+	items := p.allLocked()
+	sorted := priorityHeap(items)
+	sort.Sort(sorted)
+
+	return sorted
+}
+
+// NewPriorityJobQueue creates a queue that hands out the most urgent job
+// first, holding at most size pending jobs. Jobs stop being handed out and the
+// output channel is closed when ctx is done.
+func NewPriorityJobQueue(ctx context.Context, size uint) InspectableFullJobQueue {
+	queue := &priorityJobQueue{
+		items:         priorityHeap{},
+		capacity:      int(size), //nolint:gosec // Queue sizes are small configuration values.
+		changed:       make(chan struct{}),
+		outputChannel: make(chan jobs.Job),
+	}
+
+	go queue.dispatch(ctx)
+
+	return queue
+}
@@ -0,0 +1,304 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/logger"
+)
+
+const (
+	// maxHeldJobs bounds the jobs taken off the wrapped queue that wait on
+	// their limiters, so the rest stay inspectable and drainable there.
+	maxHeldJobs = 64
+
+	// requeueTimeout bounds how long held jobs are offered back to the
+	// wrapped queue on shutdown.
+	requeueTimeout = 5 * time.Second
+)
+
+// ErrInvalidLimiter is returned for a limiter that can never allow a job, such
+// as one with a burst of 0.
+var ErrInvalidLimiter = errors.New("rate limiter never allows a job")
+
+// KeyFunc groups jobs for per-key rate limiting, for example by source type.
+type KeyFunc func(job jobs.Job) string
+
+// KeyLimiterFunc creates the limiter used for a key the first time it is seen.
+type KeyLimiterFunc func(key string) *rate.Limiter
+
+type rateLimitedOutputJQ struct {
+	ctx            context.Context
+	outputJobQueue OutputJobQueue
+	rateLimiter    *rate.Limiter
+	keyFunc        KeyFunc
+	newKeyLimiter  KeyLimiterFunc
+	startOnce      sync.Once
+	outputChannel  chan jobs.Job
+	startError     error
+
+	// slots holds a token for every held job.
+	slots chan struct{}
+
+	mu    sync.Mutex
+	lanes map[string]*keyLane
+	// laneGroup tracks the goroutines releasing the jobs of lanes.
+	laneGroup sync.WaitGroup
+}
+
+// keyLane holds the jobs of a key in the order the wrapped queue released
+// them. Its goroutine runs while it holds jobs.
+type keyLane struct {
+	limiter *rate.Limiter
+	jobs    []jobs.Job
+	running bool
+}
+
+func (r *rateLimitedOutputJQ) GetOutputChannel() (<-chan jobs.Job, error) {
+	r.startOnce.Do(func() {
+		inputChannel, err := r.outputJobQueue.GetOutputChannel()
+		if err != nil {
+			r.startError = err
+			return
+		}
+
+		go r.forward(inputChannel)
+	})
+
+	if r.startError != nil {
+		return nil, r.startError
+	}
+
+	return r.outputChannel, nil
+}
+
+// forward takes jobs off the wrapped queue and hands each to the lane of its
+// key, which releases it once both the job's key limiter and the shared
+// limiter allow it. Jobs of a key are released in order, and a throttled key
+// does not hold back the jobs of other keys. Jobs still held when ctx is done
+// are offered back to the wrapped queue.
+func (r *rateLimitedOutputJQ) forward(inputChannel <-chan jobs.Job) {
+	defer func() {
+		r.laneGroup.Wait()
+		r.requeue()
+		close(r.outputChannel)
+	}()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case r.slots <- struct{}{}:
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case job, ok := <-inputChannel:
+			if !ok {
+				return
+			}
+
+			r.hold(job)
+		}
+	}
+}
+
+func (r *rateLimitedOutputJQ) hold(job jobs.Job) {
+	key := ""
+	if r.keyFunc != nil {
+		key = r.keyFunc(job)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lane, exists := r.lanes[key]
+	if !exists {
+		r.removeIdleLanes()
+
+		lane = &keyLane{limiter: r.getKeyLimiter(key)}
+		r.lanes[key] = lane
+	}
+
+	lane.jobs = append(lane.jobs, job)
+	if !lane.running {
+		lane.running = true
+		r.laneGroup.Add(1)
+		go r.release(lane)
+	}
+}
+
+// release sends the jobs of lane to the output channel as their limiters
+// allow, until it holds no more jobs or ctx is done.
+func (r *rateLimitedOutputJQ) release(lane *keyLane) {
+	defer r.laneGroup.Done()
+
+	for {
+		r.mu.Lock()
+		if len(lane.jobs) == 0 {
+			lane.running = false
+			r.mu.Unlock()
+			return
+		}
+
+		job := lane.jobs[0]
+		r.mu.Unlock()
+
+		if err := r.wait(lane.limiter); err != nil {
+			// Limiters are validated, so Wait only fails once ctx is done or
+			// its deadline would pass first.
+			logger.Debugf("Holding job in rate limited job queue until shutdown: %v", err)
+			<-r.ctx.Done()
+			return
+		}
+
+		select {
+		case r.outputChannel <- job:
+		case <-r.ctx.Done():
+			return
+		}
+
+		r.mu.Lock()
+		lane.jobs = lane.jobs[1:]
+		r.mu.Unlock()
+
+		<-r.slots
+	}
+}
+
+// wait waits on the key limiter before the shared one, so a throttled key
+// does not spend shared tokens it cannot use yet.
+func (r *rateLimitedOutputJQ) wait(keyLimiter *rate.Limiter) error {
+	if keyLimiter != nil {
+		if err := keyLimiter.Wait(r.ctx); err != nil {
+			return err
+		}
+	}
+
+	if r.rateLimiter != nil {
+		return r.rateLimiter.Wait(r.ctx)
+	}
+
+	return nil
+}
+
+func (r *rateLimitedOutputJQ) getKeyLimiter(key string) *rate.Limiter {
+	if r.keyFunc == nil || r.newKeyLimiter == nil {
+		return nil
+	}
+
+	limiter := r.newKeyLimiter(key)
+	if err := validateLimiter(limiter); err != nil {
+		logger.Errorf("Not rate limiting jobs of %q: %v", key, err)
+		return nil
+	}
+
+	return limiter
+}
+
+// removeIdleLanes forgets lanes without jobs whose limiters have refilled,
+// which behave like new lanes, so lanes do not grow with every key seen.
+func (r *rateLimitedOutputJQ) removeIdleLanes() {
+	now := time.Now()
+
+	for key, lane := range r.lanes {
+		if lane.running || len(lane.jobs) > 0 {
+			continue
+		}
+
+		if lane.limiter == nil || lane.limiter.TokensAt(now) >= float64(lane.limiter.Burst()) {
+			delete(r.lanes, key)
+		}
+	}
+}
+
+// requeue offers the jobs still held back to the wrapped queue, if it accepts
+// jobs, once every lane has stopped.
+func (r *rateLimitedOutputJQ) requeue() {
+	inputJobQueue, accepts := r.outputJobQueue.(InputJobQueue)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	for key, lane := range r.lanes {
+		for _, job := range lane.jobs {
+			if !accepts {
+				logger.Warnf("Dropping job %s of %q held by rate limited job queue", job.ID, key)
+				continue
+			}
+
+			if err := inputJobQueue.Add(ctx, job); err != nil {
+				logger.Errorf("Failed to return job %s to its queue: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+func validateLimiter(limiter *rate.Limiter) error {
+	if limiter == nil || limiter.Limit() == rate.Inf {
+		return nil
+	}
+
+	if limiter.Limit() <= 0 || limiter.Burst() < 1 {
+		return fmt.Errorf("%w: limit %v, burst %d", ErrInvalidLimiter, limiter.Limit(), limiter.Burst())
+	}
+
+	return nil
+}
+
+// SourceTypeKey keys jobs by the source type of their CRD, e.g. "FMP".
+func SourceTypeKey(job jobs.Job) string {
+	if job.CRD == nil {
+		return ""
+	}
+
+	return job.CRD.GetSource().Type
+}
+
+// NewRateLimitedOutputJobQueue paces how fast jobs are released from targetJQ
+// to workers. Forwarding stops when ctx is done, closing the output channel.
+// It returns ErrInvalidLimiter if limiter can never allow a job.
+func NewRateLimitedOutputJobQueue(
+	ctx context.Context,
+	targetJQ OutputJobQueue,
+	limiter *rate.Limiter,
+) (OutputJobQueue, error) {
+	return NewKeyedRateLimitedOutputJobQueue(ctx, targetJQ, limiter, nil, nil)
+}
+
+// NewKeyedRateLimitedOutputJobQueue is NewRateLimitedOutputJobQueue with an
+// additional limiter per key. Either limiter may be omitted by passing nil,
+// and key limiters that can never allow a job are logged and ignored.
+//
+// A key limiter gives each key its own lane, e.g. a limiter per source type
+// from SourceTypeKey, so jobs of a provider that is out of budget wait
+// without holding back the jobs of other providers.
+func NewKeyedRateLimitedOutputJobQueue(
+	ctx context.Context,
+	targetJQ OutputJobQueue,
+	limiter *rate.Limiter,
+	keyFunc KeyFunc,
+	newKeyLimiter KeyLimiterFunc,
+) (OutputJobQueue, error) {
+	if err := validateLimiter(limiter); err != nil {
+		return nil, err
+	}
+
+	return &rateLimitedOutputJQ{
+		ctx:            ctx,
+		outputJobQueue: targetJQ,
+		rateLimiter:    limiter,
+		keyFunc:        keyFunc,
+		newKeyLimiter:  newKeyLimiter,
+		outputChannel:  make(chan jobs.Job),
+		slots:          make(chan struct{}, maxHeldJobs),
+		lanes:          make(map[string]*keyLane),
+	}, nil
+}
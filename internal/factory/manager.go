@@ -1 +1,233 @@
 package factory
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/symbols"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+)
+
+// Manager validates submitted CRDs and splits them into jobs, or hands them
+// to Streams if they are streaming.
+type Manager struct {
+	queue     jobqueue.InputJobQueue
+	providers *provider.Registry
+	streams   *Streams
+	symbols   *symbols.Master
+}
+
+// NewManager returns a Manager queueing jobs to queue. STREAMING collections
+// are rejected when streams is nil. Target symbols resolve through master,
+// and are used as given when it is nil or does not know them.
+func NewManager(
+	queue jobqueue.InputJobQueue,
+	providers *provider.Registry,
+	streams *Streams,
+	master *symbols.Master,
+) *Manager {
+	return &Manager{
+		queue:     queue,
+		providers: providers,
+		streams:   streams,
+		symbols:   master,
+	}
+}
+
+// Submit validates collection against the provider registry and queues one job
+// per target security or series. A security renamed during the window gets a
+// job per ticker, each stored under the security's ID. STREAMING collections
+// are started instead, and queue no jobs. Workers queue the next round of the
+// jobs of RECURRING collections.
+func (m *Manager) Submit(ctx context.Context, collection crd.CRD) ([]jobs.Job, error) {
+	if err := m.providers.Validate(collection.GetSource()); err != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+	}
+
+	if collection.GetSchedule().Type == crd.ScheduleStreaming {
+		if m.streams == nil {
+			return nil, fmt.Errorf("invalid collection %s: streaming is not enabled", collection.GetName())
+		}
+
+		if err := m.streams.Start(collection); err != nil {
+			return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+		}
+
+		return []jobs.Job{}, nil
+	}
+
+	schedule := collection.GetSchedule()
+
+	startTime, err := parseScheduleTime("startDate", schedule.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+	}
+
+	endTime, err := parseScheduleTime("endDate", schedule.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+	}
+
+	notBefore, err := parseScheduleTime("startFrom", schedule.StartFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+	}
+
+	if _, recurrenceError := getRecurrence(schedule); recurrenceError != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), recurrenceError)
+	}
+
+	targets, err := m.targets(collection, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection %s: %w", collection.GetName(), err)
+	}
+
+	queued := make([]jobs.Job, 0, len(targets))
+	for _, target := range targets {
+		job := target
+		job.ID = newJobID()
+		job.CRD = collection
+		job.Status = jobs.StatusPending
+		job.NotBefore = notBefore
+
+		if addError := m.queue.Add(ctx, job); addError != nil {
+			return queued, fmt.Errorf("failed to queue job for %s: %w", job.GetTarget(), addError)
+		}
+
+		queued = append(queued, job)
+	}
+
+	return queued, nil
+}
+
+// targets returns a job with only its target and window set for each target
+// of collection. Providers of economic series take series targets, and every
+// other provider takes securities.
+func (m *Manager) targets(collection crd.CRD, startTime time.Time, endTime time.Time) ([]jobs.Job, error) {
+	p, err := m.providers.Get(collection.GetSource().Type)
+	if err != nil {
+		return nil, err
+	}
+
+	securities := collection.GetSecurities()
+	series := collection.GetSeries()
+
+	if slices.Contains(p.Capabilities(), provider.CapabilitySeries) {
+		if len(series) == 0 || len(securities) > 0 {
+			return nil, fmt.Errorf("source type %s takes series targets, not securities", p.Type())
+		}
+
+		targets := make([]jobs.Job, 0, len(series))
+		for _, target := range series {
+			targets = append(targets, jobs.Job{Series: target.ID, StartTime: startTime, EndTime: endTime})
+		}
+
+		return targets, nil
+	}
+
+	if len(securities) == 0 || len(series) > 0 {
+		return nil, fmt.Errorf("source type %s takes security targets, not series", p.Type())
+	}
+
+	targets := make([]jobs.Job, 0, len(securities))
+	for _, target := range securities {
+		security, known := m.symbols.Resolve(target.Symbol, endTime)
+		if !known {
+			targets = append(targets, jobs.Job{
+				Symbol:       target.Symbol,
+				SecurityName: target.Name,
+				StartTime:    startTime,
+				EndTime:      endTime,
+			})
+			continue
+		}
+
+		segments, segmentError := security.GetSegments(p.Type(), startTime, endTime)
+		if segmentError != nil {
+			return nil, segmentError
+		}
+
+		for _, segment := range segments {
+			job := jobs.Job{
+				Symbol:       security.ID,
+				SecurityName: cmp.Or(target.Name, security.Name),
+				StartTime:    segment.From,
+				EndTime:      segment.To,
+			}
+
+			if segment.Symbol != security.ID {
+				job.ProviderSymbol = segment.Symbol
+			}
+
+			targets = append(targets, job)
+		}
+	}
+
+	return targets, nil
+}
+
+func parseScheduleTime(field string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %s %q: %w", field, value, err)
+	}
+
+	return parsed, nil
+}
+
+// getRecurrence returns how often a RECURRING schedule collects its targets,
+// or zero for schedules that collect them once.
+func getRecurrence(schedule crd.DataCollectionSchedule) (time.Duration, error) {
+	const (
+		hoursPerDay = 24
+		daysPerWeek = 7
+	)
+
+	if schedule.Type != crd.ScheduleRecurring {
+		return 0, nil
+	}
+
+	switch strings.ToUpper(schedule.Frequency) {
+	case "":
+		return 0, fmt.Errorf("%s schedules require a frequency", crd.ScheduleRecurring)
+	case crd.FrequencyMinute:
+		return time.Minute, nil
+	case crd.FrequencyHourly:
+		return time.Hour, nil
+	case crd.FrequencyDaily:
+		return hoursPerDay * time.Hour, nil
+	case crd.FrequencyWeekly:
+		return daysPerWeek * hoursPerDay * time.Hour, nil
+	}
+
+	interval, err := time.ParseDuration(schedule.Frequency)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid schedule frequency %q", schedule.Frequency)
+	}
+
+	return interval, nil
+}
+
+func newJobID() string {
+	const (
+		jobIDBytes = 8
+	)
+
+	id := make([]byte, jobIDBytes)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
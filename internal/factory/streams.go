@@ -0,0 +1,349 @@
+package factory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/symbols"
+	"github.com/zydee3/stockdb/internal/config"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+const (
+	// StreamParameterAggregate is the spec.source.parameters key selecting
+	// whether a stream stores "bars" aggregated from its trades, the default,
+	// or every "raw" tick. Bars use the resolution parameter, one minute by
+	// default.
+	StreamParameterAggregate  = "aggregate"
+	StreamParameterResolution = "resolution"
+
+	AggregateBars = "bars"
+	AggregateRaw  = "raw"
+)
+
+// StreamDependencies are the providers and stores Streams operate on. Zero
+// delays use the defaults from config.
+type StreamDependencies struct {
+	Providers *provider.Registry
+	Store     storage.Writer
+	// Symbols resolves the symbols of collections to the securities they
+	// denote now. Nil subscribes to symbols as given.
+	Symbols            *symbols.Master
+	Clock              clock.Clock
+	ReconnectBaseDelay time.Duration
+	ReconnectMaxDelay  time.Duration
+	FlushInterval      time.Duration
+}
+
+// Streams keeps a subscription open for every STREAMING collection, and
+// reconnects and resubscribes when one drops.
+type Streams struct {
+	ctx  context.Context
+	deps StreamDependencies
+
+	mu      sync.Mutex
+	streams map[string]*runningStream
+	group   sync.WaitGroup
+}
+
+type runningStream struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// streamSink writes what a stream normalizes, either as ticks or as bars.
+type streamSink struct {
+	name       string
+	store      storage.Writer
+	aggregator *barAggregator
+	// anchors are the IDs of the securities subscribed to by a symbol other
+	// than their ID.
+	anchors map[string]string
+}
+
+func NewStreams(ctx context.Context, deps StreamDependencies) *Streams {
+	deps.ReconnectBaseDelay = cmp.Or(deps.ReconnectBaseDelay, config.StreamReconnectBaseDelay)
+	deps.ReconnectMaxDelay = cmp.Or(deps.ReconnectMaxDelay, config.StreamReconnectMaxDelay)
+	deps.FlushInterval = cmp.Or(deps.FlushInterval, config.StreamFlushInterval)
+
+	return &Streams{
+		ctx:     ctx,
+		deps:    deps,
+		streams: map[string]*runningStream{},
+	}
+}
+
+// Start validates collection and opens its stream, replacing a running stream
+// of the same name.
+func (s *Streams) Start(collection crd.CRD) error {
+	source := collection.GetSource()
+
+	if err := s.deps.Providers.Validate(source); err != nil {
+		return err
+	}
+
+	p, err := s.deps.Providers.Get(source.Type)
+	if err != nil {
+		return err
+	}
+
+	subscriber, ok := p.(provider.Subscriber)
+	if !ok {
+		return fmt.Errorf("source type %s does not support %s schedules", source.Type, crd.ScheduleStreaming)
+	}
+
+	// A stream stays logged in with the key it was opened with.
+	if source.Secret != "" {
+		return fmt.Errorf("%s schedules use the provider's default secret, remove secret %q",
+			crd.ScheduleStreaming, source.Secret)
+	}
+
+	subscription := provider.Subscription{Endpoint: source.Endpoint, Parameters: source.Parameters}
+	anchors := map[string]string{}
+
+	for _, target := range collection.GetSecurities() {
+		security, known := s.deps.Symbols.Resolve(target.Symbol, time.Time{})
+		if !known {
+			subscription.Symbols = append(subscription.Symbols, target.Symbol)
+			continue
+		}
+
+		segments, segmentError := security.GetSegments(source.Type, time.Time{}, time.Time{})
+		if segmentError != nil {
+			return segmentError
+		}
+
+		subscription.Symbols = append(subscription.Symbols, segments[0].Symbol)
+		if segments[0].Symbol != security.ID {
+			anchors[segments[0].Symbol] = security.ID
+		}
+	}
+
+	if len(subscription.Symbols) == 0 {
+		return fmt.Errorf("source type %s takes security targets", source.Type)
+	}
+
+	sink, err := newStreamSink(collection.GetName(), s.deps.Store, source)
+	if err != nil {
+		return err
+	}
+
+	sink.anchors = anchors
+
+	s.Stop(collection.GetName())
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	stream := &runningStream{cancel: cancel, done: make(chan struct{})}
+
+	s.mu.Lock()
+	s.streams[collection.GetName()] = stream
+	s.mu.Unlock()
+
+	s.group.Add(1)
+	go func() {
+		defer s.group.Done()
+		defer close(stream.done)
+
+		s.run(ctx, p, subscriber, subscription, sink)
+	}()
+
+	logger.Infof("Started stream %s for %d securities from %s",
+		collection.GetName(), len(subscription.Symbols), source.Type)
+
+	return nil
+}
+
+// Stop closes the stream of the named collection and waits for it to write
+// what it has aggregated. It returns false if no such stream is running.
+func (s *Streams) Stop(name string) bool {
+	s.mu.Lock()
+	stream, exists := s.streams[name]
+	delete(s.streams, name)
+	s.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	stream.cancel()
+	<-stream.done
+
+	return true
+}
+
+// Wait blocks until every stream has stopped, which they do once the context
+// Streams was created with is done.
+func (s *Streams) Wait() {
+	s.group.Wait()
+}
+
+func (s *Streams) run(
+	ctx context.Context,
+	p provider.Provider,
+	subscriber provider.Subscriber,
+	subscription provider.Subscription,
+	sink *streamSink,
+) {
+	flushDone := make(chan struct{})
+	go func() {
+		defer close(flushDone)
+		s.flushPeriodically(ctx, sink)
+	}()
+
+	defer func() {
+		<-flushDone
+		sink.flush(time.Time{})
+	}()
+
+	delay := s.deps.ReconnectBaseDelay
+
+	for {
+		connectedAt := s.deps.Clock.Now()
+
+		err := subscriber.Subscribe(ctx, subscription, func(payload *provider.Payload) error {
+			normalized, normalizeError := p.Normalize(payload)
+			if normalizeError != nil {
+				logger.Warnf("Dropping message from stream %s: %v", sink.name, normalizeError)
+				return nil
+			}
+
+			sink.write(ctx, normalized)
+
+			return nil
+		})
+
+		if ctx.Err() != nil {
+			logger.Infof("Stopped stream %s", sink.name)
+			return
+		}
+
+		// A connection that stayed up for a while was healthy, so the next
+		// failure starts the backoff over.
+		if s.deps.Clock.Now().Sub(connectedAt) >= s.deps.ReconnectMaxDelay {
+			delay = s.deps.ReconnectBaseDelay
+		}
+
+		logger.Warnf("Stream %s disconnected, reconnecting in %s: %v", sink.name, delay, err)
+
+		timer := s.deps.Clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Infof("Stopped stream %s", sink.name)
+			return
+		case <-timer.C():
+		}
+
+		delay = min(delay*2, s.deps.ReconnectMaxDelay)
+	}
+}
+
+func (s *Streams) flushPeriodically(ctx context.Context, sink *streamSink) {
+	for {
+		timer := s.deps.Clock.NewTimer(s.deps.FlushInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+			sink.flush(s.deps.Clock.Now())
+		}
+	}
+}
+
+func newStreamSink(name string, store storage.Writer, source crd.DataCollectionSource) (*streamSink, error) {
+	sink := &streamSink{name: name, store: store}
+
+	switch aggregate := source.Parameters[StreamParameterAggregate]; aggregate {
+	case "", AggregateBars:
+		resolution := records.Resolution1Min
+		if value := source.Parameters[StreamParameterResolution]; value != "" {
+			parsed, err := records.ParseResolution(value)
+			if err != nil {
+				return nil, err
+			}
+			resolution = parsed
+		}
+
+		if !resolution.IsIntraday() {
+			return nil, fmt.Errorf("streams aggregate into intraday bars, not %s", resolution)
+		}
+
+		sink.aggregator = newBarAggregator(resolution, source.Type)
+
+	case AggregateRaw:
+
+	default:
+		return nil, fmt.Errorf("unsupported %s %q, expected %s or %s",
+			StreamParameterAggregate, aggregate, AggregateBars, AggregateRaw)
+	}
+
+	return sink, nil
+}
+
+func (s *streamSink) write(ctx context.Context, normalized []records.Record) {
+	ticks := []records.Tick{}
+	batch := []records.Record{}
+
+	for _, record := range normalized {
+		for symbol, anchor := range s.anchors {
+			record = records.Reanchor(record, symbol, anchor)
+		}
+
+		if err := record.Validate(); err != nil {
+			logger.Warnf("Dropping invalid %T from stream %s: %v", record, s.name, err)
+			continue
+		}
+
+		if tick, ok := record.(records.Tick); ok && s.aggregator != nil {
+			ticks = append(ticks, tick)
+			continue
+		}
+
+		batch = append(batch, record)
+	}
+
+	if s.aggregator != nil {
+		for _, bar := range s.aggregator.add(ticks) {
+			batch = append(batch, bar)
+		}
+	}
+
+	s.storeBatch(ctx, batch)
+}
+
+// flush writes the aggregated bars whose period ended by now, or every open
+// bar if now is zero.
+func (s *streamSink) flush(now time.Time) {
+	if s.aggregator == nil {
+		return
+	}
+
+	batch := []records.Record{}
+	for _, bar := range s.aggregator.flush(now) {
+		batch = append(batch, bar)
+	}
+
+	s.storeBatch(context.Background(), batch)
+}
+
+// storeBatch writes batch even if ctx is done, so what a stream received
+// before it was stopped is kept.
+func (s *streamSink) storeBatch(ctx context.Context, batch []records.Record) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if _, err := s.store.Write(context.WithoutCancel(ctx), batch); err != nil {
+		logger.Errorf("Failed to write %d records from stream %s: %v", len(batch), s.name, err)
+	}
+}
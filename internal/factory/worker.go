@@ -1 +1,360 @@
 package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/logger"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	"github.com/zydee3/stockdb/internal/config"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+// WorkerDependencies are the queues and stores a Worker operates on.
+type WorkerDependencies struct {
+	// Jobs is where the worker receives jobs from.
+	Jobs jobqueue.OutputJobQueue
+	// Retries receives failed jobs with a NotBefore time set for backoff,
+	// jobs deferred by an open circuit, and the next round of the jobs of
+	// RECURRING collections.
+	Retries   jobqueue.InputJobQueue
+	Providers *provider.Registry
+	Store     storage.Writer
+	History   *history.Store
+	// Secrets resolves the secrets collections name, and redacts them from
+	// job errors.
+	Secrets *secrets.Resolver
+	// Breakers stop jobs from reaching providers that are failing. Nil runs
+	// every job.
+	Breakers *breaker.Set
+	Clock    clock.Clock
+}
+
+// Worker collects the data for jobs through the provider registered for the
+// job's source type, and writes the normalized records to the store.
+type Worker struct {
+	id   int
+	deps WorkerDependencies
+}
+
+type collectResult struct {
+	rowsWritten  int
+	bytesFetched int64
+}
+
+func NewWorker(id int, deps WorkerDependencies) *Worker {
+	return &Worker{
+		id:   id,
+		deps: deps,
+	}
+}
+
+// Run processes jobs until ctx is done or the job queue is closed.
+func (w *Worker) Run(ctx context.Context) error {
+	jobChannel, err := w.deps.Jobs.GetOutputChannel()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case job, ok := <-jobChannel:
+			if !ok {
+				return nil
+			}
+			w.process(ctx, job)
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job jobs.Job) {
+	key := getBreakerKey(job)
+	if allowed, retryAt := w.deps.Breakers.Allow(key); !allowed {
+		w.deferJob(ctx, job, key, retryAt)
+		return
+	}
+
+	startTime := w.deps.Clock.Now()
+	job.Attempts++
+
+	result, err := w.collect(ctx, job)
+	duration := w.deps.Clock.Now().Sub(startTime)
+
+	w.deps.Breakers.Record(key, getBreakerResult(ctx, err))
+
+	switch {
+	case err == nil:
+		job.Status = jobs.StatusSucceeded
+		logger.Infof("Worker %d completed job %s (%s %s): %d rows in %s",
+			w.id, job.ID, job.GetCollection(), job.GetTarget(), result.rowsWritten, duration)
+		w.record(job, result, duration, history.OutcomeSucceeded, nil)
+		w.scheduleNext(ctx, job)
+
+	case ctx.Err() != nil:
+		logger.Infof("Worker %d cancelled job %s: %v", w.id, job.ID, err)
+		w.record(job, result, duration, history.OutcomeCancelled, err)
+
+	case job.CRD != nil && job.Attempts <= job.CRD.GetOptions().Retries:
+		delay := retryDelay(job.Attempts)
+		job.Status = jobs.StatusRetrying
+		job.NotBefore = w.deps.Clock.Now().Add(delay)
+
+		logger.Warnf("Worker %d failed job %s (attempt %d), retrying in %s: %v", w.id, job.ID, job.Attempts, delay, err)
+
+		if retryError := w.deps.Retries.Add(ctx, job); retryError != nil {
+			logger.Errorf("Failed to schedule retry of job %s: %v", job.ID, retryError)
+			job.Status = jobs.StatusFailed
+			w.record(job, result, duration, history.OutcomeFailed, err)
+		}
+
+	default:
+		job.Status = jobs.StatusFailed
+		logger.Errorf("Worker %d abandoned job %s after %d attempts: %v", w.id, job.ID, job.Attempts, err)
+		w.record(job, result, duration, history.OutcomeFailed, err)
+		w.scheduleNext(ctx, job)
+	}
+}
+
+// scheduleNext puts the next round of a job of a RECURRING collection on the
+// retry queue, a frequency after this round finished, whether it succeeded or
+// was abandoned.
+func (w *Worker) scheduleNext(ctx context.Context, job jobs.Job) {
+	if job.CRD == nil {
+		return
+	}
+
+	interval, err := getRecurrence(job.CRD.GetSchedule())
+	if err != nil || interval == 0 {
+		return
+	}
+
+	next := job
+	next.ID = newJobID()
+	next.Status = jobs.StatusPending
+	next.Attempts = 0
+	next.NotBefore = w.deps.Clock.Now().Add(interval)
+
+	if addError := w.deps.Retries.Add(ctx, next); addError != nil {
+		if ctx.Err() == nil {
+			logger.Errorf("Failed to schedule the next round of job %s: %v", job.ID, addError)
+		}
+		return
+	}
+
+	logger.Debugf("Scheduled job %s (%s %s) for %s",
+		next.ID, next.GetCollection(), next.GetTarget(), next.NotBefore.Format(time.RFC3339))
+}
+
+// deferJob puts a job whose provider endpoint has an open circuit back on the
+// retry queue until retryAt. It does not count as an attempt.
+func (w *Worker) deferJob(ctx context.Context, job jobs.Job, key breaker.Key, retryAt time.Time) {
+	job.Status = jobs.StatusDeferred
+	job.NotBefore = retryAt
+
+	logger.Infof("Worker %d deferred job %s until %s, the circuit of %s %s is open",
+		w.id, job.ID, retryAt.Format(time.RFC3339), key.Source, key.Endpoint)
+
+	if err := w.deps.Retries.Add(ctx, job); err != nil && ctx.Err() == nil {
+		logger.Errorf("Failed to defer job %s: %v", job.ID, err)
+		job.Status = jobs.StatusFailed
+		w.record(job, collectResult{}, 0, history.OutcomeFailed, err)
+	}
+}
+
+func (w *Worker) collect(ctx context.Context, job jobs.Job) (collectResult, error) {
+	result := collectResult{}
+
+	if job.CRD == nil {
+		return result, errors.New("job has no collection")
+	}
+
+	source := job.CRD.GetSource()
+
+	p, err := w.deps.Providers.Get(source.Type)
+	if err != nil {
+		return result, err
+	}
+
+	if timeout := job.CRD.GetOptions().Timeout; timeout != "" {
+		duration, parseError := time.ParseDuration(timeout)
+		if parseError != nil {
+			return result, fmt.Errorf("invalid timeout %q: %w", timeout, parseError)
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	if source.Secret != "" {
+		value, resolveError := w.deps.Secrets.Resolve(source.Secret)
+		if resolveError != nil {
+			return result, resolveError
+		}
+
+		ctx = secrets.NewContext(ctx, value)
+	}
+
+	request := provider.Request{
+		Endpoint:     source.Endpoint,
+		Symbol:       job.GetProviderSymbol(),
+		Series:       job.Series,
+		SecurityName: job.SecurityName,
+		Parameters:   source.Parameters,
+		From:         job.StartTime,
+		To:           job.EndTime,
+	}
+
+	if streamer, ok := p.(provider.Streamer); ok {
+		err = streamer.Stream(ctx, request, func(payload *provider.Payload) error {
+			written, writeError := w.write(ctx, job, p, payload)
+			result.rowsWritten += written
+			result.bytesFetched += payload.BytesFetched
+
+			return writeError
+		})
+		if err != nil {
+			return result, fmt.Errorf("stream failed: %w", err)
+		}
+
+		return result, nil
+	}
+
+	payload, err := p.Fetch(ctx, request)
+	if err != nil {
+		return result, fmt.Errorf("fetch failed: %w", err)
+	}
+
+	result.bytesFetched = payload.BytesFetched
+	result.rowsWritten, err = w.write(ctx, job, p, payload)
+
+	return result, err
+}
+
+// write normalizes payload and writes its valid records to the store.
+func (w *Worker) write(ctx context.Context, job jobs.Job, p provider.Provider, payload *provider.Payload) (int, error) {
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		return 0, fmt.Errorf("normalize failed: %w", err)
+	}
+
+	// Records of a former ticker are stored under the security's ID.
+	if job.ProviderSymbol != "" {
+		for i, record := range normalized {
+			normalized[i] = records.Reanchor(record, job.ProviderSymbol, job.Symbol)
+		}
+	}
+
+	written, err := w.deps.Store.Write(ctx, validRecords(job, normalized))
+	if err != nil {
+		return 0, fmt.Errorf("write failed: %w", err)
+	}
+
+	return written, nil
+}
+
+func (w *Worker) record(
+	job jobs.Job,
+	result collectResult,
+	duration time.Duration,
+	outcome history.Outcome,
+	err error,
+) {
+	if w.deps.History == nil {
+		return
+	}
+
+	entry := history.Entry{
+		JobID:        job.ID,
+		Collection:   job.GetCollection(),
+		Symbol:       job.Symbol,
+		Series:       job.Series,
+		WindowStart:  job.StartTime,
+		WindowEnd:    job.EndTime,
+		Attempts:     job.Attempts,
+		FinishedAt:   w.deps.Clock.Now(),
+		Duration:     duration,
+		RowsWritten:  result.rowsWritten,
+		BytesFetched: result.bytesFetched,
+		Outcome:      outcome,
+	}
+
+	if job.CRD != nil {
+		entry.Source = job.CRD.GetSource().Type
+		entry.Endpoint = job.CRD.GetSource().Endpoint
+	}
+
+	if err != nil {
+		entry.Error = w.deps.Secrets.Redact(err.Error())
+	}
+
+	if appendError := w.deps.History.Append(entry); appendError != nil {
+		logger.Errorf("Failed to record job %s in history: %v", job.ID, appendError)
+	}
+}
+
+// getBreakerKey returns the circuit of the provider endpoint job collects
+// from.
+func getBreakerKey(job jobs.Job) breaker.Key {
+	if job.CRD == nil {
+		return breaker.Key{}
+	}
+
+	source := job.CRD.GetSource()
+
+	return breaker.Key{Source: source.Type, Endpoint: source.Endpoint}
+}
+
+// getBreakerResult classifies the error of a job for its circuit. Only
+// failures of the provider count against it, not jobs for data it does not
+// have.
+func getBreakerResult(ctx context.Context, err error) breaker.Result {
+	switch {
+	case err == nil:
+		return breaker.ResultSuccess
+	case ctx.Err() != nil:
+		return breaker.ResultIgnored
+	case provider.IsUnavailable(err):
+		return breaker.ResultFailure
+	default:
+		return breaker.ResultSuccess
+	}
+}
+
+// validRecords drops the records that fail validation, so a single malformed
+// row from a provider does not fail the whole batch.
+func validRecords(job jobs.Job, normalized []records.Record) []records.Record {
+	valid := make([]records.Record, 0, len(normalized))
+	for _, record := range normalized {
+		if err := record.Validate(); err != nil {
+			logger.Warnf("Dropping invalid %T from job %s: %v", record, job.ID, err)
+			continue
+		}
+		valid = append(valid, record)
+	}
+
+	return valid
+}
+
+func retryDelay(attempt int) time.Duration {
+	delay := config.RetryBaseDelay
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= config.RetryMaxDelay {
+			return config.RetryMaxDelay
+		}
+	}
+
+	return delay
+}
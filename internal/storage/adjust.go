@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// Adjustment selects how historical bars are back-adjusted for corporate
+// actions. Back-adjusted bars keep the latest prices as they traded and scale
+// earlier bars so they compare with them.
+type Adjustment string
+
+const (
+	// AdjustNone returns bars as they are stored, which is already adjusted
+	// for splits for bars with records.Bar.SplitAdjusted set.
+	AdjustNone Adjustment = ""
+	// AdjustSplit adjusts prices and volumes for splits, unless the provider
+	// already did.
+	AdjustSplit Adjustment = "split"
+	// AdjustTotalReturn adjusts prices for splits and for reinvested
+	// dividends, so the change between two closes is the total return.
+	AdjustTotalReturn Adjustment = "total-return"
+)
+
+func ParseAdjustment(value string) (Adjustment, error) {
+	switch adjustment := Adjustment(value); adjustment {
+	case AdjustNone, AdjustSplit, AdjustTotalReturn:
+		return adjustment, nil
+	default:
+		return "", fmt.Errorf("unsupported adjustment %q, expected %s or %s", value, AdjustSplit, AdjustTotalReturn)
+	}
+}
+
+// SplitsForSecurity returns the splits of symbol with an ex-date in [from, to],
+// oldest first.
+func (s *Store) SplitsForSecurity(symbol string, from time.Time, to time.Time) []records.Split {
+	return s.Splits.Query(symbol, from, to)
+}
+
+// DividendsForSecurity returns the dividends of symbol with an ex-date in
+// [from, to], oldest first.
+func (s *Store) DividendsForSecurity(symbol string, from time.Time, to time.Time) []records.Dividend {
+	return s.Dividends.Query(symbol, from, to)
+}
+
+// AdjustedBarsForSecurity returns the bars of symbol at resolution that start
+// in [from, to], oldest first, back-adjusted for the stored corporate actions.
+func (s *Store) AdjustedBarsForSecurity(
+	symbol string,
+	resolution records.Resolution,
+	from time.Time,
+	to time.Time,
+	adjustment Adjustment,
+) []records.Bar {
+	if adjustment == AdjustNone {
+		return s.BarsForSecurity(symbol, resolution, from, to)
+	}
+
+	// Dividend factors depend on the close before each ex-date, which may be
+	// outside the window, so the whole history is adjusted.
+	bars := s.BarsForSecurity(symbol, resolution, time.Time{}, time.Time{})
+
+	dividends := []records.Dividend{}
+	if adjustment == AdjustTotalReturn {
+		dividends = s.DividendsForSecurity(symbol, time.Time{}, time.Time{})
+	}
+
+	adjusted := AdjustBars(bars, s.SplitsForSecurity(symbol, time.Time{}, time.Time{}), dividends)
+
+	return slices.DeleteFunc(adjusted, func(bar records.Bar) bool {
+		return (!from.IsZero() && bar.Time.Before(from)) || (!to.IsZero() && bar.Time.After(to))
+	})
+}
+
+// AdjustBars back-adjusts bars, which must be ordered by time, for splits and
+// dividends. An action applies to the bars that start before its ex-date, and
+// splits skip bars that are already split-adjusted. A dividend scales prices
+// by one minus its amount over the last close before the ex-date, with the
+// amount split-adjusted like that close, and is ignored if there is no such
+// close.
+func AdjustBars(bars []records.Bar, splits []records.Split, dividends []records.Dividend) []records.Bar {
+	type factor struct {
+		exDate time.Time
+		price  float64
+		volume float64
+		split  bool
+	}
+
+	factors := make([]factor, 0, len(splits)+len(dividends))
+
+	for _, split := range splits {
+		ratio := split.GetRatio()
+		factors = append(factors, factor{exDate: split.ExDate, price: 1 / ratio, volume: ratio, split: true})
+	}
+
+	for _, dividend := range dividends {
+		// The first bar on or after the ex-date.
+		first := slices.IndexFunc(bars, func(bar records.Bar) bool {
+			return !bar.Time.Before(dividend.ExDate)
+		})
+
+		if first < 0 {
+			first = len(bars)
+		}
+
+		if first == 0 {
+			continue
+		}
+
+		// The amount is declared on the share basis of its ex-date, which
+		// later splits change for split-adjusted closes.
+		previous, amount := bars[first-1], dividend.Amount
+		if previous.SplitAdjusted {
+			amount /= getSplitRatioAfter(splits, dividend.ExDate)
+		}
+
+		if previous.Close <= amount {
+			continue
+		}
+
+		factors = append(factors, factor{
+			exDate: dividend.ExDate,
+			price:  1 - amount/previous.Close,
+			volume: 1,
+		})
+	}
+
+	adjusted := slices.Clone(bars)
+	for i := range adjusted {
+		priceFactor, volumeFactor := 1.0, 1.0
+		for _, f := range factors {
+			if f.split && adjusted[i].SplitAdjusted {
+				continue
+			}
+
+			if adjusted[i].Time.Before(f.exDate) {
+				priceFactor *= f.price
+				volumeFactor *= f.volume
+			}
+		}
+
+		adjusted[i].Open *= priceFactor
+		adjusted[i].High *= priceFactor
+		adjusted[i].Low *= priceFactor
+		adjusted[i].Close *= priceFactor
+		adjusted[i].Volume *= volumeFactor
+	}
+
+	return adjusted
+}
+
+// getSplitRatioAfter returns the shares after every split with an ex-date
+// after exDate per share before them.
+func getSplitRatioAfter(splits []records.Split, exDate time.Time) float64 {
+	ratio := 1.0
+	for _, split := range splits {
+		if split.ExDate.After(exDate) {
+			ratio *= split.GetRatio()
+		}
+	}
+
+	return ratio
+}
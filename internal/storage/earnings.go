@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// EarningsReactionQuery selects earnings surprises and the price reaction to
+// them.
+type EarningsReactionQuery struct {
+	Symbol string
+	// Limit is the number of most recent reports to return. Zero returns
+	// every report.
+	Limit int
+	// Days is the number of trading days after the report date the reaction
+	// is measured through. Zero is one, the first close after the report.
+	Days int
+}
+
+// EarningsReaction is a reported earnings surprise and the move in the daily
+// close around it. The move is measured from the last close before the report
+// date to a close after it, so it covers reports released both before the
+// open and after the close.
+type EarningsReaction struct {
+	Earnings records.Earnings `json:"earnings"`
+
+	// BaseBar is the last daily bar before the report date and ReactionBar the
+	// bar Days trading days after it. Either is nil if it has not been
+	// collected.
+	BaseBar     *records.Bar `json:"baseBar,omitempty"`
+	ReactionBar *records.Bar `json:"reactionBar,omitempty"`
+
+	// PriceChangePercent is nil unless both bars are known.
+	PriceChangePercent *float64 `json:"priceChangePercent,omitempty"`
+}
+
+// EarningsForSecurity returns the earnings of symbol reported in [from, to],
+// oldest first.
+func (s *Store) EarningsForSecurity(symbol string, from time.Time, to time.Time) []records.Earnings {
+	return s.Earnings.Query(symbol, from, to)
+}
+
+// EarningsReactions returns the reported earnings of the query's symbol, most
+// recent first, each with the price reaction to it.
+func (s *Store) EarningsReactions(query EarningsReactionQuery) []EarningsReaction {
+	const (
+		percent = 100
+	)
+
+	days := max(query.Days, 1)
+	bars := s.BarsForSecurity(query.Symbol, records.Resolution1Day, time.Time{}, time.Time{})
+
+	reactions := []EarningsReaction{}
+
+	earnings := s.EarningsForSecurity(query.Symbol, time.Time{}, time.Time{})
+	for _, report := range slices.Backward(earnings) {
+		if query.Limit > 0 && len(reactions) >= query.Limit {
+			break
+		}
+
+		if !report.IsReported() {
+			continue
+		}
+
+		reaction := EarningsReaction{Earnings: report}
+
+		// The first bar on or after the report date, and the first after it.
+		// The close of the report date precedes reports released after it.
+		first := slices.IndexFunc(bars, func(bar records.Bar) bool {
+			return !bar.Time.Before(report.ReportDate)
+		})
+		if first < 0 {
+			first = len(bars)
+		}
+
+		after := first
+		if after < len(bars) && bars[after].Time.Equal(report.ReportDate) {
+			after++
+		}
+
+		if first > 0 {
+			reaction.BaseBar = &bars[first-1]
+		}
+
+		if last := after + days - 1; last < len(bars) {
+			reaction.ReactionBar = &bars[last]
+		}
+
+		if reaction.BaseBar != nil && reaction.ReactionBar != nil && reaction.BaseBar.Close != 0 {
+			change := (reaction.ReactionBar.Close - reaction.BaseBar.Close) / reaction.BaseBar.Close * percent
+			reaction.PriceChangePercent = &change
+		}
+
+		reactions = append(reactions, reaction)
+	}
+
+	return reactions
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// OptionChainQuery selects the options of an underlying and their quotes from
+// one snapshot of the chain.
+type OptionChainQuery struct {
+	Underlying string
+	// At selects the last snapshot at or before it. Zero selects the latest
+	// snapshot.
+	At time.Time
+	// ExpiresFrom and ExpiresTo bound the expirations returned. ExpiresFrom
+	// defaults to the day of the snapshot, so expired options are left out,
+	// and a zero ExpiresTo leaves the range open.
+	ExpiresFrom time.Time
+	ExpiresTo   time.Time
+}
+
+// OptionChainEntry is an option of a chain and its quote.
+type OptionChainEntry struct {
+	Contract records.OptionContract `json:"contract"`
+	// Quote is nil if the option was not quoted in the snapshot.
+	Quote *records.OptionQuote `json:"quote,omitempty"`
+}
+
+// OptionChain returns the options of the query's underlying ordered by
+// expiration, strike and type, each with its quote in the snapshot queried.
+// A snapshot is the quotes of one fetch of the chain, which share their time,
+// so options missing from it are not shown with older quotes.
+func (s *Store) OptionChain(query OptionChainQuery) []OptionChainEntry {
+	contracts := s.OptionContracts.Query(query.Underlying, time.Time{}, query.ExpiresTo)
+
+	quotes := make(map[string]records.OptionQuote, len(contracts))
+	snapshot := time.Time{}
+
+	for _, contract := range contracts {
+		contractQuotes := s.OptionQuotes.Query(contract.Symbol, time.Time{}, query.At)
+		if len(contractQuotes) == 0 {
+			continue
+		}
+
+		quote := contractQuotes[len(contractQuotes)-1]
+		quotes[contract.Symbol] = quote
+
+		if quote.Time.After(snapshot) {
+			snapshot = quote.Time
+		}
+	}
+
+	expiresFrom := query.ExpiresFrom
+	if expiresFrom.IsZero() && !snapshot.IsZero() {
+		year, month, day := snapshot.UTC().Date()
+		expiresFrom = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	contracts = slices.DeleteFunc(contracts, func(contract records.OptionContract) bool {
+		return contract.Expiration.Before(expiresFrom)
+	})
+
+	slices.SortFunc(contracts, func(a records.OptionContract, b records.OptionContract) int {
+		return cmp.Or(
+			a.Expiration.Compare(b.Expiration),
+			cmp.Compare(a.Strike, b.Strike),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+
+	chain := make([]OptionChainEntry, 0, len(contracts))
+	for _, contract := range contracts {
+		entry := OptionChainEntry{Contract: contract}
+
+		if quote, exists := quotes[contract.Symbol]; exists && quote.Time.Equal(snapshot) {
+			entry.Quote = &quote
+		}
+
+		chain = append(chain, entry)
+	}
+
+	return chain
+}
+
+// OptionQuotesForContract returns the quotes of the option symbol taken in
+// [from, to], oldest first.
+func (s *Store) OptionQuotesForContract(symbol string, from time.Time, to time.Time) []records.OptionQuote {
+	return s.OptionQuotes.Query(symbol, from, to)
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// SeriesForID returns the observations of a series for dates in [from, to],
+// oldest first, as they were published at asOf. A zero asOf returns the
+// current values.
+func (s *Store) SeriesForID(
+	seriesID string,
+	from time.Time,
+	to time.Time,
+	asOf time.Time,
+) []records.SeriesObservation {
+	observations := []records.SeriesObservation{}
+
+	for _, observation := range s.SeriesObservations.Query(seriesID, from, to) {
+		current := observation.RealtimeEnd.IsZero()
+		if !asOf.IsZero() {
+			current = observation.IsCurrentAt(asOf)
+		}
+
+		if current {
+			observations = append(observations, observation)
+		}
+	}
+
+	return observations
+}
+
+// SeriesVintages returns every published value of a series for dates in
+// [from, to], ordered by date and then by when each value was published.
+func (s *Store) SeriesVintages(seriesID string, from time.Time, to time.Time) []records.SeriesObservation {
+	return s.SeriesObservations.Query(seriesID, from, to)
+}
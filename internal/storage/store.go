@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// Writer persists batches of normalized records.
+type Writer interface {
+	Write(ctx context.Context, batch []records.Record) (int, error)
+}
+
+// Store holds every fact table. It is the in-memory implementation of the
+// StockDB schema, used until the TimescaleDB backend is in place.
+type Store struct {
+	News           *Table[records.NewsArticle]
+	NewsSecurities *Table[records.NewsSecurity]
+	Bars           *Table[records.Bar]
+	StatementItems *Table[records.StatementItem]
+	Earnings       *Table[records.Earnings]
+	Splits         *Table[records.Split]
+	Dividends      *Table[records.Dividend]
+	Filings        *Table[records.Filing]
+	Facts          *Table[records.Fact]
+	Ticks          *Table[records.Tick]
+
+	// OptionContracts are anchored to their underlying and OptionQuotes to
+	// the option symbol.
+	OptionContracts *Table[records.OptionContract]
+	OptionQuotes    *Table[records.OptionQuote]
+
+	// SeriesObservations are economic series, which are not tied to a
+	// security.
+	SeriesObservations *Table[records.SeriesObservation]
+}
+
+func NewStore() *Store {
+	return &Store{
+		News:           NewTable[records.NewsArticle](),
+		NewsSecurities: NewTable[records.NewsSecurity](),
+		Bars:           NewTable[records.Bar](),
+		StatementItems: NewTable[records.StatementItem](),
+		Earnings:       NewTable[records.Earnings](),
+		Splits:         NewTable[records.Split](),
+		Dividends:      NewTable[records.Dividend](),
+		Filings:        NewTable[records.Filing](),
+		Facts:          NewTable[records.Fact](),
+		Ticks:          NewTable[records.Tick](),
+
+		OptionContracts: NewTable[records.OptionContract](),
+		OptionQuotes:    NewTable[records.OptionQuote](),
+
+		SeriesObservations: NewTable[records.SeriesObservation](),
+	}
+}
+
+// Write upserts each record into the table for its type and returns the
+// number of records written.
+func (s *Store) Write(ctx context.Context, batch []records.Record) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	// Sort the batch into tables first so an unknown record type rejects the
+	// whole batch instead of leaving it partially written.
+	news := []records.NewsArticle{}
+	newsSecurities := []records.NewsSecurity{}
+	bars := []records.Bar{}
+	statementItems := []records.StatementItem{}
+	earnings := []records.Earnings{}
+	splits := []records.Split{}
+	dividends := []records.Dividend{}
+	filings := []records.Filing{}
+	facts := []records.Fact{}
+	ticks := []records.Tick{}
+	optionContracts := []records.OptionContract{}
+	optionQuotes := []records.OptionQuote{}
+	seriesObservations := []records.SeriesObservation{}
+
+	for _, record := range batch {
+		switch row := record.(type) {
+		case records.NewsArticle:
+			news = append(news, row)
+		case records.NewsSecurity:
+			newsSecurities = append(newsSecurities, row)
+		case records.Bar:
+			bars = append(bars, row)
+		case records.StatementItem:
+			statementItems = append(statementItems, row)
+		case records.Earnings:
+			earnings = append(earnings, row)
+		case records.Split:
+			splits = append(splits, row)
+		case records.Dividend:
+			dividends = append(dividends, row)
+		case records.Filing:
+			filings = append(filings, row)
+		case records.Fact:
+			facts = append(facts, row)
+		case records.Tick:
+			ticks = append(ticks, row)
+		case records.OptionContract:
+			optionContracts = append(optionContracts, row)
+		case records.OptionQuote:
+			optionQuotes = append(optionQuotes, row)
+		case records.SeriesObservation:
+			seriesObservations = append(seriesObservations, row)
+		default:
+			return 0, fmt.Errorf("no table for record type %T", record)
+		}
+	}
+
+	s.News.Upsert(news...)
+	s.NewsSecurities.Upsert(newsSecurities...)
+	s.Bars.Upsert(bars...)
+	s.StatementItems.Upsert(statementItems...)
+	s.Earnings.Upsert(earnings...)
+	s.Splits.Upsert(splits...)
+	s.Dividends.Upsert(dividends...)
+	s.Filings.Upsert(filings...)
+	s.Facts.Upsert(facts...)
+	s.Ticks.Upsert(ticks...)
+	s.OptionContracts.Upsert(optionContracts...)
+	s.OptionQuotes.Upsert(optionQuotes...)
+	s.SeriesObservations.Upsert(seriesObservations...)
+
+	return len(batch), nil
+}
+
+// NewsForSecurity returns the articles linked to symbol that were published in
+// [from, to], oldest first.
+func (s *Store) NewsForSecurity(symbol string, from time.Time, to time.Time) []records.NewsArticle {
+	links := s.NewsSecurities.Query(symbol, from, to)
+
+	articles := make([]records.NewsArticle, 0, len(links))
+	for _, link := range links {
+		if article, exists := s.News.Get("", link.NewsID); exists {
+			articles = append(articles, article)
+		}
+	}
+
+	return articles
+}
+
+// BarsForSecurity returns the bars of symbol at resolution that start in
+// [from, to], oldest first.
+func (s *Store) BarsForSecurity(
+	symbol string,
+	resolution records.Resolution,
+	from time.Time,
+	to time.Time,
+) []records.Bar {
+	bars := []records.Bar{}
+	for _, bar := range s.Bars.Query(symbol, from, to) {
+		if bar.Resolution == resolution {
+			bars = append(bars, bar)
+		}
+	}
+
+	return bars
+}
+
+// StatementForSecurity returns the items of the statements of symbol that
+// report on a period ending in [from, to], ordered by report date.
+func (s *Store) StatementForSecurity(
+	symbol string,
+	statement records.Statement,
+	from time.Time,
+	to time.Time,
+) []records.StatementItem {
+	items := []records.StatementItem{}
+	for _, item := range s.StatementItems.Query(symbol, from, to) {
+		if item.Statement == statement {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// Table is an in-memory fact table. Rows are partitioned by anchor and
+// upserted by key, mirroring the (security_id, time) layout and ON CONFLICT
+// upserts of the hypertables described in the README.
+type Table[T records.Record] struct {
+	mu   sync.RWMutex
+	rows map[string]map[string]T
+}
+
+func NewTable[T records.Record]() *Table[T] {
+	return &Table[T]{
+		rows: make(map[string]map[string]T),
+	}
+}
+
+// Upsert writes rows, replacing rows with the same anchor and key. It returns
+// the number of rows that did not exist before.
+func (t *Table[T]) Upsert(rows ...T) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	inserted := 0
+	for _, row := range rows {
+		partition, exists := t.rows[row.GetAnchor()]
+		if !exists {
+			partition = make(map[string]T)
+			t.rows[row.GetAnchor()] = partition
+		}
+
+		if _, exists = partition[row.GetKey()]; !exists {
+			inserted++
+		}
+
+		partition[row.GetKey()] = row
+	}
+
+	return inserted
+}
+
+func (t *Table[T]) Get(anchor string, key string) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, exists := t.rows[anchor][key]
+	return row, exists
+}
+
+// Query returns the rows of anchor with a timestamp in [from, to], ordered by
+// timestamp. A zero from or to leaves that side of the range open.
+func (t *Table[T]) Query(anchor string, from time.Time, to time.Time) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	matched := []T{}
+	for _, row := range t.rows[anchor] {
+		timestamp := row.GetTimestamp()
+		if !from.IsZero() && timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && timestamp.After(to) {
+			continue
+		}
+		matched = append(matched, row)
+	}
+
+	slices.SortFunc(matched, func(a T, b T) int {
+		if c := a.GetTimestamp().Compare(b.GetTimestamp()); c != 0 {
+			return c
+		}
+		if a.GetKey() < b.GetKey() {
+			return -1
+		}
+		if a.GetKey() > b.GetKey() {
+			return 1
+		}
+		return 0
+	})
+
+	return matched
+}
+
+// Anchors returns every anchor that has at least one row.
+func (t *Table[T]) Anchors() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	anchors := make([]string, 0, len(t.rows))
+	for anchor := range t.rows {
+		anchors = append(anchors, anchor)
+	}
+	slices.Sort(anchors)
+
+	return anchors
+}
+
+func (t *Table[T]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	count := 0
+	for _, partition := range t.rows {
+		count += len(partition)
+	}
+
+	return count
+}
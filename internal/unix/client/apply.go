@@ -2,9 +2,7 @@ package client
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net"
 	"os"
 
 	"github.com/urfave/cli/v3"
@@ -13,7 +11,6 @@ import (
 	"github.com/zydee3/stockdb/internal/common/crd"
 	"github.com/zydee3/stockdb/internal/common/logger"
 	"github.com/zydee3/stockdb/internal/unix/messages"
-	"github.com/zydee3/stockdb/internal/unix/socket"
 )
 
 //nolint:gochecknoglobals // gochecknoglobals
@@ -71,34 +68,18 @@ func onAction(_ context.Context, cmd *cli.Command) error {
 		return cli.Exit(err, 1)
 	}
 
-	conn, err := net.Dial("unix", socket.SocketPath)
-	if err != nil {
-		return cli.Exit(err, 1)
-	}
-
-	defer conn.Close()
-
 	stockdbCmd := messages.Command{
 		Type:       messages.CommandTypeApply,
 		Parameters: make(map[string]string),
 		Data:       crd,
 	}
 
-	encoder := json.NewEncoder(conn)
-	if encodeError := encoder.Encode(stockdbCmd); encodeError != nil {
-		logger.Error("%w", encodeError)
-		return cli.Exit(encodeError, 1)
-	}
-
-	// Receive and parse response
-	response := messages.Response{}
-
-	decoder := json.NewDecoder(conn)
-	if decodeError := decoder.Decode(&response); decodeError != nil {
-		return cli.Exit(decodeError, 1)
+	response, err := sendCommand(stockdbCmd, nil)
+	if err != nil {
+		return cli.Exit(err, 1)
 	}
 
-	logger.Info("Response received from server:", response)
+	logger.Info("Response received from server:", *response)
 
 	return nil
 }
@@ -2,21 +2,46 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"os"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/zydee3/stockdb/internal/common/logger"
 	"github.com/zydee3/stockdb/internal/common/version"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	"github.com/zydee3/stockdb/internal/unix/socket"
 )
 
+// socketPath is the daemon socket the commands are sent to.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var socketPath = socket.SocketPath
+
 func Init() {
 	cmd := &cli.Command{
 		Name:        "stockctl",
 		Description: "Command-line tool for StockDB",
 		Version:     version.GetVersion(),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "socket",
+				Usage: "path of the stockd socket",
+				Value: socket.SocketPath,
+			},
+		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			socketPath = cmd.String("socket")
+			return ctx, nil
+		},
 		Commands: []*cli.Command{
 			&applyYamlCommand,
+			&queueCommand,
+			&historyCommand,
+			&queryCommand,
+			&statusCommand,
 		},
 	}
 
@@ -25,3 +50,39 @@ func Init() {
 		os.Exit(1)
 	}
 }
+
+// sendCommand sends stockdbCmd to the daemon and decodes the response. When
+// data is a non-nil pointer, the response data is decoded into it.
+func sendCommand(stockdbCmd messages.Command, data any) (*messages.Response, error) {
+	return Send(socketPath, stockdbCmd, data)
+}
+
+// Send sends stockdbCmd to the daemon listening on path and decodes the
+// response like sendCommand.
+func Send(path string, stockdbCmd messages.Command, data any) (*messages.Response, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	encoder := json.NewEncoder(conn)
+	if encodeError := encoder.Encode(stockdbCmd); encodeError != nil {
+		return nil, encodeError
+	}
+
+	// Receive and parse response
+	response := &messages.Response{Data: data}
+
+	decoder := json.NewDecoder(conn)
+	if decodeError := decoder.Decode(response); decodeError != nil {
+		return nil, decodeError
+	}
+
+	if response.Type == messages.ResponseTypeError {
+		return response, errors.New(response.Message)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	"github.com/zydee3/stockdb/internal/unix/server/handlers"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var historyCommand = cli.Command{
+	Name:        "history",
+	Description: `Show finished jobs, most recent first.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "collection", Aliases: []string{"c"}, Usage: "only show jobs of this collection"},
+		&cli.StringFlag{Name: "symbol", Aliases: []string{"s"}, Usage: "only show jobs for this symbol or series"},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "only show jobs finished after a time (RFC 3339 or YYYY-MM-DD, UTC) or within a duration (e.g. 24h)",
+		},
+		&cli.BoolFlag{Name: "failed", Usage: "only show jobs that did not succeed"},
+		&cli.IntFlag{Name: "limit", Aliases: []string{"n"}, Usage: "show at most this many jobs"},
+	},
+	Action: onHistory,
+}
+
+// parseSince accepts an absolute time, as parseTime does, or a duration
+// relative to now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if since, err := parseTime(value); err == nil {
+		return since, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: expected a time or a duration", value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+func onHistory(_ context.Context, cmd *cli.Command) error {
+	parameters := make(map[string]string)
+
+	if collection := cmd.String("collection"); collection != "" {
+		parameters[handlers.ParameterCollection] = collection
+	}
+
+	if symbol := cmd.String("symbol"); symbol != "" {
+		parameters[handlers.ParameterSymbol] = symbol
+	}
+
+	if value := cmd.String("since"); value != "" {
+		since, err := parseSince(value, time.Now())
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+		parameters[handlers.ParameterSince] = since.Format(time.RFC3339)
+	}
+
+	if cmd.Bool("failed") {
+		parameters[handlers.ParameterFailed] = strconv.FormatBool(true)
+	}
+
+	if limit := cmd.Int("limit"); limit > 0 {
+		parameters[handlers.ParameterLimit] = strconv.Itoa(limit)
+	}
+
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeHistory,
+		Parameters: parameters,
+	}
+
+	response := &apitypes.HistoryResponse{}
+	if _, err := sendCommand(stockdbCmd, response); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "FINISHED\tJOB\tCOLLECTION\tTARGET\tWINDOW\tATTEMPTS\tDURATION\tROWS\tBYTES\tOUTCOME\tERROR")
+	for _, entry := range response.Entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%d\t%d\t%s\t%s\n",
+			entry.FinishedAt.Local().Format(time.DateTime),
+			entry.JobID,
+			entry.Collection,
+			cmp.Or(entry.Symbol, entry.Series),
+			formatWindow(entry.WindowStart, entry.WindowEnd),
+			entry.Attempts,
+			entry.Duration.Round(time.Millisecond),
+			entry.RowsWritten,
+			entry.BytesFetched,
+			entry.Outcome,
+			entry.Error,
+		)
+	}
+
+	return writer.Flush()
+}
+
+func formatWindow(start time.Time, end time.Time) string {
+	if start.IsZero() && end.IsZero() {
+		return "-"
+	}
+
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return format(start) + ".." + format(end)
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	"github.com/zydee3/stockdb/internal/unix/server/handlers"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var queryCommand = cli.Command{
+	Name:        "query",
+	Description: `Query collected data.`,
+	Commands: []*cli.Command{
+		{
+			Name:        "earnings",
+			Description: `Show earnings surprises with the price reaction to each, most recent first.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "symbol", Aliases: []string{"s"}, Usage: "security to query", Required: true},
+				&cli.IntFlag{Name: "quarters", Aliases: []string{"n"}, Usage: "show this many reports", Value: 8},
+				&cli.IntFlag{
+					Name:  "days",
+					Usage: "trading days after the report to measure the price reaction through, from the close before it",
+					Value: 1,
+				},
+			},
+			Action: onQueryEarnings,
+		},
+		{
+			Name:        "bars",
+			Description: `Show the price bars of a security, oldest first.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "symbol", Aliases: []string{"s"}, Usage: "security to query", Required: true},
+				&cli.StringFlag{
+					Name:    "resolution",
+					Aliases: []string{"r"},
+					Usage:   "bar resolution: 1min, 5min, 15min, 30min, 1hour, 4hour or 1day",
+					Value:   "1day",
+				},
+				&cli.StringFlag{Name: "from", Usage: "first bar start time (RFC 3339 or YYYY-MM-DD, UTC)"},
+				&cli.StringFlag{Name: "to", Usage: "last bar start time (RFC 3339 or YYYY-MM-DD, UTC)"},
+				&cli.StringFlag{
+					Name:  "adjust",
+					Usage: "back-adjust prices for corporate actions: split or total-return",
+				},
+			},
+			Action: onQueryBars,
+		},
+		{
+			Name:        "options",
+			Description: `Show the option chain of a security from its latest snapshot, without options expired by then.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "symbol", Aliases: []string{"s"}, Usage: "underlying security to query", Required: true},
+				&cli.StringFlag{Name: "at", Usage: "show the last snapshot at or before this time (RFC 3339 or YYYY-MM-DD, UTC)"},
+				&cli.StringFlag{Name: "expires-from", Usage: "first expiration date, defaults to the snapshot date"},
+				&cli.StringFlag{Name: "expires-to", Usage: "last expiration date"},
+			},
+			Action: onQueryOptions,
+		},
+	},
+}
+
+func onQueryEarnings(_ context.Context, cmd *cli.Command) error {
+	stockdbCmd := messages.Command{
+		Type: messages.CommandTypeQueryEarnings,
+		Parameters: map[string]string{
+			handlers.ParameterSymbol: cmd.String("symbol"),
+			handlers.ParameterLimit:  strconv.Itoa(cmd.Int("quarters")),
+			handlers.ParameterDays:   strconv.Itoa(cmd.Int("days")),
+		},
+	}
+
+	response := &apitypes.EarningsResponse{}
+	if _, err := sendCommand(stockdbCmd, response); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "DATE\tEPS\tEPS EST\tEPS SURPRISE\tREVENUE\tREVENUE EST\tREVENUE SURPRISE\tPRICE CHANGE")
+	for _, reaction := range response.Reactions {
+		earnings := reaction.Earnings
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			earnings.ReportDate.Format(time.DateOnly),
+			formatNumber(earnings.EPSActual),
+			formatNumber(earnings.EPSEstimated),
+			formatPercent(earnings.EPSSurprisePercent),
+			formatNumber(earnings.RevenueActual),
+			formatNumber(earnings.RevenueEstimated),
+			formatPercent(earnings.RevenueSurprisePercent),
+			formatPercent(reaction.PriceChangePercent),
+		)
+	}
+
+	return writer.Flush()
+}
+
+func onQueryBars(_ context.Context, cmd *cli.Command) error {
+	parameters := map[string]string{
+		handlers.ParameterSymbol:     cmd.String("symbol"),
+		handlers.ParameterResolution: cmd.String("resolution"),
+		handlers.ParameterAdjust:     cmd.String("adjust"),
+	}
+
+	if err := addTimeParameters(cmd, parameters, map[string]string{
+		"from": handlers.ParameterFrom,
+		"to":   handlers.ParameterTo,
+	}); err != nil {
+		return err
+	}
+
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeQueryBars,
+		Parameters: parameters,
+	}
+
+	response := &apitypes.BarsResponse{}
+	if _, err := sendCommand(stockdbCmd, response); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "TIME\tOPEN\tHIGH\tLOW\tCLOSE\tVOLUME")
+	for _, bar := range response.Bars {
+		fmt.Fprintf(writer, "%s\t%.4f\t%.4f\t%.4f\t%.4f\t%.0f\n",
+			bar.Time.UTC().Format(time.RFC3339), bar.Open, bar.High, bar.Low, bar.Close, bar.Volume)
+	}
+
+	return writer.Flush()
+}
+
+func onQueryOptions(_ context.Context, cmd *cli.Command) error {
+	parameters := map[string]string{
+		handlers.ParameterSymbol: cmd.String("symbol"),
+	}
+
+	if err := addTimeParameters(cmd, parameters, map[string]string{
+		"at":           handlers.ParameterAt,
+		"expires-from": handlers.ParameterExpiresFrom,
+		"expires-to":   handlers.ParameterExpiresTo,
+	}); err != nil {
+		return err
+	}
+
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeQueryOptions,
+		Parameters: parameters,
+	}
+
+	response := &apitypes.OptionChainResponse{}
+	if _, err := sendCommand(stockdbCmd, response); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "EXPIRATION\tSTRIKE\tTYPE\tBID\tASK\tLAST\tVOLUME\tOPEN INTEREST\tIV\tQUOTED")
+	for _, entry := range response.Options {
+		contract := entry.Contract
+		fmt.Fprintf(writer, "%s\t%g\t%s\t", contract.Expiration.Format(time.DateOnly), contract.Strike, contract.Type)
+
+		quote := entry.Quote
+		if quote == nil {
+			fmt.Fprintln(writer, "-\t-\t-\t-\t-\t-\t-")
+			continue
+		}
+
+		fmt.Fprintf(writer, "%.2f\t%.2f\t%.2f\t%.0f\t%.0f\t%.2f%%\t%s\n",
+			quote.Bid, quote.Ask, quote.Last, quote.Volume, quote.OpenInterest, 100*quote.ImpliedVolatility,
+			quote.Time.UTC().Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+// addTimeParameters parses the time flags named by the keys of flags and sets
+// the parameters they map to as RFC 3339 times. Unset flags are left out.
+func addTimeParameters(cmd *cli.Command, parameters map[string]string, flags map[string]string) error {
+	for flag, name := range flags {
+		value := cmd.String(flag)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := parseTime(value)
+		if err != nil {
+			return cli.Exit(fmt.Errorf("invalid %s: %w", flag, err), 1)
+		}
+		parameters[name] = parsed.Format(time.RFC3339)
+	}
+
+	return nil
+}
+
+// parseTime accepts an RFC 3339 time or a date, which is taken as midnight UTC.
+func parseTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	return time.ParseInLocation(time.DateOnly, value, time.UTC)
+}
+
+func formatNumber(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatPercent(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%+.2f%%", *value)
+}
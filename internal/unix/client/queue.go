@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	"github.com/zydee3/stockdb/internal/unix/server/handlers"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var queueCommand = cli.Command{
+	Name:        "queue",
+	Description: `Inspect and manage jobs waiting in the StockDB job queue.`,
+	Commands: []*cli.Command{
+		{
+			Name:        "ls",
+			Description: `List pending jobs in the order they will be run.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "collection", Aliases: []string{"c"}, Usage: "only list jobs of this collection"},
+				&cli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "only list jobs of this priority"},
+				&cli.IntFlag{Name: "limit", Aliases: []string{"n"}, Usage: "list at most this many jobs"},
+			},
+			Action: onQueueList,
+		},
+		{
+			Name:        "stats",
+			Description: `Show pending job counts per priority and per collection.`,
+			Action:      onQueueStats,
+		},
+		{
+			Name:        "drain",
+			Description: `Remove the pending jobs of a collection from the queue.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "collection",
+					Aliases:  []string{"c"},
+					Usage:    "collection whose pending jobs are removed",
+					Required: true,
+				},
+			},
+			Action: onQueueDrain,
+		},
+	},
+}
+
+func queueParameters(cmd *cli.Command) map[string]string {
+	parameters := make(map[string]string)
+
+	if collection := cmd.String("collection"); collection != "" {
+		parameters[handlers.ParameterCollection] = collection
+	}
+
+	if priority := cmd.String("priority"); priority != "" {
+		parameters[handlers.ParameterPriority] = priority
+	}
+
+	if limit := cmd.Int("limit"); limit > 0 {
+		parameters[handlers.ParameterLimit] = strconv.Itoa(limit)
+	}
+
+	return parameters
+}
+
+func onQueueList(_ context.Context, cmd *cli.Command) error {
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeQueueList,
+		Parameters: queueParameters(cmd),
+	}
+
+	list := &apitypes.QueueListResponse{}
+	if _, err := sendCommand(stockdbCmd, list); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tCOLLECTION\tPRIORITY\tSOURCE\tENDPOINT")
+	for _, job := range list.Jobs {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\n", job.ID, job.Collection, job.Priority, job.Source, job.Endpoint)
+	}
+
+	return writer.Flush()
+}
+
+func onQueueStats(_ context.Context, cmd *cli.Command) error {
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeQueueStats,
+		Parameters: make(map[string]string),
+	}
+
+	stats := &apitypes.QueueStatsResponse{}
+	if _, err := sendCommand(stockdbCmd, stats); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "TOTAL\t%d\n\n", stats.Total)
+
+	fmt.Fprintln(writer, "PRIORITY\tJOBS")
+	priorities := make([]int, 0, len(stats.ByPriority))
+	for priority := range stats.ByPriority {
+		priorities = append(priorities, priority)
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+	for _, priority := range priorities {
+		fmt.Fprintf(writer, "%d\t%d\n", priority, stats.ByPriority[priority])
+	}
+
+	fmt.Fprintln(writer, "\nCOLLECTION\tJOBS")
+	collections := make([]string, 0, len(stats.ByCollection))
+	for collection := range stats.ByCollection {
+		collections = append(collections, collection)
+	}
+	slices.Sort(collections)
+	for _, collection := range collections {
+		fmt.Fprintf(writer, "%s\t%d\n", collection, stats.ByCollection[collection])
+	}
+
+	return writer.Flush()
+}
+
+func onQueueDrain(_ context.Context, cmd *cli.Command) error {
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeQueueDrain,
+		Parameters: queueParameters(cmd),
+	}
+
+	drained := &apitypes.QueueDrainResponse{}
+	response, err := sendCommand(stockdbCmd, drained)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	fmt.Fprintln(cmd.Root().Writer, response.Message)
+
+	return nil
+}
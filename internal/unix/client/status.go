@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var statusCommand = cli.Command{
+	Name:        "status",
+	Description: `Show pending jobs and the circuit breaker of each provider endpoint.`,
+	Action:      onStatus,
+}
+
+func onStatus(_ context.Context, cmd *cli.Command) error {
+	stockdbCmd := messages.Command{
+		Type:       messages.CommandTypeStatus,
+		Parameters: make(map[string]string),
+	}
+
+	status := &apitypes.StatusResponse{}
+	if _, err := sendCommand(stockdbCmd, status); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	writer := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "PENDING JOBS\t%d\n\n", status.PendingJobs)
+
+	fmt.Fprintln(writer, "SOURCE\tENDPOINT\tCIRCUIT\tFAILURES\tOPENED\tRETRY AT")
+	for _, circuit := range status.Circuits {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+			circuit.Source,
+			circuit.Endpoint,
+			circuit.State,
+			circuit.Failures,
+			circuit.Requests,
+			formatTime(circuit.OpenedAt),
+			formatTime(circuit.RetryAt),
+		)
+	}
+
+	return writer.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Local().Format(time.DateTime)
+}
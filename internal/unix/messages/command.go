@@ -3,8 +3,16 @@ package messages
 type CommandType string
 
 const (
-	CommandTypeApply   CommandType = "apply"
-	CommandTypeUnknown CommandType = "unknown"
+	CommandTypeApply         CommandType = "apply"
+	CommandTypeQueueList     CommandType = "queue-list"
+	CommandTypeQueueStats    CommandType = "queue-stats"
+	CommandTypeQueueDrain    CommandType = "queue-drain"
+	CommandTypeHistory       CommandType = "history"
+	CommandTypeQueryEarnings CommandType = "query-earnings"
+	CommandTypeQueryBars     CommandType = "query-bars"
+	CommandTypeQueryOptions  CommandType = "query-options"
+	CommandTypeStatus        CommandType = "status"
+	CommandTypeUnknown       CommandType = "unknown"
 )
 
 type Command struct {
@@ -17,6 +25,22 @@ func NewCommandType(s string) CommandType {
 	switch s {
 	case "apply":
 		return CommandTypeApply
+	case "queue-list":
+		return CommandTypeQueueList
+	case "queue-stats":
+		return CommandTypeQueueStats
+	case "queue-drain":
+		return CommandTypeQueueDrain
+	case "history":
+		return CommandTypeHistory
+	case "query-earnings":
+		return CommandTypeQueryEarnings
+	case "query-bars":
+		return CommandTypeQueryBars
+	case "query-options":
+		return CommandTypeQueryOptions
+	case "status":
+		return CommandTypeStatus
 	default:
 		return CommandTypeUnknown
 	}
@@ -1,14 +1,48 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/config"
+	"github.com/zydee3/stockdb/internal/factory"
 	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
 )
 
-func OnApplyRequest(cmd messages.Command) messages.Response {
+func OnApplyRequest(manager *factory.Manager, cmd messages.Command) messages.Response {
+	if manager == nil {
+		return newErrorResponse(errors.New("job manager is not available"))
+	}
+
+	collection := &crd.DataCollection{}
+	if err := decodeData(cmd.Data, collection); err != nil {
+		return newErrorResponse(fmt.Errorf("invalid collection: %w", err))
+	}
+
+	// A full queue would otherwise hold the connection until it drains.
+	ctx, cancel := context.WithTimeout(context.Background(), config.ApplyTimeout)
+	defer cancel()
+
+	queued, err := manager.Submit(ctx, collection)
+	if err != nil {
+		if len(queued) > 0 {
+			err = fmt.Errorf("%w, after queueing %d jobs", err, len(queued))
+		}
+
+		return newErrorResponse(err)
+	}
+
+	jobIDs := make([]string, 0, len(queued))
+	for _, job := range queued {
+		jobIDs = append(jobIDs, job.ID)
+	}
+
 	return messages.Response{
 		Type:    messages.ResponseTypeSuccess,
-		Message: fmt.Sprintf("Received Apply Command: %s", cmd.Type.String()),
+		Message: fmt.Sprintf("Accepted collection %s: %d jobs queued", collection.GetName(), len(queued)),
+		Data:    apitypes.ApplyResponse{Collection: collection.GetName(), Jobs: jobIDs},
 	}
 }
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/zydee3/stockdb/internal/common/symbols"
+	"github.com/zydee3/stockdb/internal/factory"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/storage"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+)
+
+type RequestHandler func(cmd messages.Command) messages.Response
+
+// Dependencies holds the daemon state that request handlers operate on.
+type Dependencies struct {
+	Manager  *factory.Manager
+	JobQueue jobqueue.InspectableJobQueue
+	History  *history.Store
+	Breakers *breaker.Set
+	Store    *storage.Store
+	// Symbols resolves the symbols of queries to the IDs securities are
+	// stored under.
+	Symbols *symbols.Master
+}
+
+func NewRequestHandlers(deps Dependencies) map[messages.CommandType]RequestHandler {
+	return map[messages.CommandType]RequestHandler{
+		messages.CommandTypeApply: func(cmd messages.Command) messages.Response {
+			return OnApplyRequest(deps.Manager, cmd)
+		},
+		messages.CommandTypeQueueList: func(cmd messages.Command) messages.Response {
+			return OnQueueListRequest(deps.JobQueue, cmd)
+		},
+		messages.CommandTypeQueueStats: func(cmd messages.Command) messages.Response {
+			return OnQueueStatsRequest(deps.JobQueue, cmd)
+		},
+		messages.CommandTypeQueueDrain: func(cmd messages.Command) messages.Response {
+			return OnQueueDrainRequest(deps.JobQueue, cmd)
+		},
+		messages.CommandTypeHistory: func(cmd messages.Command) messages.Response {
+			return OnHistoryRequest(deps.History, cmd)
+		},
+		messages.CommandTypeQueryEarnings: func(cmd messages.Command) messages.Response {
+			return OnQueryEarningsRequest(deps.Store, deps.Symbols, cmd)
+		},
+		messages.CommandTypeQueryBars: func(cmd messages.Command) messages.Response {
+			return OnQueryBarsRequest(deps.Store, deps.Symbols, cmd)
+		},
+		messages.CommandTypeQueryOptions: func(cmd messages.Command) messages.Response {
+			return OnQueryOptionsRequest(deps.Store, deps.Symbols, cmd)
+		},
+		messages.CommandTypeStatus: func(cmd messages.Command) messages.Response {
+			return OnStatusRequest(deps.JobQueue, deps.Breakers, cmd)
+		},
+		messages.CommandTypeUnknown: OnUnknownRequest,
+	}
+}
+
+func newErrorResponse(err error) messages.Response {
+	return messages.Response{
+		Type:    messages.ResponseTypeError,
+		Message: err.Error(),
+	}
+}
+
+// decodeData converts command data, which arrives as generic JSON values, into
+// target.
+func decodeData(data any, target any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, target)
+}
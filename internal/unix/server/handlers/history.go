@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+const (
+	ParameterSymbol = "symbol"
+	ParameterSince  = "since"
+	ParameterFailed = "failed"
+)
+
+func OnHistoryRequest(store *history.Store, cmd messages.Command) messages.Response {
+	if store == nil {
+		return newErrorResponse(errors.New("job history is not available"))
+	}
+
+	query, err := parseHistoryQuery(cmd.Parameters)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.HistoryResponse{Entries: store.Query(query)},
+	}
+}
+
+func parseHistoryQuery(parameters map[string]string) (history.Query, error) {
+	query := history.Query{
+		Collection: parameters[ParameterCollection],
+		Symbol:     parameters[ParameterSymbol],
+	}
+
+	if value := parameters[ParameterSince]; value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("invalid since %q: %w", value, err)
+		}
+		query.Since = since
+	}
+
+	if value := parameters[ParameterFailed]; value != "" {
+		failed, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid failed %q: %w", value, err)
+		}
+		query.FailedOnly = failed
+	}
+
+	if value := parameters[ParameterLimit]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit %q: %w", value, err)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/symbols"
+	"github.com/zydee3/stockdb/internal/storage"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+const (
+	ParameterDays        = "days"
+	ParameterResolution  = "resolution"
+	ParameterFrom        = "from"
+	ParameterTo          = "to"
+	ParameterAdjust      = "adjust"
+	ParameterAt          = "at"
+	ParameterExpiresFrom = "expiresFrom"
+	ParameterExpiresTo   = "expiresTo"
+)
+
+func OnQueryEarningsRequest(store *storage.Store, master *symbols.Master, cmd messages.Command) messages.Response {
+	if store == nil {
+		return newErrorResponse(errors.New("data store is not available"))
+	}
+
+	query := storage.EarningsReactionQuery{
+		Symbol: resolveSymbol(master, cmd.Parameters[ParameterSymbol], time.Time{}),
+	}
+
+	if query.Symbol == "" {
+		return newErrorResponse(errors.New("earnings query requires a symbol"))
+	}
+
+	var err error
+	if query.Limit, err = parseIntParameter(cmd.Parameters, ParameterLimit); err != nil {
+		return newErrorResponse(err)
+	}
+
+	if query.Days, err = parseIntParameter(cmd.Parameters, ParameterDays); err != nil {
+		return newErrorResponse(err)
+	}
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.EarningsResponse{Reactions: store.EarningsReactions(query)},
+	}
+}
+
+func OnQueryBarsRequest(store *storage.Store, master *symbols.Master, cmd messages.Command) messages.Response {
+	if store == nil {
+		return newErrorResponse(errors.New("data store is not available"))
+	}
+
+	symbol := strings.ToUpper(cmd.Parameters[ParameterSymbol])
+	if symbol == "" {
+		return newErrorResponse(errors.New("bars query requires a symbol"))
+	}
+
+	resolution, err := records.ParseResolution(cmd.Parameters[ParameterResolution])
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	adjustment, err := storage.ParseAdjustment(cmd.Parameters[ParameterAdjust])
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	from, err := parseTimeParameter(cmd.Parameters, ParameterFrom)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	to, err := parseTimeParameter(cmd.Parameters, ParameterTo)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	symbol = resolveSymbol(master, symbol, to)
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.BarsResponse{
+			Bars: store.AdjustedBarsForSecurity(symbol, resolution, from, to, adjustment),
+		},
+	}
+}
+
+// OnQueryOptionsRequest returns the option chain of an underlying from the
+// latest snapshot collected, or the last one at or before the at parameter.
+func OnQueryOptionsRequest(store *storage.Store, master *symbols.Master, cmd messages.Command) messages.Response {
+	if store == nil {
+		return newErrorResponse(errors.New("data store is not available"))
+	}
+
+	symbol := cmd.Parameters[ParameterSymbol]
+	if symbol == "" {
+		return newErrorResponse(errors.New("options query requires a symbol"))
+	}
+
+	query := storage.OptionChainQuery{}
+
+	var err error
+	if query.At, err = parseTimeParameter(cmd.Parameters, ParameterAt); err != nil {
+		return newErrorResponse(err)
+	}
+
+	query.Underlying = resolveSymbol(master, symbol, query.At)
+
+	if query.ExpiresFrom, err = parseTimeParameter(cmd.Parameters, ParameterExpiresFrom); err != nil {
+		return newErrorResponse(err)
+	}
+
+	if query.ExpiresTo, err = parseTimeParameter(cmd.Parameters, ParameterExpiresTo); err != nil {
+		return newErrorResponse(err)
+	}
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.OptionChainResponse{Options: store.OptionChain(query)},
+	}
+}
+
+// resolveSymbol returns the ID of the security symbol denotes at time at,
+// which its records are stored under, or symbol in upper case if master does
+// not know it.
+func resolveSymbol(master *symbols.Master, symbol string, at time.Time) string {
+	if security, ok := master.Resolve(symbol, at); ok {
+		return security.ID
+	}
+
+	return strings.ToUpper(symbol)
+}
+
+// parseTimeParameter returns the RFC 3339 time parameter name, or the zero
+// time if it is not set.
+func parseTimeParameter(parameters map[string]string, name string) (time.Time, error) {
+	value := parameters[name]
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+
+	return parsed, nil
+}
+
+// parseIntParameter returns the non-negative integer parameter name, or zero if
+// it is not set.
+func parseIntParameter(parameters map[string]string, name string) (int, error) {
+	value := parameters[name]
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a non-negative integer", name, value)
+	}
+
+	return parsed, nil
+}
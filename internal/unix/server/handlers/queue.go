@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+const (
+	ParameterCollection = "collection"
+	ParameterPriority   = "priority"
+	ParameterLimit      = "limit"
+)
+
+var errQueueUnavailable = errors.New("job queue is not available")
+
+func OnQueueListRequest(queue jobqueue.InspectableJobQueue, cmd messages.Command) messages.Response {
+	if queue == nil {
+		return newErrorResponse(errQueueUnavailable)
+	}
+
+	filter, err := parseQueueFilter(cmd.Parameters)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.QueueListResponse{Jobs: toQueueJobs(queue.Peek(filter))},
+	}
+}
+
+func OnQueueStatsRequest(queue jobqueue.InspectableJobQueue, _ messages.Command) messages.Response {
+	if queue == nil {
+		return newErrorResponse(errQueueUnavailable)
+	}
+
+	stats := queue.Stats()
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.QueueStatsResponse{
+			Total:        stats.Total,
+			ByPriority:   stats.ByPriority,
+			ByCollection: stats.ByCollection,
+		},
+	}
+}
+
+func OnQueueDrainRequest(queue jobqueue.InspectableJobQueue, cmd messages.Command) messages.Response {
+	if queue == nil {
+		return newErrorResponse(errQueueUnavailable)
+	}
+
+	filter, err := parseQueueFilter(cmd.Parameters)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	// Draining the whole queue by accident is too easy to do from the command
+	// line, so a collection is required.
+	if filter.Collection == "" {
+		return newErrorResponse(errors.New("a collection is required to drain the queue"))
+	}
+
+	drained := queue.Drain(filter)
+
+	return messages.Response{
+		Type:    messages.ResponseTypeSuccess,
+		Message: fmt.Sprintf("Drained %d jobs from collection %s", len(drained), filter.Collection),
+		Data:    apitypes.QueueDrainResponse{Drained: toQueueJobs(drained)},
+	}
+}
+
+func parseQueueFilter(parameters map[string]string) (jobqueue.Filter, error) {
+	filter := jobqueue.Filter{
+		Collection: parameters[ParameterCollection],
+	}
+
+	if value := parameters[ParameterPriority]; value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid priority %q: %w", value, err)
+		}
+		filter.Priority = &priority
+	}
+
+	if value := parameters[ParameterLimit]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid limit %q: %w", value, err)
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func toQueueJobs(pending []jobs.Job) []apitypes.QueueJob {
+	queueJobs := make([]apitypes.QueueJob, 0, len(pending))
+	for _, job := range pending {
+		queueJob := apitypes.QueueJob{
+			ID:         job.ID,
+			Collection: job.GetCollection(),
+			Priority:   job.GetPriority(),
+		}
+
+		if job.CRD != nil {
+			queueJob.Source = job.CRD.GetSource().Type
+			queueJob.Endpoint = job.CRD.GetSource().Endpoint
+		}
+
+		queueJobs = append(queueJobs, queueJob)
+	}
+
+	return queueJobs
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+func OnStatusRequest(queue jobqueue.InspectableJobQueue, breakers *breaker.Set, _ messages.Command) messages.Response {
+	if queue == nil {
+		return newErrorResponse(errQueueUnavailable)
+	}
+
+	return messages.Response{
+		Type: messages.ResponseTypeSuccess,
+		Data: apitypes.StatusResponse{
+			PendingJobs: queue.Stats().Total,
+			Circuits:    breakers.Statuses(),
+		},
+	}
+}
@@ -16,8 +16,9 @@ import (
 )
 
 // StartServer initializes and runs the Unix socket server ctx provides
-// lifecycle control from the parent daemon.
-func StartServer(ctx context.Context, socketPath string) error {
+// lifecycle control from the parent daemon. deps is the daemon state exposed to
+// request handlers.
+func StartServer(ctx context.Context, socketPath string, deps handlers.Dependencies) error {
 	if socketPath == "" {
 		return errors.New("socket path is not set")
 	}
@@ -35,7 +36,7 @@ func StartServer(ctx context.Context, socketPath string) error {
 
 	logger.Infof("Socket server started on %s", socketPath)
 
-	return runServer(ctx, listener, socketPath)
+	return runServer(ctx, listener, socketPath, handlers.NewRequestHandlers(deps))
 }
 
 func createSocketDirectory(socketPath string) error {
@@ -82,7 +83,12 @@ func createSocketListener(socketPath string) (net.Listener, error) {
 	return listener, nil
 }
 
-func runServer(ctx context.Context, listener net.Listener, socketPath string) error {
+func runServer(
+	ctx context.Context,
+	listener net.Listener,
+	socketPath string,
+	requestHandlers map[messages.CommandType]handlers.RequestHandler,
+) error {
 	const (
 		drainTimeout = 30 * time.Second
 	)
@@ -97,7 +103,7 @@ func runServer(ctx context.Context, listener net.Listener, socketPath string) er
 	// Start accepting connections in a goroutine
 	acceptDone := make(chan error, 1)
 	go func() {
-		acceptDone <- acceptConnections(acceptCtx, listener, tracker)
+		acceptDone <- acceptConnections(acceptCtx, listener, tracker, requestHandlers)
 	}()
 
 	// Wait for either parent context cancellation or acceptor error
@@ -138,7 +144,12 @@ func runServer(ctx context.Context, listener net.Listener, socketPath string) er
 	return err
 }
 
-func acceptConnections(ctx context.Context, listener net.Listener, tracker *Tracker) error {
+func acceptConnections(
+	ctx context.Context,
+	listener net.Listener,
+	tracker *Tracker,
+	requestHandlers map[messages.CommandType]handlers.RequestHandler,
+) error {
 	for {
 		// Use acceptChan pattern to make listener.Accept() cancellable
 		acceptChan := make(chan net.Conn, 1)
@@ -161,7 +172,7 @@ func acceptConnections(ctx context.Context, listener net.Listener, tracker *Trac
 
 		case connection := <-acceptChan:
 			// New connection
-			go handleConnection(connection, tracker)
+			go handleConnection(connection, tracker, requestHandlers)
 
 		case err := <-acceptErrChan:
 			// If we're shutting down, ignore accept errors
@@ -175,12 +186,11 @@ func acceptConnections(ctx context.Context, listener net.Listener, tracker *Trac
 	}
 }
 
-func handleConnection(connection net.Conn, tracker *Tracker) {
-	var requestHandlers = map[messages.CommandType]func(messages.Command) messages.Response{
-		messages.CommandTypeApply:   handlers.OnApplyRequest,
-		messages.CommandTypeUnknown: handlers.OnUnknownRequest,
-	}
-
+func handleConnection(
+	connection net.Conn,
+	tracker *Tracker,
+	requestHandlers map[messages.CommandType]handlers.RequestHandler,
+) {
 	// Register connection with tracker and get completion function
 	cleanupFn := tracker.Track(connection.RemoteAddr().String())
 
@@ -198,7 +208,12 @@ func handleConnection(connection net.Conn, tracker *Tracker) {
 
 	logger.Infof("Received command: %+v", *cmd)
 
-	response := requestHandlers[cmd.Type](*cmd)
+	handler, exists := requestHandlers[cmd.Type]
+	if !exists {
+		handler = handlers.OnUnknownRequest
+	}
+
+	response := handler(*cmd)
 
 	// Send response back to client
 	if respError := sendResponse(connection, response); respError != nil {
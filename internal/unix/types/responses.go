@@ -1 +1,59 @@
 package apitypes
+
+import (
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+// QueueJob describes a pending job in the daemon's job queue.
+type QueueJob struct {
+	ID         string `json:"id"`
+	Collection string `json:"collection"`
+	Priority   int    `json:"priority"`
+	Source     string `json:"source,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+}
+
+type QueueListResponse struct {
+	Jobs []QueueJob `json:"jobs"`
+}
+
+type QueueStatsResponse struct {
+	Total        int            `json:"total"`
+	ByPriority   map[int]int    `json:"byPriority"`
+	ByCollection map[string]int `json:"byCollection"`
+}
+
+type QueueDrainResponse struct {
+	Drained []QueueJob `json:"drained"`
+}
+
+type HistoryResponse struct {
+	Entries []history.Entry `json:"entries"`
+}
+
+type ApplyResponse struct {
+	Collection string   `json:"collection"`
+	Jobs       []string `json:"jobs"`
+}
+
+type EarningsResponse struct {
+	Reactions []storage.EarningsReaction `json:"reactions"`
+}
+
+type BarsResponse struct {
+	Bars []records.Bar `json:"bars"`
+}
+
+type OptionChainResponse struct {
+	Options []storage.OptionChainEntry `json:"options"`
+}
+
+// StatusResponse summarizes the daemon: its pending jobs and the circuits of
+// the provider endpoints it collected from.
+type StatusResponse struct {
+	PendingJobs int              `json:"pendingJobs"`
+	Circuits    []breaker.Status `json:"circuits"`
+}
@@ -0,0 +1,217 @@
+package cboe_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/cboe"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testChainBody = `{
+	"timestamp": "2025-04-21 19:45:03",
+	"data": {
+		"symbol": "AAPL",
+		"current_price": 193.16,
+		"options": [
+			{"option": "AAPL250425C00190000", "bid": 5.1, "bid_size": 12, "ask": 5.3, "ask_size": 8,
+				"iv": 0.412, "open_interest": 15230, "volume": 4210, "last_trade_price": 5.2},
+			{"option": "AAPL250425P00190000", "bid": 1.9, "bid_size": 30, "ask": 2.0, "ask_size": 25,
+				"iv": 0.398, "open_interest": 9800, "volume": 3120, "last_trade_price": 1.95},
+			{"option": "AAPL251219C00250000", "bid": 4.0, "bid_size": 1, "ask": 4.2, "ask_size": 1,
+				"iv": 0.301, "open_interest": 40000, "volume": 12, "last_trade_price": 4.1},
+			{"option": "AAPL", "bid": 1, "ask": 1}
+		]
+	}
+}`
+
+func newTestProvider(transport http.RoundTripper) *cboe.Provider {
+	return cboe.NewProvider(cboe.NewHTTPClient(test.NewHTTPClient(transport), ""))
+}
+
+func TestProviderOptionChain(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testChainBody}
+	p := newTestProvider(transport)
+
+	request := provider.Request{Endpoint: cboe.EndpointOptionChain, Symbol: "aapl"}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	if url := transport.Requests()[0].URL.String(); url != cboe.DefaultBaseURL+"/options/AAPL.json" {
+		t.Errorf("Unexpected URL %s", url)
+	}
+	if payload.BytesFetched != int64(len(testChainBody)) {
+		t.Errorf("Expected %d bytes fetched, got %d", len(testChainBody), payload.BytesFetched)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 6 {
+		t.Fatalf("Expected a contract and a quote for each valid option, got %d records", len(normalized))
+	}
+
+	contract, ok := normalized[2].(records.OptionContract)
+	if !ok {
+		t.Fatalf("Expected an OptionContract, got %T", normalized[2])
+	}
+
+	expectedContract := records.OptionContract{
+		Symbol:     "AAPL250425P00190000",
+		Underlying: "AAPL",
+		Type:       records.OptionPut,
+		Strike:     190,
+		Expiration: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		Source:     cboe.SourceType,
+	}
+	if contract != expectedContract {
+		t.Errorf("Expected %+v, got %+v", expectedContract, contract)
+	}
+
+	quote, ok := normalized[3].(records.OptionQuote)
+	if !ok {
+		t.Fatalf("Expected an OptionQuote, got %T", normalized[3])
+	}
+
+	quotedAt := time.Date(2025, 4, 21, 19, 45, 3, 0, time.UTC)
+	if quote.Symbol != contract.Symbol || quote.Underlying != "AAPL" || !quote.Time.Equal(quotedAt) {
+		t.Errorf("Unexpected quote identity: %+v", quote)
+	}
+	if quote.Bid != 1.9 || quote.Ask != 2.0 || quote.Volume != 3120 || quote.OpenInterest != 9800 ||
+		quote.ImpliedVolatility != 0.398 || quote.UnderlyingPrice != 193.16 {
+		t.Errorf("Unexpected quote values: %+v", quote)
+	}
+
+	for _, record := range normalized {
+		if validateError := record.Validate(); validateError != nil {
+			t.Errorf("Expected valid records, got %v", validateError)
+		}
+	}
+}
+
+func TestProviderOptionChainExpiresWithin(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{Body: testChainBody})
+
+	request := provider.Request{
+		Endpoint:   cboe.EndpointOptionChain,
+		Symbol:     "AAPL",
+		Parameters: map[string]string{cboe.ParameterExpiresWithin: "30"},
+	}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 4 {
+		t.Errorf("Expected the December option to be dropped, got %d records", len(normalized))
+	}
+
+	request.Parameters[cboe.ParameterExpiresWithin] = "soon"
+	if _, fetchError := p.Fetch(context.Background(), request); fetchError == nil {
+		t.Error("Expected an invalid expiresWithin to fail")
+	}
+}
+
+func TestProviderOptionChainIndex(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testChainBody}
+	p := newTestProvider(transport)
+
+	request := provider.Request{Endpoint: cboe.EndpointOptionChain, Symbol: "^spx"}
+	if _, err := p.Fetch(context.Background(), request); err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	if path := transport.Requests()[0].URL.Path; path != "/api/global/delayed_quotes/options/_SPX.json" {
+		t.Errorf("Expected the index chain path, got %s", path)
+	}
+}
+
+func TestProviderOptionChainErrors(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{Status: http.StatusForbidden})
+
+	_, err := p.Fetch(context.Background(), provider.Request{Endpoint: cboe.EndpointOptionChain, Symbol: "NOPE"})
+
+	apiError := &cboe.APIError{}
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a 403 APIError, got %v", err)
+	}
+
+	_, err = p.Fetch(context.Background(), provider.Request{Endpoint: "QUOTES", Symbol: "AAPL"})
+	if !errors.Is(err, provider.ErrUnsupportedEndpoint) {
+		t.Errorf("Expected ErrUnsupportedEndpoint, got %v", err)
+	}
+}
+
+func TestParseOCCSymbol(t *testing.T) {
+	cases := map[string]records.OptionContract{
+		"SPXW250425C05000000": {
+			Symbol: "SPXW250425C05000000", Type: records.OptionCall, Strike: 5000,
+			Expiration: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		},
+		"BRK  260116P00412500": {
+			Symbol: "BRK260116P00412500", Type: records.OptionPut, Strike: 412.5,
+			Expiration: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for symbol, expected := range cases {
+		contract, err := records.ParseOCCSymbol(symbol)
+		if err != nil {
+			t.Errorf("ParseOCCSymbol(%q) failed: %v", symbol, err)
+			continue
+		}
+		if contract != expected {
+			t.Errorf("ParseOCCSymbol(%q): expected %+v, got %+v", symbol, expected, contract)
+		}
+	}
+
+	for _, symbol := range []string{"AAPL", "AAPL251319C00100000", "AAPL250425X00100000", "AAPL250425C0010000A"} {
+		if _, err := records.ParseOCCSymbol(symbol); err == nil {
+			t.Errorf("Expected ParseOCCSymbol(%q) to fail", symbol)
+		}
+	}
+}
+
+// TestProviderOptionChainSynthetic replays a fixture written from Cboe's
+// delayed quotes schema, with Black-Scholes quotes and greeks, not recorded
+// from the API.
+func TestProviderOptionChainSynthetic(t *testing.T) {
+	client := test.NewSyntheticClient(t, "testdata/synthetic/aapl-chain.json")
+	p := cboe.NewProvider(cboe.NewHTTPClient(client, cboe.DefaultBaseURL))
+
+	payload, err := p.Fetch(context.Background(), provider.Request{Endpoint: cboe.EndpointOptionChain, Symbol: "AAPL"})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 16 {
+		t.Fatalf("Expected a contract and a quote for each of 8 options, got %d records", len(normalized))
+	}
+
+	for _, record := range normalized {
+		if validateError := record.Validate(); validateError != nil {
+			t.Errorf("Expected valid records, got %v", validateError)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package common_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/clock"
+)
+
+const cacheETag = `"v1"`
+
+// validatingServer answers with a body per path, and with 304 to requests
+// that send its ETag.
+type validatingServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	etag        bool
+	requests    int
+	conditional int
+}
+
+func newValidatingServer(t *testing.T, etag bool) *validatingServer {
+	t.Helper()
+
+	s := &validatingServer{etag: etag}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		notModified := s.etag && r.Header.Get("If-None-Match") == cacheETag
+		if notModified {
+			s.conditional++
+		}
+		s.mu.Unlock()
+
+		if s.etag {
+			w.Header().Set("ETag", cacheETag)
+		}
+
+		if notModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Write([]byte(strings.Repeat("x", 100) + r.URL.Path))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *validatingServer) getCounts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests, s.conditional
+}
+
+func newCachingClient(t *testing.T, maxSize int64, fakeClock *clock.FakeClock) (httpUtil.HTTPClient, string) {
+	t.Helper()
+
+	directory := t.TempDir()
+	cache, err := httpUtil.OpenCache(directory, maxSize)
+	if err != nil {
+		t.Fatalf("OpenCache() failed: %v", err)
+	}
+
+	return httpUtil.HTTPClient{
+		Client:    &http.Client{},
+		Clock:     fakeClock,
+		Cache:     cache,
+		CacheTTLs: httpUtil.CacheTTLs{"profile": time.Hour, "series/*": time.Minute},
+	}, directory
+}
+
+// getBody requests url and returns the body and cache status of the response.
+func getBody(t *testing.T, client httpUtil.HTTPClient, url string) (string, string) {
+	t.Helper()
+
+	response, err := client.Get(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	return string(body), response.Header.Get(httpUtil.CacheStatusHeader)
+}
+
+func TestCacheTTLs(t *testing.T) {
+	ttls := httpUtil.CacheTTLs{
+		"profile":               time.Hour,
+		"observations":          time.Minute,
+		"series/observations":   time.Second,
+		"submissions/CIK*.json": 2 * time.Hour,
+	}
+
+	tests := []struct {
+		path     string
+		expected time.Duration
+		ok       bool
+	}{
+		{"/stable/profile", time.Hour, true},
+		{"/fred/series/observations", time.Second, true},
+		{"/other/observations", time.Minute, true},
+		{"/submissions/CIK0000320193.json", 2 * time.Hour, true},
+		{"/stable/profile/extra", 0, false},
+		{"/", 0, false},
+	}
+
+	for _, test := range tests {
+		ttl, ok := ttls.Get(test.path)
+		if ttl != test.expected || ok != test.ok {
+			t.Errorf("Get(%q) = %v, %t, expected %v, %t", test.path, ttl, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestHTTPClientCache(t *testing.T) {
+	start := time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC)
+
+	t.Run("ServesFreshResponses", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		fakeClock := clock.NewFakeClock(start)
+		client, _ := newCachingClient(t, 1<<20, fakeClock)
+
+		first, status := getBody(t, client, server.URL+"/profile?symbol=AAPL")
+		if status != "" {
+			t.Errorf("Expected the first response from the server, got %q", status)
+		}
+
+		fakeClock.Advance(59 * time.Minute)
+
+		second, status := getBody(t, client, server.URL+"/profile?symbol=AAPL")
+		if status != httpUtil.CacheStatusHit || second != first {
+			t.Errorf("Expected a cache hit with the same body, got %q %q", status, second)
+		}
+
+		if requests, _ := server.getCounts(); requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+
+		getBody(t, client, server.URL+"/profile?symbol=MSFT")
+		fakeClock.Advance(time.Minute)
+		getBody(t, client, server.URL+"/profile?symbol=AAPL")
+
+		if requests, _ := server.getCounts(); requests != 3 {
+			t.Errorf("Expected other queries and expired responses to be requested, got %d requests", requests)
+		}
+	})
+
+	t.Run("RevalidatesStaleResponses", func(t *testing.T) {
+		server := newValidatingServer(t, true)
+		fakeClock := clock.NewFakeClock(start)
+		client, _ := newCachingClient(t, 1<<20, fakeClock)
+
+		first, _ := getBody(t, client, server.URL+"/series/GDP")
+
+		fakeClock.Advance(2 * time.Minute)
+
+		second, status := getBody(t, client, server.URL+"/series/GDP")
+		if status != httpUtil.CacheStatusRevalidated || second != first {
+			t.Errorf("Expected the cached body to be revalidated, got %q %q", status, second)
+		}
+
+		if _, status = getBody(t, client, server.URL+"/series/GDP"); status != httpUtil.CacheStatusHit {
+			t.Errorf("Expected revalidation to restart the TTL, got %q", status)
+		}
+
+		// Endpoints without a TTL are revalidated on every request.
+		getBody(t, client, server.URL+"/news")
+		if _, status = getBody(t, client, server.URL+"/news"); status != httpUtil.CacheStatusRevalidated {
+			t.Errorf("Expected the response to be revalidated, got %q", status)
+		}
+
+		if requests, conditional := server.getCounts(); requests != 4 || conditional != 2 {
+			t.Errorf("Expected 4 requests, 2 conditional, got %d and %d", requests, conditional)
+		}
+	})
+
+	t.Run("DoesNotStoreUnvalidatedResponsesWithoutTTL", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		client, _ := newCachingClient(t, 1<<20, clock.NewFakeClock(start))
+
+		getBody(t, client, server.URL+"/news")
+		getBody(t, client, server.URL+"/news")
+
+		if requests, _ := server.getCounts(); requests != 2 || client.Cache.GetSize() != 0 {
+			t.Errorf("Expected nothing to be cached, got %d requests and %d bytes", requests, client.Cache.GetSize())
+		}
+	})
+
+	t.Run("EvictsLeastRecentlyUsed", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		fakeClock := clock.NewFakeClock(start)
+		client, _ := newCachingClient(t, 1<<20, fakeClock)
+
+		getBody(t, client, server.URL+"/a/profile")
+		entrySize := client.Cache.GetSize()
+
+		// Room for two entries.
+		client, directory := newCachingClient(t, 2*entrySize+entrySize/2, fakeClock)
+
+		for _, path := range []string{"/a/profile", "/b/profile", "/a/profile", "/c/profile"} {
+			getBody(t, client, server.URL+path)
+			fakeClock.Advance(time.Second)
+		}
+
+		if _, status := getBody(t, client, server.URL+"/a/profile"); status != httpUtil.CacheStatusHit {
+			t.Errorf("Expected the recently used entry to be kept, got %q", status)
+		}
+
+		if _, status := getBody(t, client, server.URL+"/b/profile"); status != "" {
+			t.Errorf("Expected the least recently used entry to be evicted, got %q", status)
+		}
+
+		if client.Cache.GetSize() > 2*entrySize+entrySize/2 {
+			t.Errorf("Expected the cache to fit its bound, got %d bytes", client.Cache.GetSize())
+		}
+
+		reopened, err := httpUtil.OpenCache(directory, entrySize)
+		if err != nil {
+			t.Fatalf("OpenCache() failed: %v", err)
+		}
+
+		if reopened.GetSize() > entrySize {
+			t.Errorf("Expected reopening with a smaller bound to evict, got %d bytes", reopened.GetSize())
+		}
+	})
+
+	t.Run("RefetchesEntriesRemovedFromDisk", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		client, directory := newCachingClient(t, 1<<20, clock.NewFakeClock(start))
+
+		getBody(t, client, server.URL+"/profile")
+
+		files, err := os.ReadDir(directory)
+		if err != nil || len(files) != 1 {
+			t.Fatalf("Expected a cache entry, got %v: %v", files, err)
+		}
+
+		if removeError := os.Remove(filepath.Join(directory, files[0].Name())); removeError != nil {
+			t.Fatalf("Remove() failed: %v", removeError)
+		}
+
+		if _, status := getBody(t, client, server.URL+"/profile"); status != "" {
+			t.Errorf("Expected the removed entry to be requested again, got %q", status)
+		}
+
+		if _, status := getBody(t, client, server.URL+"/profile"); status != httpUtil.CacheStatusHit {
+			t.Errorf("Expected the response to be cached again, got %q", status)
+		}
+
+		if requests, _ := server.getCounts(); requests != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("KeysResponsesByAPIKeyHeaders", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		client, _ := newCachingClient(t, 1<<20, clock.NewFakeClock(start))
+
+		for _, apiKey := range []string{"first-key", "second-key", "first-key"} {
+			request, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+				server.URL+"/profile?symbol=AAPL", nil)
+			if err != nil {
+				t.Fatalf("NewRequest() failed: %v", err)
+			}
+
+			request.Header.Set("apikey", apiKey)
+
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("Do() failed: %v", err)
+			}
+			response.Body.Close()
+		}
+
+		if requests, _ := server.getCounts(); requests != 2 {
+			t.Errorf("Expected a request per API key, got %d requests", requests)
+		}
+	})
+
+	t.Run("LetsClientsVetoStoring", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		client, _ := newCachingClient(t, 1<<20, clock.NewFakeClock(start))
+		client.Cacheable = func(_ *http.Response, body []byte) bool {
+			return !strings.HasSuffix(string(body), "/error/profile")
+		}
+
+		for range 2 {
+			getBody(t, client, server.URL+"/error/profile")
+			getBody(t, client, server.URL+"/data/profile")
+		}
+
+		if requests, _ := server.getCounts(); requests != 3 {
+			t.Errorf("Expected only the vetoed response to be requested again, got %d requests", requests)
+		}
+	})
+
+	t.Run("KeepsAPIKeysOutOfEntries", func(t *testing.T) {
+		server := newValidatingServer(t, false)
+		client, directory := newCachingClient(t, 1<<20, clock.NewFakeClock(start))
+
+		getBody(t, client, server.URL+"/profile?api_key="+testSecret)
+
+		files, err := os.ReadDir(directory)
+		if err != nil || len(files) != 1 {
+			t.Fatalf("Expected a cache entry, got %v: %v", files, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(directory, files[0].Name()))
+		if err != nil {
+			t.Fatalf("Failed to read entry: %v", err)
+		}
+
+		if strings.Contains(files[0].Name()+string(data), testSecret) {
+			t.Errorf("Expected the API key to be redacted from the entry:\n%s", data)
+		}
+	})
+}
+
+func TestHTTPClientCacheHitsDoNotSpendBudget(t *testing.T) {
+	server := newValidatingServer(t, false)
+	client, _ := newCachingClient(t, 1<<20, clock.NewFakeClock(time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC)))
+
+	// A single request per hour, which the first request spends.
+	client.Budget = rate.NewLimiter(rate.Every(time.Hour), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for range 10 {
+		response, err := client.Get(ctx, server.URL+"/profile")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		response.Body.Close()
+	}
+
+	if client.Budget.Tokens() >= 1 {
+		t.Errorf("Expected the first request to spend the budget, got %v tokens", client.Budget.Tokens())
+	}
+
+	if _, err := client.Get(ctx, server.URL+"/other"); err == nil {
+		t.Error("Expected a request to the server to wait for the spent budget")
+	}
+}
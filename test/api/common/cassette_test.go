@@ -0,0 +1,176 @@
+package common_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+const testSecret = "sk-live-5f2d9c"
+
+// newRecordedServer echoes the secret it was called with, as some APIs do in
+// their error messages, and sets a session cookie.
+func newRecordedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session="+testSecret)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/binary":
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		default:
+			w.Write([]byte(`{"symbol":"` + r.URL.Query().Get("symbol") + `","key":"` + r.URL.Query().Get("apikey") + `"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// bodyReader returns a function reading the body of a response, so requests
+// can be passed to it directly.
+func bodyReader(t *testing.T) func(*http.Response, error) string {
+	return func(response *http.Response, err error) string {
+		t.Helper()
+
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		defer response.Body.Close()
+
+		body, readError := io.ReadAll(response.Body)
+		if readError != nil {
+			t.Fatalf("Failed to read body: %v", readError)
+		}
+
+		return string(body)
+	}
+}
+
+func TestCassette(t *testing.T) {
+	server := newRecordedServer(t)
+	path := filepath.Join(t.TempDir(), "cassettes", "quotes.json")
+	ctx := context.Background()
+
+	recorder, err := httpUtil.OpenCassette(path, httpUtil.CassetteOptions{
+		Mode:    httpUtil.CassetteRecord,
+		Secrets: []string{testSecret},
+	})
+	if err != nil {
+		t.Fatalf("OpenCassette() failed: %v", err)
+	}
+
+	client := recorder.NewHTTPClient()
+	readBody := bodyReader(t)
+
+	recordedBodies := []string{
+		readBody(client.Get(ctx, server.URL+"/quote?symbol=AAPL&apikey="+testSecret)),
+		readBody(client.Get(ctx, server.URL+"/binary")),
+		readBody(client.Post(ctx, server.URL+"/echo", "application/json", strings.NewReader(`{"q":1}`))),
+	}
+
+	if !strings.Contains(recordedBodies[0], testSecret) {
+		t.Errorf("Expected the live response to be returned unredacted, got %s", recordedBodies[0])
+	}
+
+	if saveError := recorder.Save(); saveError != nil {
+		t.Fatalf("Save() failed: %v", saveError)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(saved), testSecret) {
+		t.Errorf("Expected the secret to be redacted from the cassette:\n%s", saved)
+	}
+
+	// The server is gone, so replays must come from the cassette.
+	server.Close()
+
+	t.Run("ReplaysOffline", func(t *testing.T) {
+		player, openError := httpUtil.OpenCassette(path, httpUtil.CassetteOptions{
+			Mode:    httpUtil.CassetteReplay,
+			Secrets: []string{"a-different-key"},
+		})
+		if openError != nil {
+			t.Fatalf("OpenCassette() failed: %v", openError)
+		}
+
+		client := player.NewHTTPClient()
+		readBody := bodyReader(t)
+
+		// Query order and the key value do not matter once keys are redacted.
+		quote := readBody(client.Get(ctx, server.URL+"/quote?apikey=a-different-key&symbol=AAPL"))
+		if quote != `{"symbol":"AAPL","key":"REDACTED"}` {
+			t.Errorf("Unexpected replayed body %s", quote)
+		}
+
+		if binary := readBody(client.Get(ctx, server.URL+"/binary")); binary != recordedBodies[1] {
+			t.Errorf("Expected the binary body to round trip, got %q", binary)
+		}
+
+		if len(player.Unplayed()) != 1 {
+			t.Errorf("Expected the POST to be unplayed, got %d unplayed", len(player.Unplayed()))
+		}
+
+		echo := readBody(client.Post(ctx, server.URL+"/echo", "application/json", strings.NewReader(`{"q":1}`)))
+		if echo != `{"q":1}` {
+			t.Errorf("Unexpected replayed body %s", echo)
+		}
+	})
+
+	t.Run("MatchesStrictly", func(t *testing.T) {
+		player, openError := httpUtil.OpenCassette(path, httpUtil.CassetteOptions{Mode: httpUtil.CassetteReplay})
+		if openError != nil {
+			t.Fatalf("OpenCassette() failed: %v", openError)
+		}
+
+		client := player.NewHTTPClient()
+		readBody := bodyReader(t)
+
+		misses := []func() (*http.Response, error){
+			func() (*http.Response, error) { return client.Get(ctx, server.URL+"/quote?symbol=MSFT&apikey=x") },
+			func() (*http.Response, error) {
+				return client.Post(ctx, server.URL+"/echo", "application/json", strings.NewReader(`{"q":2}`))
+			},
+			func() (*http.Response, error) { return client.Delete(ctx, server.URL+"/binary") },
+		}
+
+		for i, miss := range misses {
+			if _, missError := miss(); !errors.Is(missError, httpUtil.ErrCassetteMiss) {
+				t.Errorf("Request %d: expected ErrCassetteMiss, got %v", i, missError)
+			}
+		}
+
+		readBody(client.Get(ctx, server.URL+"/binary"))
+
+		// Each recording is played once.
+		if _, missError := client.Get(ctx, server.URL+"/binary"); !errors.Is(missError, httpUtil.ErrCassetteMiss) {
+			t.Errorf("Expected a second replay to miss, got %v", missError)
+		}
+	})
+
+	t.Run("RequiresCassetteToReplay", func(t *testing.T) {
+		_, openError := httpUtil.OpenCassette(filepath.Join(t.TempDir(), "missing.json"), httpUtil.CassetteOptions{
+			Mode: httpUtil.CassetteReplay,
+		})
+		if !errors.Is(openError, os.ErrNotExist) {
+			t.Errorf("Expected os.ErrNotExist, got %v", openError)
+		}
+	})
+}
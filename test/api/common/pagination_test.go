@@ -0,0 +1,191 @@
+package common_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+// pagedItems serves the items 0 to count-1 in pages of size, by page number,
+// offset or cursor, and records the positions requested.
+type pagedItems struct {
+	count     int
+	size      int
+	positions []httpUtil.PagePosition
+}
+
+func (p *pagedItems) fetchPage(start int) httpUtil.Page[int] {
+	items := []int{}
+	for i := start; i < min(start+p.size, p.count); i++ {
+		items = append(items, i)
+	}
+
+	page := httpUtil.Page[int]{Items: items}
+	if start+p.size < p.count {
+		page.NextCursor = strconv.Itoa(start + p.size)
+	}
+
+	return page
+}
+
+func (p *pagedItems) byNumber(_ context.Context, position httpUtil.PagePosition) (httpUtil.Page[int], error) {
+	p.positions = append(p.positions, position)
+	return p.fetchPage(position.Number * p.size), nil
+}
+
+func (p *pagedItems) byOffset(_ context.Context, position httpUtil.PagePosition) (httpUtil.Page[int], error) {
+	p.positions = append(p.positions, position)
+	return p.fetchPage(position.Offset), nil
+}
+
+func (p *pagedItems) byCursor(_ context.Context, position httpUtil.PagePosition) (httpUtil.Page[int], error) {
+	p.positions = append(p.positions, position)
+
+	start, _ := strconv.Atoi(position.Cursor)
+
+	return p.fetchPage(start), nil
+}
+
+func collect(t *testing.T, items func(yield func(int, error) bool)) []int {
+	t.Helper()
+
+	collected := []int{}
+	for item, err := range items {
+		if err != nil {
+			t.Fatalf("Paginate() failed: %v", err)
+		}
+
+		collected = append(collected, item)
+	}
+
+	return collected
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	all := []int{0, 1, 2, 3, 4, 5, 6}
+
+	t.Run("PageNumber", func(t *testing.T) {
+		items := &pagedItems{count: 7, size: 3}
+		pagination := httpUtil.PageNumberPagination{FirstPage: 0, PageSize: 3}
+
+		if collected := collect(t, httpUtil.Paginate(ctx, pagination, items.byNumber,
+			httpUtil.PageOptions[int]{})); !reflect.DeepEqual(collected, all) {
+			t.Errorf("Expected %v, got %v", all, collected)
+		}
+
+		expected := []httpUtil.PagePosition{{Number: 0}, {Number: 1}, {Number: 2}}
+		if !reflect.DeepEqual(items.positions, expected) {
+			t.Errorf("Expected pages %v, got %v", expected, items.positions)
+		}
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		items := &pagedItems{count: 6, size: 3}
+		pagination := httpUtil.OffsetPagination{Limit: 3}
+
+		if collected := collect(t, httpUtil.Paginate(ctx, pagination, items.byOffset,
+			httpUtil.PageOptions[int]{})); !reflect.DeepEqual(collected, all[:6]) {
+			t.Errorf("Expected %v, got %v", all[:6], collected)
+		}
+
+		// A full last page is followed by an empty one.
+		expected := []httpUtil.PagePosition{{Offset: 0}, {Offset: 3}, {Offset: 6}}
+		if !reflect.DeepEqual(items.positions, expected) {
+			t.Errorf("Expected offsets %v, got %v", expected, items.positions)
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		items := &pagedItems{count: 7, size: 3}
+
+		if collected := collect(t, httpUtil.Paginate(ctx, httpUtil.CursorPagination{}, items.byCursor,
+			httpUtil.PageOptions[int]{})); !reflect.DeepEqual(collected, all) {
+			t.Errorf("Expected %v, got %v", all, collected)
+		}
+
+		expected := []httpUtil.PagePosition{{Cursor: ""}, {Cursor: "3"}, {Cursor: "6"}}
+		if !reflect.DeepEqual(items.positions, expected) {
+			t.Errorf("Expected cursors %v, got %v", expected, items.positions)
+		}
+	})
+
+	t.Run("RepeatedCursor", func(t *testing.T) {
+		fetch := func(context.Context, httpUtil.PagePosition) (httpUtil.Page[int], error) {
+			return httpUtil.Page[int]{Items: []int{1}, NextCursor: "same"}, nil
+		}
+
+		collected := collect(t, httpUtil.Paginate(ctx, httpUtil.CursorPagination{FirstCursor: "same"}, fetch,
+			httpUtil.PageOptions[int]{}))
+		if len(collected) != 1 {
+			t.Errorf("Expected paging to stop on a repeated cursor, got %v", collected)
+		}
+	})
+
+	t.Run("MaxPages", func(t *testing.T) {
+		items := &pagedItems{count: 100, size: 3}
+		pagination := httpUtil.PageNumberPagination{PageSize: 3}
+
+		collected := collect(t, httpUtil.Paginate(ctx, pagination, items.byNumber, httpUtil.PageOptions[int]{MaxPages: 2}))
+		if !reflect.DeepEqual(collected, all[:6]) || len(items.positions) != 2 {
+			t.Errorf("Expected 2 pages, got %v from %d requests", collected, len(items.positions))
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		items := &pagedItems{count: 100, size: 3}
+		pagination := httpUtil.PageNumberPagination{PageSize: 3}
+		options := httpUtil.PageOptions[int]{Stop: func(item int) bool { return item >= 4 }}
+
+		collected := collect(t, httpUtil.Paginate(ctx, pagination, items.byNumber, options))
+		if !reflect.DeepEqual(collected, all[:4]) || len(items.positions) != 2 {
+			t.Errorf("Expected paging to stop before item 4, got %v from %d requests", collected, len(items.positions))
+		}
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		items := &pagedItems{count: 100, size: 3}
+		pagination := httpUtil.PageNumberPagination{PageSize: 3}
+
+		for item := range httpUtil.Paginate(ctx, pagination, items.byNumber, httpUtil.PageOptions[int]{}) {
+			if item == 1 {
+				break
+			}
+		}
+
+		if len(items.positions) != 1 {
+			t.Errorf("Expected a single page to be requested, got %d", len(items.positions))
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		failure := errors.New("page failed")
+		requests := 0
+		fetch := func(_ context.Context, position httpUtil.PagePosition) (httpUtil.Page[int], error) {
+			requests++
+			if position.Number == 1 {
+				return httpUtil.Page[int]{}, failure
+			}
+
+			return httpUtil.Page[int]{Items: []int{1, 2}}, nil
+		}
+
+		items, errs := 0, []error{}
+		for _, err := range httpUtil.Paginate(ctx, httpUtil.PageNumberPagination{PageSize: 2}, fetch,
+			httpUtil.PageOptions[int]{}) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			items++
+		}
+
+		if items != 2 || len(errs) != 1 || !errors.Is(errs[0], failure) || requests != 2 {
+			t.Errorf("Expected 2 items then the error, got %d items, %v after %d requests", items, errs, requests)
+		}
+	})
+}
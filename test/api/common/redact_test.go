@@ -0,0 +1,53 @@
+package common_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+type failingRoundTripper struct{}
+
+func (failingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{
+			url:      "https://api.example.com/fred/series?series_id=CPIAUCSL&api_key=" + testSecret,
+			expected: "https://api.example.com/fred/series?api_key=REDACTED&series_id=CPIAUCSL",
+		},
+		{
+			url:      "https://user:" + testSecret + "@api.example.com/quote?APIKEY=" + testSecret,
+			expected: "https://api.example.com/quote?APIKEY=REDACTED",
+		},
+		{url: "https://api.example.com/quote?symbol=AAPL", expected: "https://api.example.com/quote?symbol=AAPL"},
+	}
+
+	for _, test := range tests {
+		if redacted := httpUtil.RedactURL(test.url); redacted != test.expected {
+			t.Errorf("RedactURL(%q) = %q, expected %q", test.url, redacted, test.expected)
+		}
+	}
+}
+
+func TestHTTPClientRedactsErrors(t *testing.T) {
+	client := httpUtil.HTTPClient{Client: &http.Client{Transport: failingRoundTripper{}}, RetryCount: 0}
+
+	_, err := client.Get(context.Background(), "https://api.example.com/series?api_key="+testSecret)
+	if err == nil {
+		t.Fatal("Expected the request to fail")
+	}
+
+	if strings.Contains(err.Error(), testSecret) || !strings.Contains(err.Error(), "api_key=REDACTED") {
+		t.Errorf("Expected the key to be redacted from %q", err)
+	}
+}
@@ -0,0 +1,352 @@
+package common_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/clock"
+)
+
+// scriptedServer answers with statuses in order, repeating the last one, and
+// records the bodies of the requests it received.
+type scriptedServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	bodies     []string
+}
+
+func newScriptedServer(t *testing.T, statuses ...int) *scriptedServer {
+	t.Helper()
+
+	s := &scriptedServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		status := s.statuses[min(len(s.bodies), len(s.statuses)-1)]
+		s.bodies = append(s.bodies, string(body))
+		retryAfter := s.retryAfter
+		s.mu.Unlock()
+
+		if retryAfter != "" && status != http.StatusOK {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(http.StatusText(status)))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *scriptedServer) getBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.bodies...)
+}
+
+func newRetryingClient(retryCount int) httpUtil.HTTPClient {
+	return httpUtil.HTTPClient{
+		Client:        &http.Client{},
+		RetryCount:    retryCount,
+		RetryWaitTime: time.Millisecond,
+	}
+}
+
+// waitForTimer waits until the client is waiting on fakeClock.
+func waitForTimer(t *testing.T, fakeClock *clock.FakeClock) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for fakeClock.Timers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the client to wait for a retry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		retryCount int
+		statuses   []int
+		requests   int
+		status     int
+	}{
+		{"RetriesServerErrors", 3, []int{503, 502, 200}, 3, 200},
+		{"RetriesRateLimits", 3, []int{429, 200}, 2, 200},
+		{"ReturnsLastResponse", 2, []int{500}, 3, 500},
+		{"DoesNotRetryClientErrors", 3, []int{404, 200}, 1, 404},
+		{"ZeroRetryCountSendsOnce", 0, []int{200}, 1, 200},
+		{"ZeroRetryCountDoesNotRetry", 0, []int{503, 200}, 1, 503},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newScriptedServer(t, test.statuses...)
+			client := newRetryingClient(test.retryCount)
+
+			response, err := client.Get(ctx, server.URL)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			defer response.Body.Close()
+
+			body, _ := io.ReadAll(response.Body)
+			if response.StatusCode != test.status || string(body) != http.StatusText(test.status) {
+				t.Errorf("Expected the %d response, got %d %q", test.status, response.StatusCode, body)
+			}
+
+			if requests := len(server.getBodies()); requests != test.requests {
+				t.Errorf("Expected %d requests, got %d", test.requests, requests)
+			}
+		})
+	}
+
+	t.Run("RetriesTransportErrors", func(t *testing.T) {
+		mock := &MultiMockRoundTripper{Mocks: []MockRoundTripper{
+			{Resp: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK"))}},
+			{Err: errors.New("connection reset")},
+		}}
+
+		client := httpUtil.HTTPClient{Client: &http.Client{Transport: mock}, RetryCount: 1}
+
+		response, err := client.Get(ctx, "https://example.com")
+		if err != nil || response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected the retry to succeed, got %v", err)
+		}
+		response.Body.Close()
+	})
+
+	t.Run("RewindsBody", func(t *testing.T) {
+		server := newScriptedServer(t, 503, 200)
+		client := newRetryingClient(1)
+
+		response, err := client.Put(ctx, server.URL, "application/json", strings.NewReader(`{"q":1}`))
+		if err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+		response.Body.Close()
+
+		bodies := server.getBodies()
+		if len(bodies) != 2 || bodies[0] != `{"q":1}` || bodies[1] != `{"q":1}` {
+			t.Errorf("Expected the body to be sent twice, got %q", bodies)
+		}
+	})
+}
+
+func TestHTTPClientRetryIdempotency(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   httpUtil.RetryPolicy
+		header   string
+		body     io.Reader
+		requests int
+	}{
+		{name: "PostIsNotRetried", body: strings.NewReader("{}"), requests: 1},
+		{name: "PostWithIdempotencyKey", header: "order-1", body: strings.NewReader("{}"), requests: 2},
+		{
+			name:     "UnsafeMethodsAllowed",
+			policy:   httpUtil.RetryPolicy{RetryUnsafeMethods: true},
+			body:     strings.NewReader("{}"),
+			requests: 2,
+		},
+		{
+			// A body that cannot be read again cannot be resent.
+			name:     "BodyWithoutGetBody",
+			policy:   httpUtil.RetryPolicy{RetryUnsafeMethods: true},
+			body:     io.MultiReader(strings.NewReader("{}")),
+			requests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newScriptedServer(t, 503, 200)
+			client := newRetryingClient(1)
+			client.RetryPolicy = test.policy
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, test.body)
+			if err != nil {
+				t.Fatalf("NewRequest() failed: %v", err)
+			}
+			if test.header != "" {
+				request.Header.Set(httpUtil.IdempotencyKeyHeader, test.header)
+			}
+
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("Do() failed: %v", err)
+			}
+			response.Body.Close()
+
+			if requests := len(server.getBodies()); requests != test.requests {
+				t.Errorf("Expected %d requests, got %d", test.requests, requests)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetryWaits(t *testing.T) {
+	start := time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC)
+
+	t.Run("HonoursRetryAfter", func(t *testing.T) {
+		server := newScriptedServer(t, 429, 200)
+		server.retryAfter = "30"
+
+		fakeClock := clock.NewFakeClock(start)
+		client := newRetryingClient(1)
+		client.Clock = fakeClock
+
+		done := make(chan error, 1)
+		go func() {
+			response, err := client.Get(context.Background(), server.URL)
+			if err == nil {
+				response.Body.Close()
+			}
+			done <- err
+		}()
+
+		waitForTimer(t, fakeClock)
+		fakeClock.Advance(29 * time.Second)
+
+		if requests := len(server.getBodies()); requests != 1 {
+			t.Fatalf("Expected the retry to wait for Retry-After, got %d requests", requests)
+		}
+
+		fakeClock.Advance(time.Second)
+
+		if err := <-done; err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+
+		if requests := len(server.getBodies()); requests != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("ReturnsResponseForLongRetryAfter", func(t *testing.T) {
+		server := newScriptedServer(t, 429, 200)
+		server.retryAfter = "120"
+
+		client := newRetryingClient(1)
+		client.RetryPolicy.MaxWaitTime = time.Minute
+
+		response, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusTooManyRequests || len(server.getBodies()) != 1 {
+			t.Errorf("Expected the 429 to be returned at once, got %d after %d requests",
+				response.StatusCode, len(server.getBodies()))
+		}
+	})
+
+	t.Run("StopsWaitingOnCancel", func(t *testing.T) {
+		server := newScriptedServer(t, 503)
+
+		fakeClock := clock.NewFakeClock(start)
+		client := newRetryingClient(3)
+		client.RetryWaitTime = time.Hour
+		client.Clock = fakeClock
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Get(ctx, server.URL)
+			done <- err
+		}()
+
+		waitForTimer(t, fakeClock)
+		cancel()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected cancellation to stop the wait")
+		}
+
+		if requests := len(server.getBodies()); requests != 1 {
+			t.Errorf("Expected no retry after cancellation, got %d requests", requests)
+		}
+	})
+}
+
+func TestHTTPClientBackoff(t *testing.T) {
+	client := httpUtil.HTTPClient{
+		RetryWaitTime: 100 * time.Millisecond,
+		RetryPolicy:   httpUtil.RetryPolicy{MaxWaitTime: time.Second},
+	}
+
+	tests := []struct {
+		attempt int
+		lower   time.Duration
+		upper   time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+		{100, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, test := range tests {
+		seen := map[time.Duration]bool{}
+		for range 100 {
+			backoff := client.GetBackoff(test.attempt)
+			if backoff < test.lower || backoff > test.upper {
+				t.Fatalf("GetBackoff(%d) = %v, expected between %v and %v", test.attempt, backoff, test.lower, test.upper)
+			}
+			seen[backoff] = true
+		}
+
+		if len(seen) == 1 {
+			t.Errorf("Expected GetBackoff(%d) to be jittered", test.attempt)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Mon, 21 Apr 2025 16:01:30 GMT", 90 * time.Second, true},
+		{"Mon, 21 Apr 2025 15:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		wait, ok := httpUtil.ParseRetryAfter(test.value, now)
+		if wait != test.expected || ok != test.ok {
+			t.Errorf("ParseRetryAfter(%q) = %v, %t, expected %v, %t", test.value, wait, ok, test.expected, test.ok)
+		}
+	}
+}
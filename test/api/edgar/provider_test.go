@@ -0,0 +1,321 @@
+package edgar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/edgar"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testUserAgent = "StockDB Tests tests@example.com"
+
+// fixtureServer serves the EDGAR responses in testdata and records every
+// request it receives. The responses are synthetic: they are written from
+// SEC's documentation of submissions and companyfacts, trimmed to the fields
+// the provider reads, and not recorded from data.sec.gov.
+type fixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	times    []time.Time
+}
+
+func newFixtureServer(t *testing.T) *fixtureServer {
+	t.Helper()
+
+	fixtures := map[string]string{
+		"/files/company_tickers.json":                     "company_tickers.json",
+		"/submissions/CIK0000320193.json":                 "submissions_CIK0000320193.json",
+		"/submissions/CIK0000320193-submissions-001.json": "CIK0000320193-submissions-001.json",
+		"/api/xbrl/companyfacts/CIK0000320193.json":       "companyfacts_CIK0000320193.json",
+	}
+
+	server := &fixtureServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		server.requests = append(server.requests, r)
+		server.times = append(server.times, time.Now())
+		server.mu.Unlock()
+
+		// SEC rejects requests without a User-Agent.
+		if r.Header.Get("User-Agent") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join("testdata", fixture))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *fixtureServer) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func newTestClient(t *testing.T, server *fixtureServer, requestsPerSecond float64) *edgar.HTTPClient {
+	t.Helper()
+
+	client, err := edgar.NewHTTPClient(
+		httpUtil.HTTPClient{Client: server.Client(), RetryCount: 0},
+		edgar.ClientOptions{
+			UserAgent:         testUserAgent,
+			BaseURL:           server.URL,
+			TickersURL:        server.URL + "/files/company_tickers.json",
+			RequestsPerSecond: requestsPerSecond,
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewHTTPClient() failed: %v", err)
+	}
+
+	return client
+}
+
+// newSyntheticProvider replays testdata/synthetic/<name>.json, which fails the
+// test unless every request of the fixture is made. The fixtures are written
+// from SEC's documentation, not recorded from data.sec.gov.
+func newSyntheticProvider(t *testing.T, name string) *edgar.Provider {
+	t.Helper()
+
+	client := test.NewSyntheticClient(t, filepath.Join("testdata", "synthetic", name+".json"))
+
+	edgarClient, err := edgar.NewHTTPClient(client, edgar.ClientOptions{UserAgent: testUserAgent})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() failed: %v", err)
+	}
+
+	return edgar.NewProvider(edgarClient)
+}
+
+func fetchAndNormalize(t *testing.T, p *edgar.Provider, request provider.Request) []records.Record {
+	t.Helper()
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	for _, record := range normalized {
+		if err = record.Validate(); err != nil {
+			t.Errorf("Expected %T to be valid: %v", record, err)
+		}
+	}
+
+	return normalized
+}
+
+func TestNewHTTPClientRequiresUserAgent(t *testing.T) {
+	_, err := edgar.NewHTTPClient(httpUtil.HTTPClient{Client: http.DefaultClient}, edgar.ClientOptions{UserAgent: " "})
+	if err == nil {
+		t.Error("Expected an error without a User-Agent")
+	}
+}
+
+func TestLookupCIK(t *testing.T) {
+	server := newFixtureServer(t)
+	client := newTestClient(t, server, 0)
+
+	cik, err := client.LookupCIK(context.Background(), "aapl")
+	if err != nil || cik != "0000320193" {
+		t.Errorf("Expected CIK 0000320193, got %q (%v)", cik, err)
+	}
+
+	if _, err = client.LookupCIK(context.Background(), "NOPE"); !errors.Is(err, edgar.ErrUnknownTicker) {
+		t.Errorf("Expected ErrUnknownTicker, got %v", err)
+	}
+
+	// The ticker list is only downloaded once.
+	if requests := len(server.Requests()); requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestProviderFilings(t *testing.T) {
+	p := newSyntheticProvider(t, "filings")
+
+	normalized := fetchAndNormalize(t, p, provider.Request{
+		Endpoint:   edgar.EndpointFilings,
+		Symbol:     "AAPL",
+		Parameters: map[string]string{edgar.ParameterForms: "10-K, 10-Q"},
+		From:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	if len(normalized) != 1 {
+		t.Fatalf("Expected the 10-Q filed in the window, got %d filings", len(normalized))
+	}
+
+	expected := records.Filing{
+		Symbol:          "AAPL",
+		CIK:             "0000320193",
+		AccessionNumber: "0000320193-25-000008",
+		Form:            "10-Q",
+		FilingDate:      time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		ReportDate:      time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC),
+		AcceptedAt:      time.Date(2025, 1, 31, 18, 1, 30, 0, time.UTC),
+		PrimaryDocument: "aapl-20241228.htm",
+		Source:          edgar.SourceType,
+	}
+
+	if filing, ok := normalized[0].(records.Filing); !ok || filing != expected {
+		t.Errorf("Expected %+v, got %+v", expected, normalized[0])
+	}
+}
+
+func TestProviderFilingsPagesOlderFiles(t *testing.T) {
+	server := newFixtureServer(t)
+	p := edgar.NewProvider(newTestClient(t, server, 0))
+
+	request := provider.Request{
+		Endpoint:   edgar.EndpointFilings,
+		Symbol:     "AAPL",
+		Parameters: map[string]string{edgar.ParameterForms: "10-Q"},
+		From:       time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	normalized := fetchAndNormalize(t, p, request)
+	if len(normalized) != 2 {
+		t.Fatalf("Expected the 10-Qs of the recent filings and the older file, got %d filings", len(normalized))
+	}
+
+	if filing, _ := normalized[1].(records.Filing); filing.AccessionNumber != "0000320193-24-000081" {
+		t.Errorf("Expected the older 10-Q last, got %+v", normalized[1])
+	}
+
+	// The window starts in the recent filings, so the older file is not
+	// requested.
+	request.From = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := len(server.Requests())
+	fetchAndNormalize(t, p, request)
+
+	for _, r := range server.Requests()[before:] {
+		if strings.Contains(r.URL.Path, "-submissions-") {
+			t.Errorf("Expected paging to stop at the window start, got a request for %s", r.URL.Path)
+		}
+	}
+
+	for _, r := range server.Requests() {
+		if agent := r.Header.Get("User-Agent"); agent != testUserAgent {
+			t.Errorf("Expected User-Agent %q on %s, got %q", testUserAgent, r.URL.Path, agent)
+		}
+	}
+}
+
+func TestProviderFacts(t *testing.T) {
+	p := newSyntheticProvider(t, "facts")
+
+	all := fetchAndNormalize(t, p, provider.Request{Endpoint: edgar.EndpointFacts, Symbol: "AAPL"})
+	if len(all) != 4 {
+		t.Errorf("Expected 4 facts, got %d", len(all))
+	}
+
+	normalized := fetchAndNormalize(t, p, provider.Request{
+		Endpoint: edgar.EndpointFacts,
+		Symbol:   "AAPL",
+		Parameters: map[string]string{
+			edgar.ParameterConcepts: "us-gaap:RevenueFromContractWithCustomerExcludingAssessedTax,Assets",
+		},
+		From: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	if len(normalized) != 2 {
+		t.Fatalf("Expected the 2 facts for the quarter ending in the window, got %d", len(normalized))
+	}
+
+	facts := map[string]records.Fact{}
+	for _, record := range normalized {
+		fact, ok := record.(records.Fact)
+		if !ok {
+			t.Fatalf("Expected a Fact, got %T", record)
+		}
+		facts[fact.Concept] = fact
+	}
+
+	revenue := facts["RevenueFromContractWithCustomerExcludingAssessedTax"]
+	if revenue.Value != 124300000000 || revenue.Unit != "USD" || revenue.FiscalPeriod != "Q1" ||
+		!revenue.PeriodStart.Equal(time.Date(2024, 9, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected revenue fact: %+v", revenue)
+	}
+
+	if assets := facts["Assets"]; !assets.PeriodStart.IsZero() || assets.Taxonomy != "us-gaap" {
+		t.Errorf("Expected an instantaneous us-gaap fact, got %+v", assets)
+	}
+}
+
+func TestProviderUnknownTicker(t *testing.T) {
+	server := newFixtureServer(t)
+	p := edgar.NewProvider(newTestClient(t, server, 0))
+
+	_, err := p.Fetch(context.Background(), provider.Request{Endpoint: edgar.EndpointFilings, Symbol: "NOPE"})
+	if !errors.Is(err, edgar.ErrUnknownTicker) {
+		t.Errorf("Expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	const (
+		requestsPerSecond = 20
+		requests          = 5
+	)
+
+	server := newFixtureServer(t)
+	client := newTestClient(t, server, requestsPerSecond)
+
+	for range requests {
+		if _, err := client.Submissions(context.Background(), "0000320193"); err != nil {
+			t.Fatalf("Submissions() failed: %v", err)
+		}
+	}
+
+	server.mu.Lock()
+	elapsed := server.times[len(server.times)-1].Sub(server.times[0])
+	server.mu.Unlock()
+
+	minimum := time.Duration(requests-1) * time.Second / requestsPerSecond
+	if elapsed < minimum*9/10 {
+		t.Errorf("Expected %d requests to take at least %s, took %s", requests, minimum, elapsed)
+	}
+}
+
+func TestClientHTTPError(t *testing.T) {
+	server := newFixtureServer(t)
+	client := newTestClient(t, server, 0)
+
+	_, err := client.CompanyFacts(context.Background(), "0000000001")
+
+	var apiError *edgar.APIError
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 APIError, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "companyfacts") {
+		t.Errorf("Expected the error to name the request, got %q", err)
+	}
+}
@@ -0,0 +1,331 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/zydee3/stockdb/internal/api/file"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+const testCSV = `Ticker,Timestamp,Open,High,Low,Close,Volume
+AAPL,2025-01-02 09:30:00,248.93,249.10,248.50,248.70,"1,200,000"
+MSFT,2025-01-02 09:30:00,425.53,426.07,425.10,425.80,300000
+AAPL,2025-01-02 09:31:00,248.70,248.90,248.20,248.30,900000
+AAPL,2025-01-02 09:32:00,not-a-price,248.90,248.20,248.30,900000
+AAPL,2025-01-02 09:33:00,248.30,248.60,248.00,248.50,700000
+`
+
+func writeFile(t *testing.T, path string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+}
+
+func newTestProvider(t *testing.T) *file.Provider {
+	t.Helper()
+
+	progress, err := file.OpenProgress("")
+	if err != nil {
+		t.Fatalf("OpenProgress() failed: %v", err)
+	}
+
+	return file.NewProvider(progress)
+}
+
+// streamBars streams request and normalizes every batch like a worker does.
+func streamBars(p *file.Provider, request provider.Request, fail func(batch int) error) ([]records.Bar, error) {
+	bars := []records.Bar{}
+	batches := 0
+
+	err := p.Stream(context.Background(), request, func(payload *provider.Payload) error {
+		batches++
+		if fail != nil {
+			if err := fail(batches); err != nil {
+				return err
+			}
+		}
+
+		normalized, err := p.Normalize(payload)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range normalized {
+			bar, _ := record.(records.Bar)
+			bars = append(bars, bar)
+		}
+
+		return nil
+	})
+
+	return bars, err
+}
+
+func TestProviderCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "minute.csv")
+	writeFile(t, path, testCSV)
+
+	request := provider.Request{
+		Endpoint: file.EndpointBars,
+		Symbol:   "AAPL",
+		Parameters: map[string]string{
+			file.ParameterPath:       path,
+			file.ParameterTimezone:   "America/New_York",
+			file.ParameterResolution: "1min",
+			"column.symbol":          "Ticker",
+			"column.time":            "Timestamp",
+			"column.open":            "Open",
+			"column.high":            "High",
+			"column.low":             "Low",
+			"column.close":           "Close",
+			"column.volume":          "Volume",
+		},
+	}
+
+	bars, err := streamBars(newTestProvider(t), request, nil)
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+
+	// The MSFT row belongs to another target and the malformed row is skipped.
+	if len(bars) != 3 {
+		t.Fatalf("Expected 3 bars, got %d: %+v", len(bars), bars)
+	}
+
+	first := bars[0]
+	if !first.Time.Equal(time.Date(2025, 1, 2, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first bar at 14:30 UTC, got %v", first.Time)
+	}
+	if first.Symbol != "AAPL" || first.Open != 248.93 || first.Volume != 1200000 || first.Source != file.SourceType {
+		t.Errorf("Unexpected first bar: %+v", first)
+	}
+
+	for _, bar := range bars {
+		if err := bar.Validate(); err != nil {
+			t.Errorf("Validate() failed: %v", err)
+		}
+	}
+}
+
+func TestProviderJSONLPerSymbol(t *testing.T) {
+	directory := t.TempDir()
+	writeFile(t, filepath.Join(directory, "AAPL", "2024.jsonl"),
+		`{"t": "2024-12-31", "o": 250.0, "h": 253.0, "l": 249.0, "c": 250.4, "v": 39480700}`+"\n")
+	writeFile(t, filepath.Join(directory, "AAPL", "2025.jsonl"),
+		`{"t": "2025-01-02", "o": 248.9, "h": 249.1, "l": 241.8, "c": 243.8, "v": 55740700}`+"\n"+
+			`{"t": "2025-01-03", "o": 243.3, "h": 244.1, "l": 241.8, "c": 243.3, "v": null}`+"\n")
+
+	request := provider.Request{
+		Endpoint: file.EndpointBars,
+		Symbol:   "AAPL",
+		From:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Parameters: map[string]string{
+			file.ParameterPath: filepath.Join(directory, "{symbol}", "*.jsonl"),
+			"column.time":      "t",
+			"column.open":      "o",
+			"column.high":      "h",
+			"column.low":       "l",
+			"column.close":     "c",
+			"column.volume":    "v",
+		},
+	}
+
+	bars, err := streamBars(newTestProvider(t), request, nil)
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+
+	// The 2024 bar is outside the window.
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 bars, got %d: %+v", len(bars), bars)
+	}
+
+	if !bars[0].Time.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)) || bars[1].Volume != 0 {
+		t.Errorf("Unexpected bars: %+v", bars)
+	}
+}
+
+// parquetBar is a row of the daily bars Parquet file TestProviderParquet
+// writes. Date is a DATE column of days since the Unix epoch.
+type parquetBar struct {
+	Symbol string   `parquet:"symbol,dict"`
+	Date   int32    `parquet:"date,date"`
+	Open   float64  `parquet:"open"`
+	High   float64  `parquet:"high"`
+	Low    float64  `parquet:"low"`
+	Close  float64  `parquet:"close"`
+	Volume *float64 `parquet:"volume,optional"`
+}
+
+func TestProviderParquet(t *testing.T) {
+	day := func(d int) int32 {
+		return int32(time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC).Unix() / int64(24*time.Hour/time.Second))
+	}
+	volume := func(v float64) *float64 {
+		return &v
+	}
+
+	// Two row groups of AAPL and MSFT daily bars, with dictionary encoded
+	// symbols and a null volume.
+	path := filepath.Join(t.TempDir(), "bars.parquet")
+	output, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	writer := parquet.NewGenericWriter[parquetBar](output, parquet.Compression(&parquet.Snappy))
+	rowGroups := [][]parquetBar{
+		{
+			{"AAPL", day(2), 248.93, 249.1, 241.82, 243.85, volume(55740700)},
+			{"MSFT", day(2), 425.53, 426.07, 414.85, 418.58, volume(16896500)},
+			{"AAPL", day(3), 243.36, 244.18, 241.89, 243.36, nil},
+		},
+		{
+			{"MSFT", day(3), 421.08, 424.03, 419.54, 423.35, volume(16662900)},
+			{"AAPL", day(6), 244.31, 247.33, 243.2, 245.00, volume(45045600)},
+		},
+	}
+
+	for _, rows := range rowGroups {
+		if _, writeError := writer.Write(rows); writeError != nil {
+			t.Fatalf("Write() failed: %v", writeError)
+		}
+
+		if flushError := writer.Flush(); flushError != nil {
+			t.Fatalf("Flush() failed: %v", flushError)
+		}
+	}
+
+	if closeError := errors.Join(writer.Close(), output.Close()); closeError != nil {
+		t.Fatalf("Close() failed: %v", closeError)
+	}
+
+	request := provider.Request{
+		Endpoint: file.EndpointBars,
+		Symbol:   "AAPL",
+		Parameters: map[string]string{
+			file.ParameterPath: path,
+			"column.symbol":    "symbol",
+			"column.time":      "date",
+		},
+	}
+
+	bars, err := streamBars(newTestProvider(t), request, nil)
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+
+	expected := []struct {
+		day    int
+		close  float64
+		volume float64
+	}{
+		{2, 243.85, 55740700},
+		{3, 243.36, 0},
+		{6, 245.00, 45045600},
+	}
+
+	if len(bars) != len(expected) {
+		t.Fatalf("Expected %d bars, got %d: %+v", len(expected), len(bars), bars)
+	}
+
+	for i, bar := range bars {
+		want := expected[i]
+		if !bar.Time.Equal(time.Date(2025, 1, want.day, 0, 0, 0, 0, time.UTC)) || bar.Close != want.close ||
+			bar.Volume != want.volume {
+			t.Errorf("Unexpected bar %d: %+v", i, bar)
+		}
+	}
+}
+
+func TestProviderResumesImport(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "daily.csv")
+	progressPath := filepath.Join(directory, "state", "imports.json")
+
+	writeFile(t, path, `time,open,high,low,close
+2025-01-02,10,11,9,10
+2025-01-03,10,11,9,10
+2025-01-06,10,11,9,10
+2025-01-07,10,11,9,10
+2025-01-08,10,11,9,10
+`)
+
+	request := provider.Request{
+		Endpoint: file.EndpointBars,
+		Symbol:   "AAPL",
+		Parameters: map[string]string{
+			file.ParameterPath:      path,
+			file.ParameterBatchSize: "2",
+		},
+	}
+
+	// Each attempt opens the saved progress again, like a restarted daemon.
+	open := func() *file.Provider {
+		progress, err := file.OpenProgress(progressPath)
+		if err != nil {
+			t.Fatalf("OpenProgress() failed: %v", err)
+		}
+
+		return file.NewProvider(progress)
+	}
+
+	// The import is interrupted while writing its second batch.
+	interrupted := errors.New("interrupted")
+	bars, err := streamBars(open(), request, func(batch int) error {
+		if batch == 2 {
+			return interrupted
+		}
+		return nil
+	})
+	if !errors.Is(err, interrupted) || len(bars) != 2 {
+		t.Fatalf("Expected the import to stop after 2 bars, got %d bars and %v", len(bars), err)
+	}
+
+	saved, err := os.ReadFile(progressPath)
+	if err != nil || !strings.Contains(string(saved), `"rows": 2`) {
+		t.Fatalf("Expected the progress of the first batch to be saved, got %s (%v)", saved, err)
+	}
+
+	bars, err = streamBars(open(), request, nil)
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+
+	if len(bars) != 3 || !bars[0].Time.Equal(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected the import to resume at the third row, got %+v", bars)
+	}
+
+	// A finished import is not read again.
+	bars, err = streamBars(open(), request, nil)
+	if err != nil || len(bars) != 0 {
+		t.Errorf("Expected a finished import to be skipped, got %d bars and %v", len(bars), err)
+	}
+}
+
+func TestProviderNoMatchingFiles(t *testing.T) {
+	request := provider.Request{
+		Endpoint:   file.EndpointBars,
+		Symbol:     "AAPL",
+		Parameters: map[string]string{file.ParameterPath: filepath.Join(t.TempDir(), "*.csv")},
+	}
+
+	if _, err := streamBars(newTestProvider(t), request, nil); err == nil {
+		t.Error("Expected an import without files to fail")
+	}
+}
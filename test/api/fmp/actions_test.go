@@ -0,0 +1,89 @@
+package fmp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+// normalizeCassette fetches request from the cassette name and normalizes
+// the response.
+func normalizeCassette(t *testing.T, name string, request provider.Request) []records.Record {
+	t.Helper()
+
+	p := newSyntheticProvider(t, name)
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	for _, record := range normalized {
+		if err = record.Validate(); err != nil {
+			t.Errorf("Expected %T to be valid: %v", record, err)
+		}
+	}
+
+	return normalized
+}
+
+func TestProviderSplits(t *testing.T) {
+	normalized := normalizeCassette(t, "splits", provider.Request{
+		Endpoint: fmp.EndpointSplits,
+		Symbol:   "NVDA",
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	if len(normalized) != 1 {
+		t.Fatalf("Expected only the split in the window, got %d", len(normalized))
+	}
+
+	expected := records.Split{
+		Symbol:      "NVDA",
+		ExDate:      time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		Numerator:   10,
+		Denominator: 1,
+		Source:      fmp.SourceType,
+	}
+
+	if split, ok := normalized[0].(records.Split); !ok || split != expected || split.GetRatio() != 10 {
+		t.Errorf("Expected %+v, got %+v", expected, normalized[0])
+	}
+}
+
+func TestProviderDividends(t *testing.T) {
+	normalized := normalizeCassette(t, "dividends", provider.Request{
+		Endpoint: fmp.EndpointDividends,
+		Symbol:   "AAPL",
+	})
+
+	if len(normalized) != 2 {
+		t.Fatalf("Expected 2 dividends, got %d", len(normalized))
+	}
+
+	latest, ok := normalized[0].(records.Dividend)
+	if !ok {
+		t.Fatalf("Expected a Dividend, got %T", normalized[0])
+	}
+
+	if latest.Amount != 0.25 || !latest.PayDate.Equal(time.Date(2025, 2, 13, 0, 0, 0, 0, time.UTC)) ||
+		!latest.DeclarationDate.Equal(time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected dividend: %+v", latest)
+	}
+
+	// Dividends are stored as declared, since adjusting for splits is done
+	// when bars are queried.
+	oldest, ok := normalized[1].(records.Dividend)
+	if !ok || oldest.Amount != 0.095 || !oldest.PayDate.IsZero() {
+		t.Errorf("Unexpected dividend: %+v", normalized[1])
+	}
+}
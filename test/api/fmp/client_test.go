@@ -0,0 +1,201 @@
+package fmp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testPricesBody = `[
+	{
+		"symbol": "AAPL",
+		"date": "2025-02-03",
+		"open": 229.99,
+		"high": 231.83,
+		"low": 225.7,
+		"close": 228.01,
+		"volume": 73063301,
+		"change": -1.98,
+		"changePercent": -0.86,
+		"vwap": 228.38
+	}
+]`
+
+func TestClientPrices(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testPricesBody}
+	client := newTestClient(transport)
+
+	result, err := client.Prices(context.Background(), fmp.PriceQuery{
+		Symbol: "AAPL",
+		From:   time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Prices() failed: %v", err)
+	}
+
+	if len(result.Items) != 1 || result.BytesFetched != int64(len(testPricesBody)) {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	price := result.Items[0]
+	if price.Date != "2025-02-03" || price.Close != 228.01 || price.Volume != 73063301 {
+		t.Errorf("Unexpected price: %+v", price)
+	}
+
+	request := transport.Requests()[0]
+	if request.URL.Path != "/stable/historical-price-eod/full" {
+		t.Errorf("Unexpected path %q", request.URL.Path)
+	}
+
+	query := request.URL.Query()
+	if query.Get("symbol") != "AAPL" || query.Has("apikey") || query.Get("from") != "2025-02-02" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+
+	if request.Header.Get(fmp.APIKeyHeader) != "test-key" {
+		t.Errorf("Expected the API key in the %s header, got %q", fmp.APIKeyHeader, request.Header.Get(fmp.APIKeyHeader))
+	}
+
+	if query.Has("to") {
+		t.Errorf("Expected an open window to omit to, got %q", query.Get("to"))
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		statusCode int
+		message    string
+		noData     bool
+	}{
+		{
+			name:       "ErrorPayload",
+			status:     http.StatusOK,
+			body:       `{"Error Message": "Invalid API KEY. Please retry or visit our documentation."}`,
+			statusCode: http.StatusOK,
+			message:    "Invalid API KEY. Please retry or visit our documentation.",
+		},
+		{
+			name:       "ErrorStatus",
+			status:     http.StatusForbidden,
+			body:       `{"Error Message": "Exclusive Endpoint"}`,
+			statusCode: http.StatusForbidden,
+			message:    "Exclusive Endpoint",
+		},
+		{
+			name:       "ErrorStatusWithoutPayload",
+			status:     http.StatusBadGateway,
+			body:       "bad gateway",
+			statusCode: http.StatusBadGateway,
+			message:    "bad gateway",
+		},
+		{
+			name:   "EmptyResult",
+			status: http.StatusOK,
+			body:   `[]`,
+			noData: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(&test.RecordingRoundTripper{Status: tt.status, Body: tt.body})
+
+			_, err := client.Prices(context.Background(), fmp.PriceQuery{Symbol: "AAPL"})
+			if tt.noData {
+				if !errors.Is(err, fmp.ErrNoData) {
+					t.Errorf("Expected ErrNoData, got %v", err)
+				}
+				return
+			}
+
+			var apiError *fmp.APIError
+			if !errors.As(err, &apiError) {
+				t.Fatalf("Expected an APIError, got %v", err)
+			}
+
+			if apiError.StatusCode != tt.statusCode || apiError.Message != tt.message {
+				t.Errorf("Unexpected APIError: %+v", apiError)
+			}
+		})
+	}
+}
+
+func TestClientDoesNotCacheErrorPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		requests int
+	}{
+		{"ErrorPayload", `{"Error Message": "Limit Reach. Please upgrade your plan."}`, 2},
+		{"Data", testPricesBody, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := httpUtil.OpenCache(t.TempDir(), 1<<20)
+			if err != nil {
+				t.Fatalf("OpenCache() failed: %v", err)
+			}
+
+			transport := &test.RecordingRoundTripper{Body: tt.body}
+			httpClient := test.NewHTTPClient(transport)
+			httpClient.Cache = cache
+			httpClient.CacheTTLs = httpUtil.CacheTTLs{"historical-price-eod/full": time.Hour}
+			client := fmp.NewHTTPClient(httpClient, "test-key", "")
+
+			for range 2 {
+				client.Prices(context.Background(), fmp.PriceQuery{Symbol: "AAPL"})
+			}
+
+			if requests := len(transport.Requests()); requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func TestClientMalformedResponse(t *testing.T) {
+	client := newTestClient(&test.RecordingRoundTripper{Body: `[{"symbol": "AAPL", "close": "n/a"}]`})
+
+	_, err := client.Prices(context.Background(), fmp.PriceQuery{Symbol: "AAPL"})
+
+	var apiError *fmp.APIError
+	if err == nil || errors.As(err, &apiError) || errors.Is(err, fmp.ErrNoData) {
+		t.Errorf("Expected a decode error, got %v", err)
+	}
+}
+
+func TestClientCancelled(t *testing.T) {
+	client := newTestClient(&test.RecordingRoundTripper{Body: testNewsBody})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.News(ctx, fmp.NewsQuery{Symbols: []string{"AAPL"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	for _, endpoint := range []string{fmp.EndpointNews, fmp.EndpointPrices} {
+		entry, err := fmp.LookupEndpoint(endpoint)
+		if err != nil || entry.Path == "" {
+			t.Errorf("Expected %s in the catalog, got %+v (%v)", endpoint, entry, err)
+		}
+	}
+
+	if _, err := fmp.LookupEndpoint("QUOTES"); !errors.Is(err, provider.ErrUnsupportedEndpoint) {
+		t.Errorf("Expected ErrUnsupportedEndpoint, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package fmp_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testEarningsBody = `[
+	{
+		"symbol": "MSFT",
+		"date": "2025-07-29",
+		"epsActual": null,
+		"epsEstimated": 3.35,
+		"revenueActual": null,
+		"revenueEstimated": 73810000000,
+		"lastUpdated": "2025-04-30"
+	},
+	{
+		"symbol": "MSFT",
+		"date": "2025-04-30",
+		"epsActual": 3.46,
+		"epsEstimated": 3.22,
+		"revenueActual": 70066000000,
+		"revenueEstimated": 68480000000,
+		"lastUpdated": "2025-04-30"
+	}
+]`
+
+func TestProviderEarnings(t *testing.T) {
+	p := newSyntheticProvider(t, "earnings")
+
+	payload, err := p.Fetch(context.Background(), provider.Request{Endpoint: fmp.EndpointEarnings, Symbol: "MSFT"})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 2 {
+		t.Fatalf("Expected 2 earnings, got %d", len(normalized))
+	}
+
+	upcoming, ok := normalized[0].(records.Earnings)
+	if !ok {
+		t.Fatalf("Expected Earnings, got %T", normalized[0])
+	}
+
+	if upcoming.IsReported() || upcoming.EPSSurprisePercent != nil || upcoming.EPSEstimated == nil {
+		t.Errorf("Expected an upcoming report with only estimates, got %+v", upcoming)
+	}
+
+	reported, ok := normalized[1].(records.Earnings)
+	if !ok {
+		t.Fatalf("Expected Earnings, got %T", normalized[1])
+	}
+
+	if !reported.IsReported() || !reported.ReportDate.Equal(time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected reported earnings: %+v", reported)
+	}
+
+	expectedSurprise := (3.46 - 3.22) / 3.22 * 100
+	if reported.EPSSurprisePercent == nil || math.Abs(*reported.EPSSurprisePercent-expectedSurprise) > 1e-9 {
+		t.Errorf("Expected an EPS surprise of %.4f%%, got %v", expectedSurprise, reported.EPSSurprisePercent)
+	}
+
+	if reported.RevenueSurprisePercent == nil || *reported.RevenueSurprisePercent <= 0 {
+		t.Errorf("Expected a positive revenue surprise, got %v", reported.RevenueSurprisePercent)
+	}
+}
+
+func TestProviderEarningsWindow(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{Body: testEarningsBody})
+
+	payload, err := p.Fetch(context.Background(), provider.Request{
+		Endpoint: fmp.EndpointEarnings,
+		Symbol:   "MSFT",
+		To:       time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 1 {
+		t.Errorf("Expected only the report before the end of the window, got %d", len(normalized))
+	}
+}
@@ -0,0 +1,301 @@
+package fmptest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/fmp/fmptest"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+)
+
+func newTestServer(t *testing.T) *fmptest.Server {
+	t.Helper()
+
+	server := fmptest.NewServer()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestProvider(server *fmptest.Server, apiKey secrets.Value) *fmp.Provider {
+	client := httpUtil.HTTPClient{Client: &http.Client{}, RetryCount: 0}
+
+	return fmp.NewProvider(fmp.NewHTTPClient(client, apiKey, server.GetBaseURL()), nil)
+}
+
+func pricesRequest(symbol string) provider.Request {
+	return provider.Request{
+		Endpoint: fmp.EndpointPrices,
+		Symbol:   symbol,
+		From:     time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 4, 12, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestServerEndpoints(t *testing.T) {
+	server := newTestServer(t)
+	p := newTestProvider(server, fmptest.DefaultAPIKey)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		request provider.Request
+	}{
+		{"DailyPrices", pricesRequest("AAPL")},
+		{"IntradayPrices", provider.Request{
+			Endpoint:   fmp.EndpointPrices,
+			Symbol:     "AAPL",
+			Parameters: map[string]string{fmp.ParameterResolution: "5min"},
+			From:       time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2025, 4, 11, 0, 0, 0, 0, time.UTC),
+		}},
+		{"News", provider.Request{Endpoint: fmp.EndpointNews, Symbol: "MSFT"}},
+		{"IncomeStatement", provider.Request{Endpoint: fmp.EndpointIncomeStatement, Symbol: "MSFT"}},
+		{"QuarterlyBalanceSheet", provider.Request{
+			Endpoint:   fmp.EndpointBalanceSheet,
+			Symbol:     "MSFT",
+			Parameters: map[string]string{fmp.ParameterPeriod: "quarter"},
+		}},
+		{"CashFlow", provider.Request{Endpoint: fmp.EndpointCashFlow, Symbol: "MSFT"}},
+		{"Earnings", provider.Request{Endpoint: fmp.EndpointEarnings, Symbol: "NVDA"}},
+		{"Splits", provider.Request{Endpoint: fmp.EndpointSplits, Symbol: "NVDA"}},
+		{"Dividends", provider.Request{Endpoint: fmp.EndpointDividends, Symbol: "NVDA"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := p.Fetch(ctx, test.request)
+			if err != nil {
+				t.Fatalf("Fetch() failed: %v", err)
+			}
+
+			normalized, err := p.Normalize(payload)
+			if err != nil {
+				t.Fatalf("Normalize() failed: %v", err)
+			}
+
+			if len(normalized) == 0 {
+				t.Fatal("Expected records")
+			}
+
+			for _, record := range normalized {
+				if validateError := record.Validate(); validateError != nil {
+					t.Errorf("Invalid record %+v: %v", record, validateError)
+				}
+			}
+		})
+	}
+}
+
+func TestServerIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+
+	fetch := func() []records.Record {
+		server := newTestServer(t)
+		p := newTestProvider(server, fmptest.DefaultAPIKey)
+
+		payload, err := p.Fetch(ctx, pricesRequest("AAPL"))
+		if err != nil {
+			t.Fatalf("Fetch() failed: %v", err)
+		}
+
+		normalized, err := p.Normalize(payload)
+		if err != nil {
+			t.Fatalf("Normalize() failed: %v", err)
+		}
+
+		return normalized
+	}
+
+	first, second := fetch(), fetch()
+
+	// April 7 to 11 2025 is a single trading week.
+	if len(first) != 5 {
+		t.Fatalf("Expected 5 daily bars, got %d", len(first))
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected identical responses from separate servers:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RateLimit", func(t *testing.T) {
+		server := newTestServer(t)
+		p := newTestProvider(server, fmptest.DefaultAPIKey)
+		server.Inject(fmptest.Fault{Kind: fmptest.FaultRateLimit, Symbol: "AAPL", Times: 1})
+
+		_, err := p.Fetch(ctx, pricesRequest("AAPL"))
+
+		apiError := &fmp.APIError{}
+		if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("Expected a 429 APIError, got %v", err)
+		}
+
+		if _, retryError := p.Fetch(ctx, pricesRequest("AAPL")); retryError != nil {
+			t.Errorf("Expected the fault to be used up, got %v", retryError)
+		}
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		server := newTestServer(t)
+		p := newTestProvider(server, fmptest.DefaultAPIKey)
+		server.Inject(fmptest.Fault{Kind: fmptest.FaultServerError, StatusCode: http.StatusBadGateway})
+
+		for range 2 {
+			_, err := p.Fetch(ctx, pricesRequest("MSFT"))
+
+			apiError := &fmp.APIError{}
+			if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusBadGateway {
+				t.Fatalf("Expected a 502 APIError, got %v", err)
+			}
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		server := newTestServer(t)
+		p := newTestProvider(server, fmptest.DefaultAPIKey)
+		server.Inject(fmptest.Fault{Kind: fmptest.FaultMalformed, Path: "historical-price-eod/full"})
+
+		_, err := p.Fetch(ctx, pricesRequest("AAPL"))
+
+		apiError := &fmp.APIError{}
+		if err == nil || errors.As(err, &apiError) {
+			t.Fatalf("Expected a decode error, got %v", err)
+		}
+
+		splits := provider.Request{Endpoint: fmp.EndpointSplits, Symbol: "AAPL"}
+		if _, otherError := p.Fetch(ctx, splits); otherError != nil {
+			t.Errorf("Expected other paths to be unaffected, got %v", otherError)
+		}
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		const (
+			delay = 200 * time.Millisecond
+		)
+
+		server := newTestServer(t)
+		p := newTestProvider(server, fmptest.DefaultAPIKey)
+		server.Inject(fmptest.Fault{Kind: fmptest.FaultSlow, Delay: delay})
+
+		start := time.Now()
+		if _, err := p.Fetch(ctx, pricesRequest("AAPL")); err != nil {
+			t.Fatalf("Fetch() failed: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < delay {
+			t.Errorf("Expected the response to take at least %v, took %v", delay, elapsed)
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, delay/4)
+		defer cancel()
+
+		if _, err := p.Fetch(timeoutCtx, pricesRequest("AAPL")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the request to time out, got %v", err)
+		}
+	})
+
+	t.Run("InvalidAPIKey", func(t *testing.T) {
+		server := newTestServer(t)
+		p := newTestProvider(server, "wrong-key")
+
+		_, err := p.Fetch(ctx, pricesRequest("AAPL"))
+
+		apiError := &fmp.APIError{}
+		if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected a 401 APIError, got %v", err)
+		}
+	})
+}
+
+func TestServerRequests(t *testing.T) {
+	server := newTestServer(t)
+	p := newTestProvider(server, fmptest.DefaultAPIKey)
+	server.Inject(fmptest.Fault{Kind: fmptest.FaultRateLimit, Symbol: "MSFT", Times: 1})
+
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		p.Fetch(context.Background(), pricesRequest(symbol))
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+
+	if requests[0].Path != "historical-price-eod/full" || requests[0].Query.Get("symbol") != "AAPL" ||
+		requests[0].StatusCode != http.StatusOK {
+		t.Errorf("Unexpected first request: %+v", requests[0])
+	}
+
+	if requests[1].Query.Get("symbol") != "MSFT" || requests[1].StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Unexpected second request: %+v", requests[1])
+	}
+}
+
+func TestServerPagesNews(t *testing.T) {
+	server := newTestServer(t)
+	p := newTestProvider(server, fmptest.DefaultAPIKey)
+	ctx := context.Background()
+
+	// Two years of weekdays, three pages of news.
+	request := provider.Request{
+		Endpoint: fmp.EndpointNews,
+		Symbol:   "AAPL",
+		From:     time.Date(2023, 4, 17, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 4, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	payload, err := p.Fetch(ctx, request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	fetched, _ := payload.Data.([]fmp.NewsArticle)
+
+	batches := []int{}
+	err = p.Stream(ctx, request, func(payload *provider.Payload) error {
+		batch, _ := payload.Data.([]fmp.NewsArticle)
+		batches = append(batches, len(batch))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(batches, []int{250, 250, len(fetched) - 500}) {
+		t.Errorf("Expected %d articles in batches of a page, got %v", len(fetched), batches)
+	}
+
+	for i, r := range server.Requests()[3:] {
+		if page := r.Query.Get("page"); page != strconv.Itoa(i) || r.Query.Get("limit") != "250" {
+			t.Errorf("Expected request %d for page %d, got %v", i, i, r.Query)
+		}
+	}
+
+	// Pages are requested as the articles are consumed.
+	client := fmp.NewHTTPClient(httpUtil.HTTPClient{Client: &http.Client{}}, fmptest.DefaultAPIKey, server.GetBaseURL())
+	before := len(server.Requests())
+
+	for _, err := range client.AllNews(ctx, fmp.NewsQuery{Symbols: []string{"AAPL"}, From: request.From}, nil) {
+		if err != nil {
+			t.Fatalf("AllNews() failed: %v", err)
+		}
+		break
+	}
+
+	if requests := len(server.Requests()) - before; requests != 1 {
+		t.Errorf("Expected a single page to be requested, got %d requests", requests)
+	}
+}
@@ -0,0 +1,127 @@
+package fmp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+// testIntradayBody is newest first, as FMP returns it, and repeats a bar.
+const testIntradayBody = `[
+	{"date": "2025-03-10 09:35:00", "open": 107.5, "low": 106.9, "high": 108.2, "close": 107.1, "volume": 2100345},
+	{"date": "2025-03-10 09:30:00", "open": 108.0, "low": 107.2, "high": 108.9, "close": 107.5, "volume": 3512211},
+	{"date": "2025-03-10 09:30:00", "open": 108.0, "low": 107.2, "high": 108.9, "close": 107.5, "volume": 3512211}
+]`
+
+func fetchBars(t *testing.T, p *fmp.Provider, request provider.Request) []records.Bar {
+	t.Helper()
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	bars := make([]records.Bar, 0, len(normalized))
+	for _, record := range normalized {
+		bar, ok := record.(records.Bar)
+		if !ok {
+			t.Fatalf("Expected a Bar, got %T", record)
+		}
+
+		if err = bar.Validate(); err != nil {
+			t.Errorf("Expected bar to be valid: %v", err)
+		}
+
+		bars = append(bars, bar)
+	}
+
+	return bars
+}
+
+func TestProviderIntradayPrices(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testIntradayBody}
+	bars := fetchBars(t, newTestProvider(transport), provider.Request{
+		Endpoint:   fmp.EndpointPrices,
+		Symbol:     "NVDA",
+		Parameters: map[string]string{fmp.ParameterResolution: "5min"},
+		From:       time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC),
+		To:         time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC),
+	})
+
+	request := transport.Requests()[0]
+	if request.URL.Path != "/stable/historical-chart/5min" {
+		t.Errorf("Unexpected path %q", request.URL.Path)
+	}
+
+	if query := request.URL.Query(); query.Get("from") != "2025-03-10" || query.Get("to") != "2025-03-10" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 bars after removing the duplicate, got %d", len(bars))
+	}
+
+	// 09:30 in New York is 13:30 UTC once daylight saving time has started.
+	expected := []time.Time{
+		time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC),
+		time.Date(2025, 3, 10, 13, 35, 0, 0, time.UTC),
+	}
+
+	for i, bar := range bars {
+		if !bar.Time.Equal(expected[i]) || bar.Time.Location() != time.UTC {
+			t.Errorf("Expected bar %d at %v, got %v", i, expected[i], bar.Time)
+		}
+
+		if bar.Symbol != "NVDA" || bar.Resolution != records.Resolution5Min || bar.Source != fmp.SourceType {
+			t.Errorf("Unexpected bar: %+v", bar)
+		}
+	}
+}
+
+func TestProviderDailyPrices(t *testing.T) {
+	bars := fetchBars(t, newSyntheticProvider(t, "daily-prices"), provider.Request{
+		Endpoint: fmp.EndpointPrices,
+		Symbol:   "AAPL",
+		From:     time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC),
+	})
+
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 bars, got %d", len(bars))
+	}
+
+	bar := bars[0]
+	if !bar.Time.Equal(time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)) || bar.Resolution != records.Resolution1Day ||
+		bar.Close != 228.01 || bar.Volume != 73063301 {
+		t.Errorf("Unexpected daily bar: %+v", bar)
+	}
+
+	// The end-of-day endpoint is split-adjusted, so splits must not be applied
+	// to its bars again.
+	if !bar.SplitAdjusted {
+		t.Errorf("Expected the daily bar to be marked split-adjusted: %+v", bar)
+	}
+}
+
+func TestProviderInvalidResolution(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{Body: testIntradayBody})
+
+	_, err := p.Fetch(context.Background(), provider.Request{
+		Endpoint:   fmp.EndpointPrices,
+		Symbol:     "NVDA",
+		Parameters: map[string]string{fmp.ParameterResolution: "2min"},
+	})
+	if err == nil {
+		t.Error("Expected an error for an unsupported resolution")
+	}
+}
@@ -0,0 +1,138 @@
+package fmp_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testNewsBody = `[
+	{
+		"symbol": "aapl",
+		"publishedDate": "2025-02-03 09:30:00",
+		"publisher": "Example News",
+		"title": "Apple opens higher",
+		"image": "https://example.com/aapl.png",
+		"site": "example.com",
+		"text": "Shares of Apple rose at the open.",
+		"url": "https://example.com/aapl"
+	}
+]`
+
+func newTestClient(transport http.RoundTripper) *fmp.HTTPClient {
+	return fmp.NewHTTPClient(test.NewHTTPClient(transport), "test-key", "")
+}
+
+func newTestProvider(transport http.RoundTripper) *fmp.Provider {
+	return fmp.NewProvider(newTestClient(transport), nil)
+}
+
+// newSyntheticProvider replays testdata/synthetic/<name>.json, which fails the
+// test unless every request of the fixture is made. The fixtures are written
+// from FMP's documentation, not recorded from the API.
+func newSyntheticProvider(t *testing.T, name string) *fmp.Provider {
+	t.Helper()
+
+	client := test.NewSyntheticClient(t, filepath.Join("testdata", "synthetic", name+".json"))
+
+	return fmp.NewProvider(fmp.NewHTTPClient(client, "test-key", fmp.DefaultBaseURL), nil)
+}
+
+func TestProviderNews(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testNewsBody}
+	p := newTestProvider(transport)
+
+	request := provider.Request{
+		Endpoint: fmp.EndpointNews,
+		Symbol:   "AAPL",
+		From:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	if payload.BytesFetched != int64(len(testNewsBody)) {
+		t.Errorf("Expected %d bytes fetched, got %d", len(testNewsBody), payload.BytesFetched)
+	}
+
+	if len(transport.Requests()) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(transport.Requests()))
+	}
+
+	query := transport.Requests()[0].URL.Query()
+	if query.Get("symbols") != "AAPL" || query.Get("from") != "2025-01-31" || query.Get("to") != "2025-02-03" {
+		t.Errorf("Unexpected request query: %v", query)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 2 {
+		t.Fatalf("Expected an article and a security link, got %d records", len(normalized))
+	}
+
+	expectedTime := time.Date(2025, 2, 3, 14, 30, 0, 0, time.UTC)
+
+	article, ok := normalized[0].(records.NewsArticle)
+	if !ok {
+		t.Fatalf("Expected a NewsArticle, got %T", normalized[0])
+	}
+
+	if !article.PublishedAt.Equal(expectedTime) || article.PublishedAt.Location() != time.UTC {
+		t.Errorf("Expected publish time %v in UTC, got %v", expectedTime, article.PublishedAt)
+	}
+
+	if article.ID != records.NewsID("https://example.com/aapl") || article.Source != fmp.SourceType {
+		t.Errorf("Unexpected article: %+v", article)
+	}
+
+	link, ok := normalized[1].(records.NewsSecurity)
+	if !ok {
+		t.Fatalf("Expected a NewsSecurity, got %T", normalized[1])
+	}
+
+	if link.Symbol != "AAPL" || link.NewsID != article.ID || !link.PublishedAt.Equal(expectedTime) {
+		t.Errorf("Unexpected security link: %+v", link)
+	}
+
+	for _, record := range normalized {
+		if validateError := record.Validate(); validateError != nil {
+			t.Errorf("Expected %T to be valid: %v", record, validateError)
+		}
+	}
+}
+
+func TestProviderNewsWithoutArticles(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{Body: `[]`})
+
+	payload, err := p.Fetch(context.Background(), provider.Request{Endpoint: fmp.EndpointNews, Symbol: "AAPL"})
+	if err != nil {
+		t.Fatalf("Expected an empty window to succeed, got %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil || len(normalized) != 0 {
+		t.Errorf("Expected no records, got %d (%v)", len(normalized), err)
+	}
+}
+
+func TestProviderUnsupportedEndpoint(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{})
+
+	_, err := p.Fetch(context.Background(), provider.Request{Endpoint: "QUOTES", Symbol: "AAPL"})
+	if err == nil {
+		t.Error("Expected an error for an unsupported endpoint")
+	}
+}
@@ -0,0 +1,176 @@
+package fmp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testIncomeStatementBody = `[
+	{
+		"date": "2025-01-26",
+		"symbol": "NVDA",
+		"reportedCurrency": "USD",
+		"cik": "0001045810",
+		"filingDate": "2025-02-26",
+		"acceptedDate": "2025-02-26 16:42:38",
+		"fiscalYear": "2025",
+		"period": "Q4",
+		"revenue": 39331000000,
+		"netIncome": 22091000000,
+		"eps": 0.9
+	},
+	{
+		"date": "2024-10-27",
+		"symbol": "NVDA",
+		"reportedCurrency": "USD",
+		"cik": "0001045810",
+		"filingDate": "2024-11-20",
+		"acceptedDate": "2024-11-20 16:33:11",
+		"fiscalYear": 2025,
+		"period": "Q3",
+		"revenue": 35082000000,
+		"netIncome": 19309000000,
+		"eps": 0.79
+	}
+]`
+
+func TestProviderStatements(t *testing.T) {
+	p := newSyntheticProvider(t, "income-statement")
+
+	request := provider.Request{
+		Endpoint:   fmp.EndpointIncomeStatement,
+		Symbol:     "NVDA",
+		Parameters: map[string]string{fmp.ParameterPeriod: fmp.PeriodQuarter},
+		From:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	// The Q3 statement reports on a period ending before the window.
+	if len(normalized) != 3 {
+		t.Fatalf("Expected the 3 line items of the Q4 statement, got %d", len(normalized))
+	}
+
+	items := map[string]records.StatementItem{}
+	for _, record := range normalized {
+		item, ok := record.(records.StatementItem)
+		if !ok {
+			t.Fatalf("Expected a StatementItem, got %T", record)
+		}
+
+		if err = item.Validate(); err != nil {
+			t.Errorf("Expected item to be valid: %v", err)
+		}
+
+		items[item.Item] = item
+	}
+
+	revenue, ok := items["revenue"]
+	if !ok {
+		t.Fatalf("Expected a revenue item, got %v", items)
+	}
+
+	expected := records.StatementItem{
+		Symbol:       "NVDA",
+		Statement:    records.StatementIncome,
+		FiscalYear:   2025,
+		FiscalPeriod: "Q4",
+		ReportDate:   time.Date(2025, 1, 26, 0, 0, 0, 0, time.UTC),
+		FilingDate:   time.Date(2025, 2, 26, 0, 0, 0, 0, time.UTC),
+		Item:         "revenue",
+		Value:        39331000000,
+		Currency:     "USD",
+		Source:       fmp.SourceType,
+	}
+
+	if revenue != expected {
+		t.Errorf("Expected %+v, got %+v", expected, revenue)
+	}
+
+	if _, ok = items["cik"]; ok {
+		t.Error("Expected statement metadata not to become line items")
+	}
+}
+
+func TestProviderStatementPeriods(t *testing.T) {
+	tests := []struct {
+		period   string
+		expected string
+		valid    bool
+	}{
+		{period: "", expected: "annual", valid: true},
+		{period: "annual", expected: "annual", valid: true},
+		{period: "quarter", expected: "quarter", valid: true},
+		{period: "monthly", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			transport := &test.RecordingRoundTripper{Body: testIncomeStatementBody}
+			p := newTestProvider(transport)
+
+			_, err := p.Fetch(context.Background(), provider.Request{
+				Endpoint:   fmp.EndpointCashFlow,
+				Symbol:     "NVDA",
+				Parameters: map[string]string{fmp.ParameterPeriod: tt.period},
+			})
+
+			if !tt.valid {
+				if err == nil {
+					t.Error("Expected an error for an unsupported period")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Fetch() failed: %v", err)
+			}
+
+			if period := transport.Requests()[0].URL.Query().Get("period"); period != tt.expected {
+				t.Errorf("Expected period %q, got %q", tt.expected, period)
+			}
+		})
+	}
+}
+
+func TestFinancialStatementYear(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"FiscalYear", `{"fiscalYear": "2025"}`, 2025},
+		{"FiscalYearBeforeCalendarYear", `{"calendarYear": "2024", "fiscalYear": 2025}`, 2025},
+		{"CalendarYearWithoutFiscalYear", `{"calendarYear": "2024"}`, 2024},
+		{"NullFiscalYear", `{"fiscalYear": null, "calendarYear": 2024}`, 2024},
+		{"NullYears", `{"fiscalYear": null, "calendarYear": null}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := fmp.FinancialStatement{}
+			if err := json.Unmarshal([]byte(tt.body), &statement); err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+
+			if statement.FiscalYear != tt.expected {
+				t.Errorf("Expected fiscal year %d, got %d", tt.expected, statement.FiscalYear)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package fred_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fred"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/test"
+)
+
+const testObservationsBody = `{
+	"count": 3,
+	"offset": 0,
+	"limit": 100000,
+	"observations": [
+		{"realtime_start": "2025-02-12", "realtime_end": "2025-02-28", "date": "2025-01-01", "value": "317.671"},
+		{"realtime_start": "2025-03-01", "realtime_end": "9999-12-31", "date": "2025-01-01", "value": "317.780"},
+		{"realtime_start": "2025-03-12", "realtime_end": "9999-12-31", "date": "2025-02-01", "value": "."}
+	]
+}`
+
+func newTestProvider(transport http.RoundTripper) *fred.Provider {
+	return fred.NewProvider(fred.NewHTTPClient(test.NewHTTPClient(transport), "test-key", ""))
+}
+
+func TestProviderObservations(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: testObservationsBody}
+	p := newTestProvider(transport)
+
+	request := provider.Request{
+		Endpoint: fred.EndpointObservations,
+		Series:   "cpiaucsl",
+		From:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	query := transport.Requests()[0].URL.Query()
+	if query.Get("series_id") != "cpiaucsl" || query.Get("api_key") != "test-key" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+	if query.Get("realtime_start") != "1776-07-04" || query.Get("observation_start") != "2025-01-01" {
+		t.Errorf("Expected every vintage in the window to be requested, got %v", query)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 3 {
+		t.Fatalf("Expected 3 observations, got %d", len(normalized))
+	}
+
+	first, _ := normalized[0].(records.SeriesObservation)
+	if first.SeriesID != "CPIAUCSL" || first.Value == nil || *first.Value != 317.671 {
+		t.Errorf("Unexpected first vintage: %+v", first)
+	}
+	if !first.RealtimeEnd.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first vintage to end when it was revised, got %v", first.RealtimeEnd)
+	}
+
+	revised, _ := normalized[1].(records.SeriesObservation)
+	if !revised.RealtimeEnd.IsZero() || *revised.Value != 317.780 {
+		t.Errorf("Unexpected revised vintage: %+v", revised)
+	}
+
+	missing, _ := normalized[2].(records.SeriesObservation)
+	if missing.Value != nil {
+		t.Errorf("Expected a missing value, got %v", *missing.Value)
+	}
+
+	for _, record := range normalized {
+		if err := record.Validate(); err != nil {
+			t.Errorf("Validate() failed: %v", err)
+		}
+	}
+}
+
+func TestProviderRejectsLatestVintages(t *testing.T) {
+	transport := &test.RecordingRoundTripper{Body: `{"observations": []}`}
+	p := newTestProvider(transport)
+
+	request := provider.Request{
+		Endpoint:   fred.EndpointObservations,
+		Series:     "UNRATE",
+		Parameters: map[string]string{fred.ParameterVintages: "latest"},
+	}
+
+	if _, err := p.Fetch(context.Background(), request); err == nil {
+		t.Error("Expected only every vintage to be supported")
+	}
+
+	if len(transport.Requests()) != 0 {
+		t.Errorf("Expected no request, got %d", len(transport.Requests()))
+	}
+}
+
+func TestProviderRequiresSeries(t *testing.T) {
+	p := newTestProvider(&test.RecordingRoundTripper{})
+
+	if _, err := p.Fetch(context.Background(), provider.Request{Endpoint: fred.EndpointObservations}); err == nil {
+		t.Error("Expected a request without a series to fail")
+	}
+}
+
+func TestProviderAPIError(t *testing.T) {
+	transport := &test.RecordingRoundTripper{
+		Status: http.StatusBadRequest,
+		Body:   `{"error_code": 400, "error_message": "Bad Request. The series does not exist."}`,
+	}
+	p := newTestProvider(transport)
+
+	_, err := p.Fetch(context.Background(), provider.Request{Endpoint: fred.EndpointObservations, Series: "NOPE"})
+
+	var apiError *fred.APIError
+	if !errors.As(err, &apiError) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+
+	if apiError.StatusCode != http.StatusBadRequest || apiError.Message != "Bad Request. The series does not exist." {
+		t.Errorf("Unexpected APIError: %+v", apiError)
+	}
+}
+
+// TestProviderObservationsSynthetic replays a fixture written from FRED's
+// documentation, not recorded from the API.
+func TestProviderObservationsSynthetic(t *testing.T) {
+	client := test.NewSyntheticClient(t, "testdata/synthetic/observations.json")
+	p := fred.NewProvider(fred.NewHTTPClient(client, "test-key", fred.DefaultBaseURL))
+
+	request := provider.Request{
+		Endpoint: fred.EndpointObservations,
+		Series:   "CPIAUCSL",
+		From:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	if len(normalized) != 4 {
+		t.Fatalf("Expected every vintage, got %d records", len(normalized))
+	}
+
+	for _, record := range normalized {
+		if validateError := record.Validate(); validateError != nil {
+			t.Errorf("Expected valid records, got %v", validateError)
+		}
+	}
+
+	_, err = p.Fetch(context.Background(), provider.Request{Endpoint: fred.EndpointObservations, Series: "NOSUCHSERIES"})
+
+	apiError := &fred.APIError{}
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected the recorded 400, got %v", err)
+	}
+}
+
+func TestClientObservationsPages(t *testing.T) {
+	offsets := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+
+		observations := []string{}
+		for i := offset; i < min(offset+2, 5); i++ {
+			observations = append(observations, fmt.Sprintf(`{"date": "2025-0%d-01", "value": "%d"}`, i+1, i))
+		}
+
+		fmt.Fprintf(w, `{"count": 5, "offset": %d, "limit": 2, "observations": [%s]}`,
+			offset, strings.Join(observations, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	client := fred.NewHTTPClient(httpUtil.HTTPClient{Client: server.Client(), RetryCount: 0}, "test-key", server.URL)
+
+	observations, err := client.Observations(context.Background(), fred.ObservationQuery{SeriesID: "GDP", Limit: 2})
+	if err != nil {
+		t.Fatalf("Observations() failed: %v", err)
+	}
+
+	if len(observations.Observations) != 5 || observations.Observations[4].Value != "4" {
+		t.Errorf("Expected the 5 observations of every page, got %+v", observations.Observations)
+	}
+
+	if !slices.Equal(offsets, []string{"", "2", "4"}) {
+		t.Errorf("Expected pages at offsets 0, 2 and 4, got %v", offsets)
+	}
+}
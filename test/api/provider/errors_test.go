@@ -0,0 +1,37 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/provider"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"TransportError", &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("connection refused")}, true},
+		{"Timeout", fmt.Errorf("fetch failed: %w", context.DeadlineExceeded), true},
+		{"RateLimited", &fmp.APIError{Path: "news/stock", StatusCode: http.StatusTooManyRequests}, true},
+		{"ServerError", fmt.Errorf("fetch failed: %w", &fmp.APIError{StatusCode: http.StatusBadGateway}), true},
+		{"ClientError", &fmp.APIError{Path: "news/stock", StatusCode: http.StatusUnauthorized}, false},
+		{"NoData", fmp.ErrNoData, false},
+		{"Cancelled", context.Canceled, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if unavailable := provider.IsUnavailable(test.err); unavailable != test.expected {
+				t.Errorf("IsUnavailable(%v) = %t, expected %t", test.err, unavailable, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package provider_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+)
+
+func TestRequestContains(t *testing.T) {
+	from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		request  provider.Request
+		t        time.Time
+		expected bool
+	}{
+		{"OpenWindow", provider.Request{}, from, true},
+		{"From", provider.Request{From: from, To: to}, from, true},
+		{"To", provider.Request{From: from, To: to}, to, true},
+		{"BeforeFrom", provider.Request{From: from, To: to}, from.Add(-time.Second), false},
+		{"AfterTo", provider.Request{From: from, To: to}, to.Add(time.Second), false},
+		{"OpenEnd", provider.Request{From: from}, to.AddDate(1, 0, 0), true},
+		{"OpenStart", provider.Request{To: to}, from.AddDate(-1, 0, 0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if contains := test.request.Contains(test.t); contains != test.expected {
+				t.Errorf("Contains(%v) = %t, expected %t", test.t, contains, test.expected)
+			}
+		})
+	}
+}
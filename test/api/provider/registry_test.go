@@ -0,0 +1,78 @@
+package provider_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/test"
+)
+
+func TestRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T) *provider.Registry {
+		registry := provider.NewRegistry()
+		if err := registry.Register(&test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}}); err != nil {
+			t.Fatalf("Register() failed: %v", err)
+		}
+		return registry
+	}
+
+	t.Run("GetRegistered", func(t *testing.T) {
+		p, err := newRegistry(t).Get("FAKE")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if p.Type() != "FAKE" {
+			t.Errorf("Expected provider FAKE, got %s", p.Type())
+		}
+	})
+
+	t.Run("RejectsDuplicate", func(t *testing.T) {
+		registry := newRegistry(t)
+		if err := registry.Register(&test.FakeProvider{SourceType: "FAKE"}); err == nil {
+			t.Error("Expected registering a second FAKE provider to fail")
+		}
+	})
+
+	t.Run("GetUnknown", func(t *testing.T) {
+		_, err := newRegistry(t).Get("OTHER")
+		if !errors.Is(err, provider.ErrUnknownSource) {
+			t.Errorf("Expected ErrUnknownSource, got %v", err)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		registry := newRegistry(t)
+
+		testCases := []struct {
+			name     string
+			source   crd.DataCollectionSource
+			expected error
+		}{
+			{name: "Supported", source: crd.DataCollectionSource{Type: "FAKE", Endpoint: "NEWS"}},
+			{
+				name:     "UnknownType",
+				source:   crd.DataCollectionSource{Type: "OTHER", Endpoint: "NEWS"},
+				expected: provider.ErrUnknownSource,
+			},
+			{
+				name:     "UnsupportedEndpoint",
+				source:   crd.DataCollectionSource{Type: "FAKE", Endpoint: "PRICES"},
+				expected: provider.ErrUnsupportedEndpoint,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				err := registry.Validate(testCase.source)
+				if testCase.expected == nil && err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if testCase.expected != nil && !errors.Is(err, testCase.expected) {
+					t.Errorf("Expected %v, got %v", testCase.expected, err)
+				}
+			})
+		}
+	})
+}
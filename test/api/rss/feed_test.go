@@ -0,0 +1,123 @@
+package rss_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/rss"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+
+	return data
+}
+
+func TestParseRSS(t *testing.T) {
+	feed, err := rss.ParseFeed(readFixture(t, "press.rss"))
+	if err != nil {
+		t.Fatalf("ParseFeed() failed: %v", err)
+	}
+
+	if feed.Title != "Example Press Releases" || len(feed.Items) != 4 {
+		t.Fatalf("Unexpected feed: %+v", feed)
+	}
+
+	item := feed.Items[0]
+	if item.GUID != "press-1001" || item.Author != "Example Wire" ||
+		item.Link != "https://www.press.example.com/apple-q1" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.Description != "Apple Inc. today announced financial results for its fiscal 2025 first quarter." {
+		t.Errorf("Expected markup to be stripped, got %q", item.Description)
+	}
+	if !item.PublishedAt.Equal(time.Date(2025, 1, 30, 21, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected publish time: %v", item.PublishedAt)
+	}
+
+	if published := feed.Items[2].PublishedAt; !published.Equal(time.Date(2025, 2, 4, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a single digit day to parse, got %v", published)
+	}
+
+	if published := feed.Items[3].PublishedAt; !published.IsZero() {
+		t.Errorf("Expected an undated item, got %v", published)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := rss.ParseFeed(readFixture(t, "markets.atom"))
+	if err != nil {
+		t.Fatalf("ParseFeed() failed: %v", err)
+	}
+
+	if feed.Title != "Example Markets" || feed.Link != "https://markets.example.com/" || len(feed.Items) != 2 {
+		t.Fatalf("Unexpected feed: %+v", feed)
+	}
+
+	entry := feed.Items[0]
+	if entry.GUID != "tag:markets.example.com,2025:2001" || entry.Link != "https://markets.example.com/aapl-rise" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.Author != "Jane Analyst" || entry.Description != "The stock gained 2% in morning trading." {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	// The publish time is preferred over the update time.
+	if !entry.PublishedAt.Equal(time.Date(2025, 1, 31, 19, 45, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected publish time: %v", entry.PublishedAt)
+	}
+
+	if updated := feed.Items[1].PublishedAt; !updated.Equal(time.Date(2025, 1, 30, 21, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected the update time without a publish time, got %v", updated)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := rss.ParseFeed([]byte(`<html><body>Not a feed</body></html>`)); !errors.Is(err, rss.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		symbol  string
+		name    string
+		text    string
+		matches bool
+	}{
+		{"AAPL", "", "Buy $AAPL now", true},
+		{"AAPL", "", "Shares of NASDAQ: AAPL fell", true},
+		{"AAPL", "", "Apple (AAPL) reports", true},
+		{"AAPL", "", "AAPL rose 2%.", true},
+		{"AAPL", "", "Analysts like AAPL.", true},
+		{"AAPL", "", "AAPLX is a different fund", false},
+		{"AAPL", "", "aapl in lowercase", false},
+		{"AAPL", "Apple Inc.", "Apple unveils a new phone", true},
+		{"AAPL", "Apple Inc.", "Pineapple sales rise", false},
+		{"AAPL", "Apple Inc.", "APPLE UNVEILS A NEW PHONE", true},
+		{"TGT", "Target Corp", "Shoppers flock to Target stores", true},
+		{"TGT", "Target Corp", "The Fed missed its inflation target", false},
+		{"META", "Meta Platforms, Inc.", "Meta Platforms plans more data centers", true},
+		{"META", "Meta", "A meta analysis of earnings calls", false},
+		{"A", "", "A new product launch", false},
+		{"A", "", "Agilent (A) beats estimates", true},
+		{"BRK.B", "Berkshire Hathaway Inc.", "Berkshire  Hathaway buys shares", true},
+		{"BRK.B", "", "Shares of $BRK.B rose", true},
+		{"IT", "Gartner, Inc.", "IT budgets grow", false},
+	}
+
+	for _, test := range tests {
+		matcher := rss.NewMatcher(test.symbol, test.name)
+		if matched := matcher.Matches(test.text); matched != test.matches {
+			t.Errorf("Matches(%q) for %s (%q) = %v, expected %v", test.text, test.symbol, test.name, matched, test.matches)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package rss_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/api/rss"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/records"
+)
+
+type feedServer struct {
+	*httptest.Server
+	requests atomic.Int32
+}
+
+func newFeedServer(t *testing.T) *feedServer {
+	t.Helper()
+
+	server := &feedServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+
+		switch r.URL.Path {
+		case "/press.rss":
+			w.Write(readFixture(t, "press.rss"))
+		case "/markets.atom":
+			w.Write(readFixture(t, "markets.atom"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestProvider(fakeClock clock.Clock) *rss.Provider {
+	client := httpUtil.HTTPClient{Client: &http.Client{}, RetryCount: 0}
+	return rss.NewProvider(client, fakeClock)
+}
+
+func newsRequest(symbol string, name string, feeds ...string) provider.Request {
+	return provider.Request{
+		Endpoint:     rss.EndpointNews,
+		Symbol:       symbol,
+		SecurityName: name,
+		Parameters:   map[string]string{rss.ParameterFeeds: strings.Join(feeds, ", ")},
+		From:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func fetchNews(t *testing.T, p *rss.Provider, request provider.Request) ([]records.NewsArticle, int64) {
+	t.Helper()
+
+	payload, err := p.Fetch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	normalized, err := p.Normalize(payload)
+	if err != nil {
+		t.Fatalf("Normalize() failed: %v", err)
+	}
+
+	articles := []records.NewsArticle{}
+	for _, record := range normalized {
+		if err := record.Validate(); err != nil {
+			t.Errorf("Validate() failed: %v", err)
+		}
+
+		switch row := record.(type) {
+		case records.NewsArticle:
+			articles = append(articles, row)
+		case records.NewsSecurity:
+			if row.Symbol != strings.ToUpper(request.Symbol) {
+				t.Errorf("Unexpected link: %+v", row)
+			}
+		}
+	}
+
+	return articles, payload.BytesFetched
+}
+
+func TestProviderMatchesSecurities(t *testing.T) {
+	server := newFeedServer(t)
+	p := newTestProvider(clock.NewFakeClock(time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)))
+
+	request := newsRequest("AAPL", "Apple Inc.", server.URL+"/press.rss", server.URL+"/markets.atom")
+	articles, bytesFetched := fetchNews(t, p, request)
+
+	// The press release is matched by name, the orchard item by its cashtag
+	// category and the Atom entry by its exchange prefixed symbol. The Atom
+	// copy of the press release has the same GUID and is dropped, and the
+	// undated item is skipped.
+	urls := []string{}
+	for _, article := range articles {
+		urls = append(urls, article.URL)
+	}
+
+	expected := []string{
+		"https://www.press.example.com/apple-q1",
+		"https://www.press.example.com/orchard",
+		"https://markets.example.com/aapl-rise",
+	}
+	if strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Fatalf("Expected articles %v, got %v", expected, urls)
+	}
+
+	first := articles[0]
+	if first.ID != records.NewsID(first.URL) || first.Site != "press.example.com" || first.Source != rss.SourceType {
+		t.Errorf("Unexpected article: %+v", first)
+	}
+	if first.Publisher != "Example Press Releases" {
+		t.Errorf("Expected the feed title as publisher, got %q", first.Publisher)
+	}
+
+	if bytesFetched == 0 || server.requests.Load() != 2 {
+		t.Errorf("Expected both feeds to be fetched, got %d requests for %d bytes", server.requests.Load(), bytesFetched)
+	}
+
+	// Jobs for the other securities of the collection reuse the fetched feeds.
+	msftArticles, bytesFetched := fetchNews(t, p, newsRequest("MSFT", "", server.URL+"/press.rss"))
+	if len(msftArticles) != 1 || msftArticles[0].URL != "https://www.press.example.com/msft-cloud" {
+		t.Errorf("Unexpected MSFT articles: %+v", msftArticles)
+	}
+
+	if bytesFetched != 0 || server.requests.Load() != 2 {
+		t.Errorf("Expected the cached feed to be reused, got %d requests", server.requests.Load())
+	}
+}
+
+func TestProviderFeedFailures(t *testing.T) {
+	server := newFeedServer(t)
+	p := newTestProvider(clock.NewFakeClock(time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)))
+
+	articles, _ := fetchNews(t, p, newsRequest("AAPL", "", server.URL+"/missing.rss", server.URL+"/markets.atom"))
+	if len(articles) != 1 {
+		t.Errorf("Expected the readable feed to be used, got %+v", articles)
+	}
+
+	if _, err := p.Fetch(context.Background(), newsRequest("AAPL", "", server.URL+"/missing.rss")); err == nil {
+		t.Error("Expected a request whose feeds all fail to fail")
+	}
+
+	if _, err := p.Fetch(context.Background(), newsRequest("AAPL", "")); err == nil {
+		t.Error("Expected a request without feeds to fail")
+	}
+}
@@ -0,0 +1,125 @@
+package websocket_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/api/websocket"
+)
+
+// newEchoServer echoes every message back. A message "close" makes it close
+// the connection with code 4000 instead.
+func newEchoServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			opcode, data, readError := conn.ReadMessage()
+			if readError != nil {
+				return
+			}
+
+			if string(data) == "close" {
+				payload := binary.BigEndian.AppendUint16(nil, 4000)
+				conn.WriteMessage(websocket.OpClose, append(payload, "bye"...))
+				conn.ReadMessage()
+				return
+			}
+
+			if conn.WriteMessage(opcode, data) != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestConn(t *testing.T) {
+	url := newEchoServer(t)
+
+	t.Run("EchoesMessagesOfEverySize", func(t *testing.T) {
+		conn, err := websocket.Dial(context.Background(), url, http.Header{})
+		if err != nil {
+			t.Fatalf("Dial() failed: %v", err)
+		}
+		defer conn.Close()
+
+		// Sizes cover the short, 16-bit and 64-bit length encodings.
+		for _, size := range []int{0, 125, 126, 65535, 70000} {
+			sent := bytes.Repeat([]byte{'x'}, size)
+			if writeError := conn.WriteMessage(websocket.OpBinary, sent); writeError != nil {
+				t.Fatalf("WriteMessage(%d bytes) failed: %v", size, writeError)
+			}
+
+			opcode, received, readError := conn.ReadMessage()
+			if readError != nil {
+				t.Fatalf("ReadMessage() failed: %v", readError)
+			}
+			if opcode != websocket.OpBinary || !bytes.Equal(received, sent) {
+				t.Errorf("Expected %d bytes echoed as binary, got %d bytes with opcode %d",
+					size, len(received), opcode)
+			}
+		}
+	})
+
+	t.Run("SkipsPongs", func(t *testing.T) {
+		conn, err := websocket.Dial(context.Background(), url, http.Header{})
+		if err != nil {
+			t.Fatalf("Dial() failed: %v", err)
+		}
+		defer conn.Close()
+
+		// The server answers the ping while it waits for the next message.
+		conn.WriteMessage(websocket.OpPing, []byte("ping"))
+		conn.WriteMessage(websocket.OpText, []byte("hello"))
+
+		opcode, data, readError := conn.ReadMessage()
+		if readError != nil || opcode != websocket.OpText || string(data) != "hello" {
+			t.Errorf("Expected the text message after the pong, got %d %q %v", opcode, data, readError)
+		}
+	})
+
+	t.Run("ReturnsCloseError", func(t *testing.T) {
+		conn, err := websocket.Dial(context.Background(), url, http.Header{})
+		if err != nil {
+			t.Fatalf("Dial() failed: %v", err)
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.OpText, []byte("close"))
+
+		_, _, readError := conn.ReadMessage()
+
+		closeError := &websocket.CloseError{}
+		if !errors.As(readError, &closeError) {
+			t.Fatalf("Expected a CloseError, got %v", readError)
+		}
+		if closeError.Code != 4000 || closeError.Reason != "bye" {
+			t.Errorf("Expected close 4000 bye, got %d %s", closeError.Code, closeError.Reason)
+		}
+	})
+
+	t.Run("RejectsNonWebSocketServers", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), http.Header{})
+		if !errors.Is(err, websocket.ErrHandshake) {
+			t.Errorf("Expected ErrHandshake, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+)
+
+// RecordCassettesEnvironmentVariable re-records every cassette against the
+// real APIs when set to 1. Cassettes are replayed offline otherwise.
+const RecordCassettesEnvironmentVariable = "STOCKDB_RECORD_CASSETTES"
+
+// NewCassetteClient returns an HTTPClient that replays the cassette at path,
+// or records it when RecordCassettesEnvironmentVariable is set. secrets, such
+// as API keys read from the environment, are redacted from the recording. A
+// replayed test fails if it did not make every recorded request.
+func NewCassetteClient(t *testing.T, path string, secrets ...string) httpUtil.HTTPClient {
+	t.Helper()
+
+	mode := httpUtil.CassetteReplay
+	if os.Getenv(RecordCassettesEnvironmentVariable) == "1" {
+		mode = httpUtil.CassetteRecord
+	}
+
+	return newCassetteClient(t, path, mode, secrets)
+}
+
+// NewSyntheticClient returns an HTTPClient that replays the synthetic fixture
+// at path, a cassette written by hand from an API's documentation. Unlike
+// cassettes it is never recorded over, so it cannot catch schema drift of the
+// real API. The test fails if it did not make every request of the fixture.
+func NewSyntheticClient(t *testing.T, path string) httpUtil.HTTPClient {
+	t.Helper()
+
+	return newCassetteClient(t, path, httpUtil.CassetteReplay, nil)
+}
+
+func newCassetteClient(t *testing.T, path string, mode httpUtil.CassetteMode, secrets []string) httpUtil.HTTPClient {
+	t.Helper()
+
+	cassette, err := httpUtil.OpenCassette(path, httpUtil.CassetteOptions{Mode: mode, Secrets: secrets})
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("No cassette at %s, record it with %s=1: %v", path, RecordCassettesEnvironmentVariable, err)
+	} else if err != nil {
+		t.Fatalf("OpenCassette() failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if mode == httpUtil.CassetteRecord {
+			if saveError := cassette.Save(); saveError != nil {
+				t.Errorf("Failed to save cassette %s: %v", path, saveError)
+			}
+			return
+		}
+
+		for _, interaction := range cassette.Unplayed() {
+			t.Errorf("Recorded request was not made: %s %s", interaction.Request.Method, interaction.Request.URL)
+		}
+	})
+
+	return cassette.NewHTTPClient()
+}
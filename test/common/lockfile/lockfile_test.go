@@ -0,0 +1,101 @@
+package lockfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/common/lockfile"
+)
+
+func readPIDFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+func TestLockFile(t *testing.T) {
+	t.Run("AcquireWritesPID", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state", "stockd.pid")
+
+		lock, err := lockfile.Acquire(path)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		defer lock.Release()
+
+		if pid := readPIDFile(t, path); pid != strconv.Itoa(os.Getpid()) {
+			t.Errorf("Expected lock file to contain pid %d, got %q", os.Getpid(), pid)
+		}
+	})
+
+	t.Run("SecondAcquireFails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stockd.pid")
+
+		lock, err := lockfile.Acquire(path)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		defer lock.Release()
+
+		_, err = lockfile.Acquire(path)
+		var lockedError *lockfile.LockedError
+		if !errors.As(err, &lockedError) {
+			t.Fatalf("Expected LockedError, got %v", err)
+		}
+
+		if lockedError.PID != os.Getpid() {
+			t.Errorf("Expected holder pid %d, got %d", os.Getpid(), lockedError.PID)
+		}
+	})
+
+	t.Run("ReleaseAllowsReacquire", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stockd.pid")
+
+		lock, err := lockfile.Acquire(path)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+
+		if releaseError := lock.Release(); releaseError != nil {
+			t.Fatalf("Release() failed: %v", releaseError)
+		}
+
+		if pid := readPIDFile(t, path); pid != "" {
+			t.Errorf("Expected released lock file to be empty, got %q", pid)
+		}
+
+		lock, err = lockfile.Acquire(path)
+		if err != nil {
+			t.Fatalf("Acquire() after Release() failed: %v", err)
+		}
+		defer lock.Release()
+	})
+
+	t.Run("ReclaimsStaleLock", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stockd.pid")
+
+		// A pid left behind by a process that crashed without releasing.
+		if err := os.WriteFile(path, []byte("999999\n"), 0644); err != nil {
+			t.Fatalf("Failed to write stale lock file: %v", err)
+		}
+
+		lock, err := lockfile.Acquire(path)
+		if err != nil {
+			t.Fatalf("Acquire() over stale lock failed: %v", err)
+		}
+		defer lock.Release()
+
+		if pid := readPIDFile(t, path); pid != strconv.Itoa(os.Getpid()) {
+			t.Errorf("Expected reclaimed lock file to contain pid %d, got %q", os.Getpid(), pid)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package secrets_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/common/secrets"
+)
+
+const testKey = "sk-live-5f2d9c"
+
+func TestValueIsRedacted(t *testing.T) {
+	value := secrets.Value(testKey)
+
+	formatted := []string{
+		fmt.Sprint(value),
+		fmt.Sprintf("%s %v %q %#v", value, value, value, value),
+		fmt.Sprintf("%+v", struct{ APIKey secrets.Value }{value}),
+	}
+
+	encoded, err := json.Marshal(map[string]secrets.Value{"apiKey": value})
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	for _, text := range append(formatted, string(encoded)) {
+		if strings.Contains(text, testKey) {
+			t.Errorf("Expected the secret to be redacted from %s", text)
+		}
+	}
+
+	if value.Reveal() != testKey {
+		t.Errorf("Expected Reveal() to return the secret, got %q", value.Reveal())
+	}
+}
+
+func TestResolver(t *testing.T) {
+	directory := t.TempDir()
+	if err := os.WriteFile(filepath.Join(directory, "fred-api-key"), []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, "fmp-api-key"), []byte("shadowed"), 0600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+
+	t.Setenv("STOCKDB_TEST_FMP_API_KEY", testKey)
+
+	resolver := secrets.NewResolver(
+		secrets.Environment{Prefix: "STOCKDB_TEST_"},
+		secrets.Directory{Path: directory},
+	)
+
+	tests := []struct {
+		name     string
+		expected secrets.Value
+		err      error
+	}{
+		{name: "fmp-api-key", expected: testKey},
+		{name: "fred-api-key", expected: "from-file"},
+		{name: "missing", err: secrets.ErrNotFound},
+		{name: "../fred-api-key", err: secrets.ErrInvalidName},
+		{name: "FMP_API_KEY", err: secrets.ErrInvalidName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := resolver.Resolve(test.name)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %v, got %v", test.err, err)
+			}
+
+			if value != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected.Reveal(), value.Reveal())
+			}
+		})
+	}
+
+	redacted := resolver.Redact("GET /fred?api_key=from-file failed for " + testKey)
+	if redacted != "GET /fred?api_key=REDACTED failed for REDACTED" {
+		t.Errorf("Unexpected redaction %q", redacted)
+	}
+
+	if _, err := (*secrets.Resolver)(nil).Resolve("fmp-api-key"); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("Expected a nil resolver to have no secrets, got %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	directory := t.TempDir()
+	keyFile := filepath.Join(directory, "key")
+	storePath := filepath.Join(directory, "secrets.enc")
+
+	if err := secrets.GenerateKeyFile(keyFile); err != nil {
+		t.Fatalf("GenerateKeyFile() failed: %v", err)
+	}
+
+	if err := secrets.GenerateKeyFile(keyFile); !errors.Is(err, os.ErrExist) {
+		t.Errorf("Expected an existing key to be kept, got %v", err)
+	}
+
+	key, err := secrets.ReadKeyFile(keyFile)
+	if err != nil {
+		t.Fatalf("ReadKeyFile() failed: %v", err)
+	}
+
+	store, err := secrets.OpenStore(storePath, key)
+	if err != nil {
+		t.Fatalf("OpenStore() failed: %v", err)
+	}
+
+	for name, value := range map[string]secrets.Value{"fmp-api-key": testKey, "fred-api-key": "fred"} {
+		if setError := store.Set(name, value); setError != nil {
+			t.Fatalf("Set() failed: %v", setError)
+		}
+	}
+
+	if setError := store.Set("../escape", "value"); !errors.Is(setError, secrets.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", setError)
+	}
+
+	if deleteError := store.Delete("fred-api-key"); deleteError != nil {
+		t.Fatalf("Delete() failed: %v", deleteError)
+	}
+
+	if saveError := store.Save(); saveError != nil {
+		t.Fatalf("Save() failed: %v", saveError)
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		t.Fatalf("Failed to read store: %v", err)
+	}
+	if strings.Contains(string(data), testKey) || strings.Contains(string(data), "fmp-api-key") {
+		t.Errorf("Expected the store to be encrypted:\n%s", data)
+	}
+
+	reopened, err := secrets.OpenStore(storePath, key)
+	if err != nil {
+		t.Fatalf("OpenStore() failed: %v", err)
+	}
+
+	if names := reopened.Names(); !slices.Equal(names, []string{"fmp-api-key"}) {
+		t.Errorf("Unexpected names %v", names)
+	}
+
+	if value, lookupError := secrets.NewResolver(reopened).Resolve("fmp-api-key"); value != testKey {
+		t.Errorf("Expected the stored secret, got %q: %v", value.Reveal(), lookupError)
+	}
+
+	wrongKey := slices.Clone(key)
+	wrongKey[0] ^= 0xff
+	if _, openError := secrets.OpenStore(storePath, wrongKey); openError == nil {
+		t.Error("Expected a store opened with the wrong key to fail")
+	}
+}
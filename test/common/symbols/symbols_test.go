@@ -0,0 +1,183 @@
+package symbols_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/symbols"
+)
+
+const testMaster = `securities:
+  - id: META
+    name: Meta Platforms, Inc.
+    figi: BBG000MM2P62
+    cusip: 30303M102
+    isin: US30303M1027
+    symbols:
+      - symbol: META
+        from: "2022-06-09"
+      - symbol: FB
+        to: "2022-06-09"
+  - id: BRK.B
+    name: Berkshire Hathaway Inc. Class B
+    symbols:
+      - symbol: BRK.B
+        aliases:
+          fmp: BRK-B
+  - id: FBRT
+    name: Franklin BSP Realty Trust
+    symbols:
+      - symbol: FB
+        from: "2024-01-02"
+`
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func loadMaster(t *testing.T, content string) (*symbols.Master, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "securities.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write master: %v", err)
+	}
+
+	return symbols.LoadMaster(path)
+}
+
+func TestMasterResolve(t *testing.T) {
+	master, err := loadMaster(t, testMaster)
+	if err != nil {
+		t.Fatalf("LoadMaster() failed: %v", err)
+	}
+
+	tests := []struct {
+		symbol   string
+		at       time.Time
+		expected string
+	}{
+		{"META", time.Time{}, "META"},
+		{"fb", date(2020, 1, 2), "META"},
+		// FB was reused after the rename.
+		{"FB", time.Time{}, "FBRT"},
+		{"FB", date(2023, 1, 3), "FBRT"},
+		{"BRK-B", time.Time{}, "BRK.B"},
+		{"BRK/B", time.Time{}, "BRK.B"},
+		{"BBG000MM2P62", time.Time{}, "META"},
+		{"US30303M1027", time.Time{}, "META"},
+		{"30303m102", time.Time{}, "META"},
+	}
+
+	for _, test := range tests {
+		security, ok := master.Resolve(test.symbol, test.at)
+		if !ok || security.ID != test.expected {
+			t.Errorf("Resolve(%q, %v) = %q %t, expected %q", test.symbol, test.at, security.ID, ok, test.expected)
+		}
+	}
+
+	if _, ok := master.Resolve("AAPL", time.Time{}); ok {
+		t.Error("Expected an unknown symbol not to resolve")
+	}
+
+	var empty *symbols.Master
+	if _, ok := empty.Resolve("META", time.Time{}); ok {
+		t.Error("Expected a nil master to resolve nothing")
+	}
+
+	meta, _ := master.Resolve("META", time.Time{})
+	if symbol := meta.GetSymbol(date(2021, 3, 1)); symbol != "FB" {
+		t.Errorf("Expected META to trade as FB in 2021, got %s", symbol)
+	}
+}
+
+func TestSecurityGetSegments(t *testing.T) {
+	master, err := loadMaster(t, testMaster)
+	if err != nil {
+		t.Fatalf("LoadMaster() failed: %v", err)
+	}
+
+	meta, _ := master.Resolve("META", time.Time{})
+
+	segments, err := meta.GetSegments("FMP", date(2022, 1, 1), date(2022, 12, 31))
+	if err != nil {
+		t.Fatalf("GetSegments() failed: %v", err)
+	}
+
+	expected := []symbols.Segment{
+		{Symbol: "FB", From: date(2022, 1, 1), To: date(2022, 6, 9).Add(-time.Nanosecond)},
+		{Symbol: "META", From: date(2022, 6, 9), To: date(2022, 12, 31)},
+	}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, segments)
+	}
+
+	// Without a start, only the ticker in use at the end is collected.
+	segments, _ = meta.GetSegments("FMP", time.Time{}, date(2021, 1, 1))
+	if len(segments) != 1 || segments[0].Symbol != "FB" || !segments[0].From.IsZero() {
+		t.Errorf("Expected the FB ticker for an open window, got %+v", segments)
+	}
+
+	brk, _ := master.Resolve("BRK.B", time.Time{})
+	if segments, _ = brk.GetSegments("FMP", time.Time{}, time.Time{}); segments[0].Symbol != "BRK-B" {
+		t.Errorf("Expected the FMP alias, got %+v", segments)
+	}
+
+	if segments, _ = brk.GetSegments("EDGAR", time.Time{}, time.Time{}); segments[0].Symbol != "BRK.B" {
+		t.Errorf("Expected the ticker for sources without an alias, got %+v", segments)
+	}
+
+	fbrt, _ := master.Resolve("FBRT", time.Time{})
+	if _, err = fbrt.GetSegments("FMP", date(2020, 1, 1), date(2020, 12, 31)); !errors.Is(err, symbols.ErrNotListed) {
+		t.Errorf("Expected ErrNotListed before the listing, got %v", err)
+	}
+}
+
+func TestNewMasterRejectsInvalidSecurities(t *testing.T) {
+	tests := []struct {
+		name       string
+		securities []symbols.Security
+	}{
+		{"MissingSymbols", []symbols.Security{{ID: "AAPL"}}},
+		{"InvalidCUSIP", []symbols.Security{{ID: "AAPL", CUSIP: "037833101", Symbols: []symbols.Listing{{Symbol: "AAPL"}}}}},
+		{"InvalidISIN", []symbols.Security{{ID: "AAPL", ISIN: "US0378331006", Symbols: []symbols.Listing{{Symbol: "AAPL"}}}}},
+		{"InvalidFIGI", []symbols.Security{{ID: "AAPL", FIGI: "BBG000B9XRY5", Symbols: []symbols.Listing{{Symbol: "AAPL"}}}}},
+		{"InvalidDate", []symbols.Security{{ID: "AAPL", Symbols: []symbols.Listing{{Symbol: "AAPL", From: "2020"}}}}},
+		{"OverlappingTickers", []symbols.Security{{ID: "META", Symbols: []symbols.Listing{
+			{Symbol: "FB", To: "2022-06-10"},
+			{Symbol: "META", From: "2022-06-09"},
+		}}}},
+		{"SharedSymbol", []symbols.Security{
+			{ID: "BRK.B", Symbols: []symbols.Listing{{Symbol: "BRK.B"}}},
+			{ID: "BRKB", Symbols: []symbols.Listing{{Symbol: "BRK-B"}}},
+		}},
+		{"DuplicateID", []symbols.Security{
+			{ID: "AAPL", Symbols: []symbols.Listing{{Symbol: "AAPL", To: "2000-01-01"}}},
+			{ID: "aapl", Symbols: []symbols.Listing{{Symbol: "APPLE"}}},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := symbols.NewMaster(test.securities); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	valid := []symbols.Security{{
+		ID:      "AAPL",
+		FIGI:    "BBG000B9XRY4",
+		CUSIP:   "037833100",
+		ISIN:    "US0378331005",
+		Symbols: []symbols.Listing{{Symbol: "AAPL"}},
+	}}
+
+	if _, err := symbols.NewMaster(valid); err != nil {
+		t.Errorf("Expected Apple's identifiers to be valid, got %v", err)
+	}
+}
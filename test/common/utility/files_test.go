@@ -0,0 +1,43 @@
+package utility_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zydee3/stockdb/internal/common/utility"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "state.json")
+
+	for _, contents := range []string{"first", "second"} {
+		if err := utility.WriteFileAtomic(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("WriteFileAtomic() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != contents {
+			t.Errorf("Expected %q, got %q (%v)", contents, data, err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected mode 0600, got %v", perm)
+	}
+
+	// The temporary file is renamed over path, so none is left behind.
+	if entries, _ := os.ReadDir(directory); len(entries) != 1 {
+		t.Errorf("Expected only the written file, got %d files", len(entries))
+	}
+
+	if err = utility.WriteFileAtomic(filepath.Join(directory, "missing", "state.json"), nil, 0600); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,289 @@
+package daemon_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/edgar"
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/fmp/fmptest"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/daemon"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/unix/client"
+	"github.com/zydee3/stockdb/internal/unix/messages"
+	"github.com/zydee3/stockdb/internal/unix/server/handlers"
+	apitypes "github.com/zydee3/stockdb/internal/unix/types"
+)
+
+const (
+	pollInterval = 20 * time.Millisecond
+	pollTimeout  = 10 * time.Second
+)
+
+func pricesCollection(symbols ...string) *crd.DataCollection {
+	securities := make([]crd.DataCollectionSecurity, 0, len(symbols))
+	for _, symbol := range symbols {
+		securities = append(securities, crd.DataCollectionSecurity{Symbol: symbol})
+	}
+
+	return &crd.DataCollection{
+		APIVersion: "stockdbv1",
+		Kind:       "DataCollection",
+		Metadata:   crd.DataCollectionMetaData{Name: "daily-prices"},
+		Spec: crd.DataCollectionSpec{
+			Source:  crd.DataCollectionSource{Type: fmp.SourceType, Endpoint: fmp.EndpointPrices},
+			Targets: crd.DataCollectionTargets{Securities: securities},
+			Schedule: crd.DataCollectionSchedule{
+				Type:      "INTERVAL",
+				StartDate: "2025-04-07T00:00:00Z",
+				EndDate:   "2025-04-12T00:00:00Z",
+			},
+			// Without retries a faulted job fails at once rather than after
+			// the worker's retry delay.
+			Options: crd.DataCollectionOptions{Timeout: "30s", Retries: 0, Priority: 1},
+		},
+	}
+}
+
+// startDaemon runs stockd against the servers of options and returns the path
+// of its socket.
+func startDaemon(t *testing.T, options daemon.ProviderOptions) string {
+	t.Helper()
+
+	t.Setenv("STOCKDB_FMP_API_KEY", fmptest.DefaultAPIKey)
+
+	stateDirectory := t.TempDir()
+	socketPath := filepath.Join(stateDirectory, "stockdb.sock")
+
+	d := daemon.NewDaemon(context.Background(), daemon.Options{
+		StateDirectory: stateDirectory,
+		SocketPath:     socketPath,
+		Providers:      options,
+	})
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := d.Shutdown(); err != nil {
+			t.Errorf("Shutdown() failed: %v", err)
+		}
+	})
+
+	for deadline := time.Now().Add(pollTimeout); ; time.Sleep(pollInterval) {
+		if _, err := client.Send(socketPath, messages.Command{Type: messages.CommandTypeQueueStats}, nil); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Daemon did not listen on %s", socketPath)
+		}
+	}
+
+	return socketPath
+}
+
+// waitForHistory polls the job history until it has count entries.
+func waitForHistory(t *testing.T, socketPath string, count int) []history.Entry {
+	t.Helper()
+
+	for deadline := time.Now().Add(pollTimeout); ; time.Sleep(pollInterval) {
+		data := &apitypes.HistoryResponse{}
+		if _, err := client.Send(socketPath, messages.Command{Type: messages.CommandTypeHistory}, data); err != nil {
+			t.Fatalf("History request failed: %v", err)
+		}
+
+		if len(data.Entries) >= count {
+			return data.Entries
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d history entries, got %+v", count, data.Entries)
+		}
+	}
+}
+
+func TestDaemonEndToEnd(t *testing.T) {
+	server := fmptest.NewServer()
+	t.Cleanup(server.Close)
+
+	// The rate limit is retried by the HTTP client, malformed JSON is not.
+	server.Inject(fmptest.Fault{Kind: fmptest.FaultRateLimit, Symbol: "MSFT", Times: 1})
+	server.Inject(fmptest.Fault{Kind: fmptest.FaultMalformed, Symbol: "NVDA"})
+	server.Inject(fmptest.Fault{Kind: fmptest.FaultSlow, Symbol: "GOOG", Delay: 200 * time.Millisecond})
+
+	socketPath := startDaemon(t, daemon.ProviderOptions{FMPBaseURL: server.GetBaseURL()})
+	symbols := []string{"AAPL", "MSFT", "NVDA", "GOOG"}
+
+	applied := &apitypes.ApplyResponse{}
+	apply := messages.Command{Type: messages.CommandTypeApply, Data: pricesCollection(symbols...)}
+	if _, err := client.Send(socketPath, apply, applied); err != nil {
+		t.Fatalf("Apply request failed: %v", err)
+	}
+
+	if len(applied.Jobs) != len(symbols) {
+		t.Fatalf("Expected a job per symbol, got %v", applied.Jobs)
+	}
+
+	outcomes := map[string]history.Outcome{}
+	for _, entry := range waitForHistory(t, socketPath, len(symbols)) {
+		outcomes[entry.Symbol] = entry.Outcome
+	}
+
+	expected := map[string]history.Outcome{
+		"AAPL": history.OutcomeSucceeded,
+		"MSFT": history.OutcomeSucceeded,
+		"NVDA": history.OutcomeFailed,
+		"GOOG": history.OutcomeSucceeded,
+	}
+
+	for symbol, outcome := range expected {
+		if outcomes[symbol] != outcome {
+			t.Errorf("Expected %s to have %s, got %q", symbol, outcome, outcomes[symbol])
+		}
+	}
+
+	for _, symbol := range symbols {
+		bars := &apitypes.BarsResponse{}
+		query := messages.Command{
+			Type:       messages.CommandTypeQueryBars,
+			Parameters: map[string]string{handlers.ParameterSymbol: symbol},
+		}
+		if _, err := client.Send(socketPath, query, bars); err != nil {
+			t.Fatalf("Bars request failed: %v", err)
+		}
+
+		// April 7 to 11 2025 is a single trading week.
+		wantBars := 0
+		if expected[symbol] == history.OutcomeSucceeded {
+			wantBars = 5
+		}
+
+		if len(bars.Bars) != wantBars {
+			t.Errorf("Expected %d bars for %s, got %d", wantBars, symbol, len(bars.Bars))
+		}
+	}
+
+	// Malformed JSON is a failure of the request, not an outage of FMP.
+	status := &apitypes.StatusResponse{}
+	if _, err := client.Send(socketPath, messages.Command{Type: messages.CommandTypeStatus}, status); err != nil {
+		t.Fatalf("Status request failed: %v", err)
+	}
+
+	circuits := status.Circuits
+	if len(circuits) != 1 || circuits[0].State != breaker.StateClosed || circuits[0].Requests != len(symbols) {
+		t.Errorf("Expected a closed FMP PRICES circuit, got %+v", circuits)
+	}
+
+	// Every job made a single request, and MSFT a retry, with the key kept
+	// out of the URL.
+	requested := []string{}
+	for _, request := range server.Requests() {
+		requested = append(requested, request.Query.Get("symbol"))
+
+		if request.Query.Has("apikey") {
+			t.Errorf("Expected the API key to be sent as a header, got query %v", request.Query)
+		}
+	}
+
+	slices.Sort(requested)
+	if !slices.Equal(requested, []string{"AAPL", "GOOG", "MSFT", "MSFT", "NVDA"}) {
+		t.Errorf("Unexpected requests for %v", requested)
+	}
+}
+
+func TestDaemonEDGARMirror(t *testing.T) {
+	t.Setenv("STOCKDB_EDGAR_USER_AGENT", "StockDB Tests tests@example.com")
+
+	fixtures := map[string]string{
+		edgar.TickersPath:                 "company_tickers.json",
+		"/submissions/CIK0000320193.json": "submissions_CIK0000320193.json",
+	}
+
+	var mu sync.Mutex
+	paths := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join("..", "api", "edgar", "testdata", fixture))
+	}))
+	t.Cleanup(server.Close)
+
+	// Only the base URL is set, so the ticker list is read from the mirror
+	// too rather than from SEC.
+	socketPath := startDaemon(t, daemon.ProviderOptions{EDGARBaseURL: server.URL})
+
+	collection := &crd.DataCollection{
+		APIVersion: "stockdbv1",
+		Kind:       "DataCollection",
+		Metadata:   crd.DataCollectionMetaData{Name: "filings"},
+		Spec: crd.DataCollectionSpec{
+			Source: crd.DataCollectionSource{Type: edgar.SourceType, Endpoint: edgar.EndpointFilings},
+			Targets: crd.DataCollectionTargets{
+				Securities: []crd.DataCollectionSecurity{{Symbol: "AAPL"}},
+			},
+			Schedule: crd.DataCollectionSchedule{
+				Type:      "INTERVAL",
+				StartDate: "2025-01-01T00:00:00Z",
+				EndDate:   "2025-02-01T00:00:00Z",
+			},
+			Options: crd.DataCollectionOptions{Timeout: "30s", Retries: 0, Priority: 1},
+		},
+	}
+
+	apply := messages.Command{Type: messages.CommandTypeApply, Data: collection}
+	if _, err := client.Send(socketPath, apply, &apitypes.ApplyResponse{}); err != nil {
+		t.Fatalf("Apply request failed: %v", err)
+	}
+
+	entries := waitForHistory(t, socketPath, 1)
+	if entries[0].Outcome != history.OutcomeSucceeded {
+		t.Errorf("Expected the filings job to succeed, got %+v", entries[0])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !slices.Contains(paths, edgar.TickersPath) {
+		t.Errorf("Expected the ticker list to be read from the mirror, got requests for %v", paths)
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	cmd := daemon.NewCommand()
+	cmd.Writer = io.Discard
+
+	args := []string{
+		"stockd",
+		"--state-dir", t.TempDir(),
+		"--http-cache-size", "1024",
+		"--secrets-key", filepath.Join(t.TempDir(), "secrets.key"),
+		"secrets", "init",
+	}
+	if err := cmd.Run(context.Background(), args); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if size := cmd.Int64("http-cache-size"); size != 1024 {
+		t.Errorf("Expected an HTTP cache size of 1024, got %d", size)
+	}
+}
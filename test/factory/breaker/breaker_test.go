@@ -0,0 +1,135 @@
+package breaker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+)
+
+//nolint:gochecknoglobals // gochecknoglobals
+var (
+	fmpPrices = breaker.Key{Source: "FMP", Endpoint: "PRICES"}
+	fmpNews   = breaker.Key{Source: "FMP", Endpoint: "NEWS"}
+)
+
+func newSet() (*breaker.Set, *clock.FakeClock) {
+	fakeClock := clock.NewFakeClock(time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC))
+
+	return breaker.NewSet(breaker.Options{
+		WindowSize:      4,
+		MinimumRequests: 3,
+		FailureRate:     0.5,
+		OpenDuration:    time.Minute,
+		MaxOpenDuration: 3 * time.Minute,
+	}, fakeClock), fakeClock
+}
+
+func record(set *breaker.Set, key breaker.Key, results ...breaker.Result) {
+	for _, result := range results {
+		set.Allow(key)
+		set.Record(key, result)
+	}
+}
+
+func getState(t *testing.T, set *breaker.Set, key breaker.Key) breaker.State {
+	t.Helper()
+
+	for _, status := range set.Statuses() {
+		if status.Key == key {
+			return status.State
+		}
+	}
+
+	t.Fatalf("No circuit for %+v", key)
+	return ""
+}
+
+func TestBreakerOpensOnFailureRate(t *testing.T) {
+	set, fakeClock := newSet()
+
+	// Two failures are too few requests to judge.
+	record(set, fmpPrices, breaker.ResultFailure, breaker.ResultFailure)
+	if state := getState(t, set, fmpPrices); state != breaker.StateClosed {
+		t.Fatalf("Expected the circuit to stay closed below the minimum requests, got %s", state)
+	}
+
+	record(set, fmpPrices, breaker.ResultSuccess)
+
+	allowed, retryAt := set.Allow(fmpPrices)
+	if allowed || !retryAt.Equal(fakeClock.Now().Add(time.Minute)) {
+		t.Errorf("Expected the open circuit to defer until %v, got %t %v", fakeClock.Now().Add(time.Minute), allowed, retryAt)
+	}
+
+	if allowed, _ := set.Allow(fmpNews); !allowed {
+		t.Error("Expected other endpoints to keep their own circuit")
+	}
+
+	status := set.Statuses()[1]
+	if status.Key != fmpPrices || status.State != breaker.StateOpen || status.Failures != 2 || status.Requests != 3 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestBreakerIgnoresOldAndIgnoredResults(t *testing.T) {
+	set, _ := newSet()
+
+	record(set, fmpPrices, breaker.ResultFailure, breaker.ResultIgnored, breaker.ResultIgnored, breaker.ResultIgnored)
+	record(set, fmpPrices, breaker.ResultSuccess, breaker.ResultSuccess, breaker.ResultSuccess, breaker.ResultSuccess)
+
+	// The window holds the four successes, the failure left it.
+	status := set.Statuses()[0]
+	if status.State != breaker.StateClosed || status.Requests != 4 || status.Failures != 0 {
+		t.Errorf("Expected a window of 4 successes, got %+v", status)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	set, fakeClock := newSet()
+	record(set, fmpPrices, breaker.ResultFailure, breaker.ResultFailure, breaker.ResultFailure)
+
+	fakeClock.Advance(time.Minute)
+
+	if state := getState(t, set, fmpPrices); state != breaker.StateHalfOpen {
+		t.Fatalf("Expected the circuit to half-open after its open duration, got %s", state)
+	}
+
+	if allowed, _ := set.Allow(fmpPrices); !allowed {
+		t.Fatal("Expected a probe to be let through")
+	}
+
+	if allowed, _ := set.Allow(fmpPrices); allowed {
+		t.Error("Expected requests to wait for the probe")
+	}
+
+	// A failed probe opens the circuit for twice as long, up to the maximum.
+	set.Record(fmpPrices, breaker.ResultFailure)
+	for _, duration := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		allowed, retryAt := set.Allow(fmpPrices)
+		if allowed || !retryAt.Equal(fakeClock.Now().Add(duration)) {
+			t.Errorf("Expected the circuit to open for %v, got %t %v", duration, allowed, retryAt)
+		}
+
+		fakeClock.Advance(duration)
+		set.Allow(fmpPrices)
+		set.Record(fmpPrices, breaker.ResultFailure)
+	}
+
+	fakeClock.Advance(3 * time.Minute)
+	record(set, fmpPrices, breaker.ResultSuccess)
+
+	status := set.Statuses()[0]
+	if status.State != breaker.StateClosed || status.Requests != 0 || !status.RetryAt.IsZero() {
+		t.Errorf("Expected a successful probe to close and reset the circuit, got %+v", status)
+	}
+}
+
+func TestNilSetAllowsEverything(t *testing.T) {
+	var set *breaker.Set
+
+	set.Record(fmpPrices, breaker.ResultFailure)
+	if allowed, _ := set.Allow(fmpPrices); !allowed || set.Statuses() != nil {
+		t.Error("Expected a nil set to allow every request")
+	}
+}
@@ -0,0 +1,595 @@
+package factory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/file"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	"github.com/zydee3/stockdb/internal/api/rss"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+	"github.com/zydee3/stockdb/internal/common/symbols"
+	"github.com/zydee3/stockdb/internal/factory"
+	"github.com/zydee3/stockdb/internal/factory/breaker"
+	"github.com/zydee3/stockdb/internal/factory/history"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/storage"
+	"github.com/zydee3/stockdb/test"
+)
+
+func testCollection(retries int, symbols ...string) *crd.DataCollection {
+	securities := make([]crd.DataCollectionSecurity, 0, len(symbols))
+	for _, symbol := range symbols {
+		securities = append(securities, crd.DataCollectionSecurity{Symbol: symbol})
+	}
+
+	return &crd.DataCollection{
+		APIVersion: "stockdbv1",
+		Kind:       "DataCollection",
+		Metadata:   crd.DataCollectionMetaData{Name: "news"},
+		Spec: crd.DataCollectionSpec{
+			Source:  crd.DataCollectionSource{Type: "FAKE", Endpoint: "NEWS"},
+			Targets: crd.DataCollectionTargets{Securities: securities},
+			Schedule: crd.DataCollectionSchedule{
+				Type:      "INTERVAL",
+				StartDate: "2025-01-01T00:00:00Z",
+				EndDate:   "2025-04-01T00:00:00Z",
+			},
+			Options: crd.DataCollectionOptions{Timeout: "30s", Retries: retries, Priority: 1},
+		},
+	}
+}
+
+func testNews(symbol string) []records.Record {
+	publishedAt := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+	newsID := records.NewsID("https://example.com/" + symbol)
+
+	return []records.Record{
+		records.NewsArticle{
+			ID:          newsID,
+			Source:      "FAKE",
+			PublishedAt: publishedAt,
+			Title:       symbol,
+			URL:         "https://example.com/" + symbol,
+		},
+		records.NewsSecurity{NewsID: newsID, Symbol: symbol, PublishedAt: publishedAt},
+		// Invalid records are dropped by the worker rather than failing the job.
+		records.NewsSecurity{Symbol: symbol},
+	}
+}
+
+func newRegistry(t *testing.T, p provider.Provider) *provider.Registry {
+	t.Helper()
+
+	registry := provider.NewRegistry()
+	if err := registry.Register(p); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	return registry
+}
+
+type workerFixture struct {
+	jobs    jobqueue.FullJobQueue
+	retries jobqueue.FullJobQueue
+	store   *storage.Store
+	history *history.Store
+	clock   *clock.FakeClock
+}
+
+func startWorker(t *testing.T, p provider.Provider) *workerFixture {
+	t.Helper()
+
+	return startWorkerWithSecrets(t, p, nil)
+}
+
+func startWorkerWithSecrets(t *testing.T, p provider.Provider, resolver *secrets.Resolver) *workerFixture {
+	t.Helper()
+
+	return startWorkerWith(t, p, func(deps *factory.WorkerDependencies) {
+		deps.Secrets = resolver
+	})
+}
+
+// startWorkerWith starts a worker for p with the dependencies configure sets
+// on top of the fixture's.
+func startWorkerWith(
+	t *testing.T,
+	p provider.Provider,
+	configure func(deps *factory.WorkerDependencies),
+) *workerFixture {
+	t.Helper()
+
+	fakeClock := clock.NewFakeClock(time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC))
+
+	jobHistory, err := history.Open(filepath.Join(t.TempDir(), "history.jsonl"), 0, fakeClock)
+	if err != nil {
+		t.Fatalf("history.Open() failed: %v", err)
+	}
+	t.Cleanup(func() { jobHistory.Close() })
+
+	fixture := &workerFixture{
+		jobs:    jobqueue.NewUnifiedJobQueue(10),
+		retries: jobqueue.NewUnifiedJobQueue(10),
+		store:   storage.NewStore(),
+		history: jobHistory,
+		clock:   fakeClock,
+	}
+
+	deps := factory.WorkerDependencies{
+		Jobs:      fixture.jobs,
+		Retries:   fixture.retries,
+		Providers: newRegistry(t, p),
+		Store:     fixture.store,
+		History:   fixture.history,
+		Clock:     fixture.clock,
+	}
+	configure(&deps)
+
+	worker := factory.NewWorker(0, deps)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return fixture
+}
+
+func (f *workerFixture) waitForHistory(t *testing.T, count int) []history.Entry {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entries := f.history.Query(history.Query{})
+		if len(entries) >= count {
+			return entries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d history entries, have %d", count, len(entries))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManager(t *testing.T) {
+	t.Run("SplitsPerSecurity", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		registry := newRegistry(t, &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}})
+		manager := factory.NewManager(queue, registry, nil, nil)
+
+		queued, err := manager.Submit(context.Background(), testCollection(0, "AAPL", "MSFT", "GOOGL"))
+		if err != nil {
+			t.Fatalf("Submit() failed: %v", err)
+		}
+
+		if len(queued) != 3 || queue.Len() != 3 {
+			t.Fatalf("Expected 3 jobs queued, got %d (queue has %d)", len(queued), queue.Len())
+		}
+
+		expectedStart := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i, symbol := range []string{"AAPL", "MSFT", "GOOGL"} {
+			job := queued[i]
+			if job.Symbol != symbol || job.ID == "" || !job.StartTime.Equal(expectedStart) {
+				t.Errorf("Unexpected job for %s: %+v", symbol, job)
+			}
+		}
+	})
+
+	t.Run("SplitsRenamedSecurities", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		registry := newRegistry(t, &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}})
+
+		master, err := symbols.NewMaster([]symbols.Security{
+			{ID: "META", Name: "Meta Platforms", Symbols: []symbols.Listing{
+				{Symbol: "FB", To: "2025-02-01"},
+				{Symbol: "META", From: "2025-02-01"},
+			}},
+			{ID: "BRK.B", Symbols: []symbols.Listing{{Symbol: "BRK.B", Aliases: map[string]string{"FAKE": "BRK-B"}}}},
+		})
+		if err != nil {
+			t.Fatalf("NewMaster() failed: %v", err)
+		}
+
+		manager := factory.NewManager(queue, registry, nil, master)
+
+		queued, err := manager.Submit(context.Background(), testCollection(0, "FB", "brk.b", "AAPL"))
+		if err != nil {
+			t.Fatalf("Submit() failed: %v", err)
+		}
+
+		if len(queued) != 4 {
+			t.Fatalf("Expected a job per ticker of each security, got %+v", queued)
+		}
+
+		rename := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		expected := []jobs.Job{
+			{Symbol: "META", ProviderSymbol: "FB", SecurityName: "Meta Platforms",
+				StartTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndTime: rename.Add(-time.Nanosecond)},
+			{Symbol: "META", SecurityName: "Meta Platforms",
+				StartTime: rename, EndTime: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{Symbol: "BRK.B", ProviderSymbol: "BRK-B"},
+			{Symbol: "AAPL"},
+		}
+
+		for i, job := range queued {
+			if job.Symbol != expected[i].Symbol || job.ProviderSymbol != expected[i].ProviderSymbol {
+				t.Errorf("Expected job %d for %s as %q, got %+v", i, expected[i].Symbol, expected[i].ProviderSymbol, job)
+			}
+
+			if expected[i].SecurityName != "" && (job.SecurityName != expected[i].SecurityName ||
+				!job.StartTime.Equal(expected[i].StartTime) || !job.EndTime.Equal(expected[i].EndTime)) {
+				t.Errorf("Unexpected window of job %d: %+v", i, job)
+			}
+		}
+	})
+
+	t.Run("RejectsUnsupportedEndpoint", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		registry := newRegistry(t, &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"PRICES"}})
+		manager := factory.NewManager(queue, registry, nil, nil)
+
+		_, err := manager.Submit(context.Background(), testCollection(0, "AAPL"))
+		if !errors.Is(err, provider.ErrUnsupportedEndpoint) {
+			t.Errorf("Expected ErrUnsupportedEndpoint, got %v", err)
+		}
+
+		if queue.Len() != 0 {
+			t.Errorf("Expected no jobs queued, got %d", queue.Len())
+		}
+	})
+	t.Run("RejectsInvalidFrequency", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		registry := newRegistry(t, &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}})
+		manager := factory.NewManager(queue, registry, nil, nil)
+
+		for _, frequency := range []string{"", "FORTNIGHTLY", "-1h"} {
+			collection := testCollection(0, "AAPL")
+			collection.Spec.Schedule = crd.DataCollectionSchedule{Type: crd.ScheduleRecurring, Frequency: frequency}
+
+			if _, err := manager.Submit(context.Background(), collection); err == nil {
+				t.Errorf("Expected frequency %q to be rejected", frequency)
+			}
+		}
+
+		if queue.Len() != 0 {
+			t.Errorf("Expected no jobs queued, got %d", queue.Len())
+		}
+	})
+	t.Run("SplitsPerSeries", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Series: true}
+		manager := factory.NewManager(queue, newRegistry(t, fake), nil, nil)
+
+		collection := testCollection(0)
+		collection.Spec.Targets.Series = []crd.DataCollectionSeries{{ID: "CPIAUCSL"}, {ID: "UNRATE"}}
+
+		queued, err := manager.Submit(context.Background(), collection)
+		if err != nil {
+			t.Fatalf("Submit() failed: %v", err)
+		}
+
+		if len(queued) != 2 || queued[0].Series != "CPIAUCSL" || queued[1].Series != "UNRATE" {
+			t.Fatalf("Expected a job per series, got %+v", queued)
+		}
+
+		if queued[0].Symbol != "" || queued[0].GetTarget() != "CPIAUCSL" {
+			t.Errorf("Unexpected series job: %+v", queued[0])
+		}
+	})
+
+	t.Run("RejectsMismatchedTargets", func(t *testing.T) {
+		queue := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Series: true}
+		manager := factory.NewManager(queue, newRegistry(t, fake), nil, nil)
+
+		if _, err := manager.Submit(context.Background(), testCollection(0, "AAPL")); err == nil {
+			t.Error("Expected securities to be rejected by a series source")
+		}
+
+		if queue.Len() != 0 {
+			t.Errorf("Expected no jobs queued, got %d", queue.Len())
+		}
+	})
+}
+
+func TestWorker(t *testing.T) {
+	t.Run("WritesRecordsAndHistory", func(t *testing.T) {
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Records: testNews("AAPL")}
+		fixture := startWorker(t, fake)
+
+		job := jobs.Job{ID: "job-1", CRD: testCollection(0, "AAPL"), Symbol: "AAPL"}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		entries := fixture.waitForHistory(t, 1)
+		entry := entries[0]
+		if entry.Outcome != history.OutcomeSucceeded || entry.JobID != "job-1" || entry.Symbol != "AAPL" {
+			t.Errorf("Unexpected history entry: %+v", entry)
+		}
+		if entry.RowsWritten != 2 || entry.Attempts != 1 || entry.Source != "FAKE" {
+			t.Errorf("Unexpected history entry: %+v", entry)
+		}
+
+		if articles := fixture.store.NewsForSecurity("AAPL", time.Time{}, time.Time{}); len(articles) != 1 {
+			t.Errorf("Expected 1 article for AAPL, got %d", len(articles))
+		}
+
+		requests := fake.Requests()
+		if len(requests) != 1 || requests[0].Symbol != "AAPL" || requests[0].Endpoint != "NEWS" {
+			t.Errorf("Unexpected provider requests: %+v", requests)
+		}
+	})
+
+	t.Run("StoresFormerTickersUnderTheSecurity", func(t *testing.T) {
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Records: testNews("FB")}
+		fixture := startWorker(t, fake)
+
+		job := jobs.Job{ID: "job-1", CRD: testCollection(0, "META"), Symbol: "META", ProviderSymbol: "FB"}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		fixture.waitForHistory(t, 1)
+
+		if requests := fake.Requests(); len(requests) != 1 || requests[0].Symbol != "FB" {
+			t.Errorf("Expected the former ticker to be requested, got %+v", requests)
+		}
+
+		if articles := fixture.store.NewsForSecurity("META", time.Time{}, time.Time{}); len(articles) != 1 {
+			t.Errorf("Expected the article to be stored under META, got %d articles", len(articles))
+		}
+	})
+
+	t.Run("RetriesWithBackoff", func(t *testing.T) {
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Err: errors.New("unavailable")}
+		fixture := startWorker(t, fake)
+
+		job := jobs.Job{ID: "job-1", CRD: testCollection(2, "AAPL"), Symbol: "AAPL"}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		retries, _ := fixture.retries.GetOutputChannel()
+		select {
+		case retry := <-retries:
+			if retry.Attempts != 1 || retry.Status != jobs.StatusRetrying {
+				t.Errorf("Unexpected retry: %+v", retry)
+			}
+			if !retry.NotBefore.After(fixture.clock.Now()) {
+				t.Errorf("Expected retry to be delayed, NotBefore is %v", retry.NotBefore)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for retry")
+		}
+
+		if entries := fixture.history.Query(history.Query{}); len(entries) != 0 {
+			t.Errorf("Expected no history for a job that will be retried, got %+v", entries)
+		}
+	})
+
+	t.Run("AbandonsAfterRetries", func(t *testing.T) {
+		fake := &test.FakeProvider{SourceType: "FAKE", EndpointNames: []string{"NEWS"}, Err: errors.New("unavailable")}
+		fixture := startWorker(t, fake)
+
+		job := jobs.Job{ID: "job-1", CRD: testCollection(2, "AAPL"), Symbol: "AAPL", Attempts: 2}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		entry := fixture.waitForHistory(t, 1)[0]
+		if entry.Outcome != history.OutcomeFailed || entry.Attempts != 3 || entry.Error == "" {
+			t.Errorf("Unexpected history entry: %+v", entry)
+		}
+	})
+	t.Run("StreamsBatches", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bars.csv")
+		contents := "time,open,high,low,close,volume\n" +
+			"2025-01-02,10,11,9,10,100\n2025-01-03,10,11,9,10,100\n2025-01-06,10,11,9,10,100\n"
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("WriteFile() failed: %v", err)
+		}
+
+		progress, _ := file.OpenProgress("")
+		fixture := startWorker(t, file.NewProvider(progress))
+
+		collection := testCollection(0, "AAPL")
+		collection.Spec.Source = crd.DataCollectionSource{
+			Type:       file.SourceType,
+			Endpoint:   file.EndpointBars,
+			Parameters: map[string]string{file.ParameterPath: path, file.ParameterBatchSize: "2"},
+		}
+
+		job := jobs.Job{ID: "job-1", CRD: collection, Symbol: "AAPL"}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		entry := fixture.waitForHistory(t, 1)[0]
+		if entry.Outcome != history.OutcomeSucceeded || entry.RowsWritten != 3 || entry.BytesFetched != int64(len(contents)) {
+			t.Errorf("Unexpected history entry: %+v", entry)
+		}
+
+		if bars := fixture.store.BarsForSecurity("AAPL", records.Resolution1Day, time.Time{}, time.Time{}); len(bars) != 3 {
+			t.Errorf("Expected 3 bars for AAPL, got %d", len(bars))
+		}
+	})
+
+	t.Run("DefersJobsWhileCircuitOpen", func(t *testing.T) {
+		fake := &test.FakeProvider{
+			SourceType:    "FAKE",
+			EndpointNames: []string{"NEWS"},
+			Err:           &url.Error{Op: "Get", URL: "https://example.com/news", Err: errors.New("connection refused")},
+		}
+
+		var breakers *breaker.Set
+		fixture := startWorkerWith(t, fake, func(deps *factory.WorkerDependencies) {
+			breakers = breaker.NewSet(breaker.Options{
+				WindowSize:      4,
+				MinimumRequests: 2,
+				FailureRate:     0.5,
+				OpenDuration:    time.Minute,
+			}, deps.Clock)
+			deps.Breakers = breakers
+		})
+
+		for i := range 3 {
+			job := jobs.Job{ID: fmt.Sprintf("job-%d", i), CRD: testCollection(0, "AAPL"), Symbol: "AAPL"}
+			if err := fixture.jobs.Add(context.Background(), job); err != nil {
+				t.Fatalf("Add() failed: %v", err)
+			}
+		}
+
+		retries, _ := fixture.retries.GetOutputChannel()
+		select {
+		case deferred := <-retries:
+			if deferred.ID != "job-2" || deferred.Status != jobs.StatusDeferred || deferred.Attempts != 0 {
+				t.Errorf("Unexpected deferred job: %+v", deferred)
+			}
+			if !deferred.NotBefore.Equal(fixture.clock.Now().Add(time.Minute)) {
+				t.Errorf("Expected the job to be deferred until the circuit half-opens, got %v", deferred.NotBefore)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for the job to be deferred")
+		}
+
+		if requests := fake.Requests(); len(requests) != 2 {
+			t.Errorf("Expected the open circuit to stop requests, got %d", len(requests))
+		}
+
+		if entries := fixture.waitForHistory(t, 2); len(entries) != 2 {
+			t.Errorf("Expected the deferred job to stay out of the history, got %+v", entries)
+		}
+
+		if statuses := breakers.Statuses(); len(statuses) != 1 || statuses[0].State != breaker.StateOpen {
+			t.Errorf("Expected the FAKE NEWS circuit to be open, got %+v", statuses)
+		}
+	})
+
+	t.Run("UsesCollectionSecret", func(t *testing.T) {
+		const (
+			researchKey = "sk-research-81c4"
+		)
+
+		t.Setenv("STOCKDB_TEST_RESEARCH_KEY", researchKey)
+		resolver := secrets.NewResolver(secrets.Environment{Prefix: "STOCKDB_TEST_"})
+
+		fake := &test.FakeProvider{
+			SourceType:    "FAKE",
+			EndpointNames: []string{"NEWS"},
+			Err:           errors.New("GET /news?apikey=" + researchKey + ": 401 Unauthorized"),
+		}
+		fixture := startWorkerWithSecrets(t, fake, resolver)
+
+		for i, name := range []string{"research-key", "missing-key"} {
+			collection := testCollection(0, "AAPL")
+			collection.Spec.Source.Secret = name
+
+			job := jobs.Job{ID: fmt.Sprintf("job-%d", i), CRD: collection, Symbol: "AAPL"}
+			if err := fixture.jobs.Add(context.Background(), job); err != nil {
+				t.Fatalf("Add() failed: %v", err)
+			}
+		}
+
+		entries := fixture.waitForHistory(t, 2)
+		errorsByJob := map[string]string{}
+		for _, entry := range entries {
+			errorsByJob[entry.JobID] = entry.Error
+		}
+
+		if !strings.Contains(errorsByJob["job-0"], "apikey=REDACTED") || strings.Contains(errorsByJob["job-0"], researchKey) {
+			t.Errorf("Expected the secret to be redacted from the job error, got %q", errorsByJob["job-0"])
+		}
+
+		if !strings.Contains(errorsByJob["job-1"], secrets.ErrNotFound.Error()) {
+			t.Errorf("Expected a missing secret to fail the job, got %q", errorsByJob["job-1"])
+		}
+
+		if used := fake.GetSecrets(); len(used) != 1 || used[0] != researchKey {
+			t.Errorf("Expected a single fetch with the collection's secret, got %d", len(used))
+		}
+	})
+
+	t.Run("PollsRecurringFeeds", func(t *testing.T) {
+		var mu sync.Mutex
+		items := []string{`<item><title>Apple opens a new store</title><link>https://example.com/store</link>
+			<pubDate>Mon, 21 Apr 2025 12:00:00 GMT</pubDate></item>`}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			fmt.Fprintf(w, `<rss version="2.0"><channel><title>Press</title>%s</channel></rss>`, strings.Join(items, ""))
+		}))
+		t.Cleanup(server.Close)
+
+		feedClock := clock.NewFakeClock(time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC))
+		fixture := startWorker(t, rss.NewProvider(test.NewHTTPClient(http.DefaultTransport), feedClock))
+
+		collection := testCollection(0, "AAPL")
+		collection.Spec.Source = crd.DataCollectionSource{
+			Type:       rss.SourceType,
+			Endpoint:   rss.EndpointNews,
+			Parameters: map[string]string{rss.ParameterFeeds: server.URL},
+		}
+		collection.Spec.Schedule = crd.DataCollectionSchedule{Type: crd.ScheduleRecurring, Frequency: crd.FrequencyHourly}
+
+		job := jobs.Job{ID: "job-1", CRD: collection, Symbol: "AAPL", SecurityName: "Apple Inc."}
+		if err := fixture.jobs.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		fixture.waitForHistory(t, 1)
+
+		retries, _ := fixture.retries.GetOutputChannel()
+		var next jobs.Job
+		select {
+		case next = <-retries:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for the next round")
+		}
+
+		if next.ID == job.ID || next.Attempts != 0 || !next.NotBefore.Equal(fixture.clock.Now().Add(time.Hour)) {
+			t.Errorf("Expected the next round an hour later, got %+v", next)
+		}
+
+		mu.Lock()
+		items = append(items, `<item><title>Apple updates its laptops</title><link>https://example.com/laptops</link>
+			<pubDate>Mon, 21 Apr 2025 17:00:00 GMT</pubDate></item>`)
+		mu.Unlock()
+
+		feedClock.Advance(time.Hour)
+		fixture.clock.Advance(time.Hour)
+
+		if err := fixture.jobs.Add(context.Background(), next); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+
+		fixture.waitForHistory(t, 2)
+
+		if articles := fixture.store.NewsForSecurity("AAPL", time.Time{}, time.Time{}); len(articles) != 2 {
+			t.Errorf("Expected the second round to store the new article, got %d articles", len(articles))
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/factory/history"
+)
+
+func testEntry(
+	jobID string,
+	collection string,
+	symbol string,
+	finishedAt time.Time,
+	outcome history.Outcome,
+) history.Entry {
+	return history.Entry{
+		JobID:       jobID,
+		Collection:  collection,
+		Source:      "FMP",
+		Endpoint:    "NEWS",
+		Symbol:      symbol,
+		WindowStart: finishedAt.Add(-24 * time.Hour),
+		WindowEnd:   finishedAt,
+		Attempts:    1,
+		FinishedAt:  finishedAt,
+		Duration:    time.Second,
+		RowsWritten: 10,
+		Outcome:     outcome,
+	}
+}
+
+func entryIDs(entries []history.Entry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.JobID)
+	}
+	return ids
+}
+
+func equalIDs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func openStore(t *testing.T, path string, retention time.Duration, clk clock.Clock) *history.Store {
+	t.Helper()
+
+	store, err := history.Open(path, retention, clk)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func appendEntries(t *testing.T, store *history.Store, entries ...history.Entry) {
+	t.Helper()
+
+	for _, entry := range entries {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append(%s) failed: %v", entry.JobID, err)
+		}
+	}
+}
+
+func TestHistoryStore(t *testing.T) {
+	now := time.Date(2025, 4, 21, 16, 0, 0, 0, time.UTC)
+
+	t.Run("QueryFilters", func(t *testing.T) {
+		clk := clock.NewFakeClock(now)
+		store := openStore(t, filepath.Join(t.TempDir(), "history.jsonl"), 0, clk)
+
+		appendEntries(t, store,
+			testEntry("1", "news", "AAPL", now.Add(-3*time.Hour), history.OutcomeSucceeded),
+			testEntry("2", "news", "MSFT", now.Add(-2*time.Hour), history.OutcomeFailed),
+			testEntry("3", "prices", "AAPL", now.Add(-1*time.Hour), history.OutcomeSucceeded),
+		)
+
+		testCases := []struct {
+			name     string
+			query    history.Query
+			expected []string
+		}{
+			{name: "All", query: history.Query{}, expected: []string{"3", "2", "1"}},
+			{name: "Collection", query: history.Query{Collection: "news"}, expected: []string{"2", "1"}},
+			{name: "Symbol", query: history.Query{Symbol: "AAPL"}, expected: []string{"3", "1"}},
+			{name: "Since", query: history.Query{Since: now.Add(-150 * time.Minute)}, expected: []string{"3", "2"}},
+			{name: "Failed", query: history.Query{FailedOnly: true}, expected: []string{"2"}},
+			{name: "Limit", query: history.Query{Limit: 1}, expected: []string{"3"}},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				if got := entryIDs(store.Query(testCase.query)); !equalIDs(got, testCase.expected) {
+					t.Errorf("Expected %v, got %v", testCase.expected, got)
+				}
+			})
+		}
+	})
+
+	t.Run("PersistsAcrossReopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		clk := clock.NewFakeClock(now)
+
+		store := openStore(t, path, 0, clk)
+		appendEntries(t, store, testEntry("1", "news", "AAPL", now, history.OutcomeFailed))
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+
+		reopened := openStore(t, path, 0, clk)
+		entries := reopened.Query(history.Query{})
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry after reopen, got %d", len(entries))
+		}
+
+		if entries[0].RowsWritten != 10 || entries[0].Outcome != history.OutcomeFailed || !entries[0].FinishedAt.Equal(now) {
+			t.Errorf("Entry did not round trip: %+v", entries[0])
+		}
+	})
+
+	t.Run("RetentionDropsExpiredEntries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		clk := clock.NewFakeClock(now)
+
+		store := openStore(t, path, 24*time.Hour, clk)
+		appendEntries(t, store,
+			testEntry("old", "news", "AAPL", now.Add(-23*time.Hour), history.OutcomeSucceeded),
+			testEntry("new", "news", "AAPL", now, history.OutcomeSucceeded),
+		)
+
+		clk.Advance(2 * time.Hour)
+
+		// Expired entries are hidden from queries before compaction runs.
+		if got := entryIDs(store.Query(history.Query{})); !equalIDs(got, []string{"new"}) {
+			t.Errorf("Expected [new], got %v", got)
+		}
+
+		if err := store.Compact(); err != nil {
+			t.Fatalf("Compact() failed: %v", err)
+		}
+		appendEntries(t, store, testEntry("newest", "news", "AAPL", clk.Now(), history.OutcomeSucceeded))
+		store.Close()
+
+		reopened := openStore(t, path, 0, clk)
+		if got := entryIDs(reopened.Query(history.Query{})); !equalIDs(got, []string{"newest", "new"}) {
+			t.Errorf("Expected compacted file to hold [newest new], got %v", got)
+		}
+	})
+
+	t.Run("SkipsTornLine", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		clk := clock.NewFakeClock(now)
+
+		store := openStore(t, path, 0, clk)
+		appendEntries(t, store, testEntry("1", "news", "AAPL", now, history.OutcomeSucceeded))
+		store.Close()
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open history file: %v", err)
+		}
+		file.WriteString(`{"jobId":"2","collec`)
+		file.Close()
+
+		reopened := openStore(t, path, 0, clk)
+		if got := entryIDs(reopened.Query(history.Query{})); !equalIDs(got, []string{"1"}) {
+			t.Errorf("Expected [1], got %v", got)
+		}
+	})
+}
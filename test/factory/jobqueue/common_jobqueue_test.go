@@ -2,12 +2,15 @@ package jobqueue_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
 	"golang.org/x/time/rate"
 
+	"github.com/zydee3/stockdb/internal/common/crd"
 	"github.com/zydee3/stockdb/internal/common/jobs"
 	"github.com/zydee3/stockdb/internal/factory/jobqueue"
 )
@@ -51,9 +54,33 @@ func TestInputJobQueueImplementations(t *testing.T) {
 }
 
 func TestOutputJobQueueImplementations(t *testing.T) {
-	var outputImplementations []outputFactory
+	var outputImplementations = []outputFactory{
+		{
+			name: "RateLimitJobQueue",
+			newQ: func() jobqueue.OutputJobQueue {
+				q, _ := jobqueue.NewRateLimitedOutputJobQueue(
+					context.Background(),
+					jobqueue.NewUnifiedJobQueue(10),
+					rate.NewLimiter(rate.Every(1*time.Second), 10),
+				)
+				return q
+			},
+		},
+		{
+			name: "KeyedRateLimitJobQueue",
+			newQ: func() jobqueue.OutputJobQueue {
+				q, _ := jobqueue.NewKeyedRateLimitedOutputJobQueue(
+					context.Background(),
+					jobqueue.NewUnifiedJobQueue(10),
+					nil,
+					jobqueue.SourceTypeKey,
+					func(string) *rate.Limiter { return rate.NewLimiter(rate.Every(1*time.Second), 10) },
+				)
+				return q
+			},
+		},
+	}
 
-	//nolint:govet // Ignore output implementations is empty until an output implementation is added.
 	for _, impl := range outputImplementations {
 		t.Run(impl.name+"/GetOutputChannel", func(t *testing.T) {
 			testGetOutputChannelSucceeds(t, impl.newQ())
@@ -61,12 +88,221 @@ func TestOutputJobQueueImplementations(t *testing.T) {
 	}
 }
 
+func TestRateLimitedOutputJobQueue(t *testing.T) {
+	t.Run("ReleasesJobs", func(t *testing.T) {
+		source := jobqueue.NewUnifiedJobQueue(10)
+		q := newRateLimitedOutputJobQueue(t, context.Background(), source, rate.NewLimiter(rate.Every(1*time.Second), 10))
+
+		testOutputReleasesJobs(t, source, q, 5)
+	})
+
+	t.Run("PacesReleases", func(t *testing.T) {
+		const interval = 50 * time.Millisecond
+
+		source := jobqueue.NewUnifiedJobQueue(10)
+		q := newRateLimitedOutputJobQueue(t, context.Background(), source, rate.NewLimiter(rate.Every(interval), 1))
+
+		start := time.Now()
+		testOutputReleasesJobs(t, source, q, 3)
+
+		// The first job uses the burst, the remaining two wait one interval each.
+		if elapsed := time.Since(start); elapsed < 2*interval {
+			t.Errorf("Expected releases to take at least %v, took %v", 2*interval, elapsed)
+		}
+	})
+
+	t.Run("PacesPerKey", func(t *testing.T) {
+		const interval = 200 * time.Millisecond
+
+		source := jobqueue.NewUnifiedJobQueue(10)
+		q := newKeyedRateLimitedOutputJobQueue(t, source, interval)
+
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		for _, sourceType := range []string{"FMP", "FMP", "FILE"} {
+			if addErr := source.Add(context.Background(), testJobWithSource(sourceType)); addErr != nil {
+				t.Fatalf("Add returned error: %v", addErr)
+			}
+		}
+
+		start := time.Now()
+		for range 3 {
+			select {
+			case <-outCh:
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timed out waiting to receive job")
+			}
+		}
+
+		if elapsed := time.Since(start); elapsed < interval/2 {
+			t.Errorf("Expected second FMP job to be paced, all jobs released after %v", elapsed)
+		}
+	})
+
+	t.Run("ClosesOnCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		q := newRateLimitedOutputJobQueue(t, ctx, jobqueue.NewUnifiedJobQueue(10), rate.NewLimiter(rate.Every(1*time.Second), 10))
+
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		cancel()
+
+		select {
+		case _, ok := <-outCh:
+			if ok {
+				t.Fatal("Expected output channel to be closed")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for output channel to close")
+		}
+	})
+
+	t.Run("ThrottledKeyDoesNotDelayOtherKeys", func(t *testing.T) {
+		const interval = time.Hour
+
+		source := jobqueue.NewUnifiedJobQueue(10)
+		q := newKeyedRateLimitedOutputJobQueue(t, source, interval)
+
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		// The second FMP job waits an hour, the FILE job behind it must not.
+		for _, sourceType := range []string{"FMP", "FMP", "FILE"} {
+			if addErr := source.Add(context.Background(), testJobWithSource(sourceType)); addErr != nil {
+				t.Fatalf("Add returned error: %v", addErr)
+			}
+		}
+
+		released := []string{}
+		for range 2 {
+			select {
+			case job := <-outCh:
+				released = append(released, jobqueue.SourceTypeKey(job))
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Timed out waiting to receive job, released %v", released)
+			}
+		}
+
+		if !slices.Contains(released, "FILE") {
+			t.Errorf("Expected the FILE job to overtake the throttled FMP job, released %v", released)
+		}
+	})
+
+	t.Run("ReturnsHeldJobsOnCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		source := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+
+		q := newRateLimitedOutputJobQueue(t, ctx, source, rate.NewLimiter(rate.Every(time.Hour), 1))
+
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		for range 2 {
+			if addErr := source.Add(context.Background(), testJobDefinition()); addErr != nil {
+				t.Fatalf("Add returned error: %v", addErr)
+			}
+		}
+
+		<-outCh
+
+		// The second job is held by the limiter until the queue is cancelled.
+		deadline := time.After(2 * time.Second)
+		for source.Len() != 0 {
+			select {
+			case <-deadline:
+				t.Fatal("Timed out waiting for the limiter to take the second job")
+			case <-time.After(time.Millisecond):
+			}
+		}
+
+		cancel()
+
+		for range outCh {
+			t.Error("Expected no more jobs to be released")
+		}
+
+		if source.Len() != 1 {
+			t.Errorf("Expected the held job to be returned to its queue, got %d pending", source.Len())
+		}
+	})
+
+	t.Run("RejectsInvalidLimiter", func(t *testing.T) {
+		_, err := jobqueue.NewRateLimitedOutputJobQueue(
+			context.Background(),
+			jobqueue.NewUnifiedJobQueue(10),
+			rate.NewLimiter(rate.Every(time.Second), 0),
+		)
+		if !errors.Is(err, jobqueue.ErrInvalidLimiter) {
+			t.Errorf("Expected ErrInvalidLimiter for a burst of 0, got %v", err)
+		}
+	})
+}
+
+func newRateLimitedOutputJobQueue(
+	t *testing.T,
+	ctx context.Context,
+	source jobqueue.OutputJobQueue,
+	limiter *rate.Limiter,
+) jobqueue.OutputJobQueue {
+	t.Helper()
+
+	q, err := jobqueue.NewRateLimitedOutputJobQueue(ctx, source, limiter)
+	if err != nil {
+		t.Fatalf("NewRateLimitedOutputJobQueue() failed: %v", err)
+	}
+
+	return q
+}
+
+// newKeyedRateLimitedOutputJobQueue limits FMP jobs to one per interval and
+// leaves other jobs unlimited.
+func newKeyedRateLimitedOutputJobQueue(
+	t *testing.T,
+	source jobqueue.OutputJobQueue,
+	interval time.Duration,
+) jobqueue.OutputJobQueue {
+	t.Helper()
+
+	q, err := jobqueue.NewKeyedRateLimitedOutputJobQueue(
+		context.Background(),
+		source,
+		nil,
+		jobqueue.SourceTypeKey,
+		func(key string) *rate.Limiter {
+			if key == "FMP" {
+				return rate.NewLimiter(rate.Every(interval), 1)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewKeyedRateLimitedOutputJobQueue() failed: %v", err)
+	}
+
+	return q
+}
+
 func TestFullJobQueueImplementations(t *testing.T) {
 	var fullImplementations = []fullFactory{
 		{
 			name: "UnifiedJobQueue",
 			newQ: func() jobqueue.FullJobQueue { return jobqueue.NewUnifiedJobQueue(10) },
 		},
+		{
+			name: "PriorityJobQueue",
+			newQ: func() jobqueue.FullJobQueue { return jobqueue.NewPriorityJobQueue(context.Background(), 10) },
+		},
 	}
 
 	for _, impl := range fullImplementations {
@@ -92,6 +328,16 @@ func testJobDefinition() jobs.Job {
 	return jobs.Job{}
 }
 
+func testJobWithSource(sourceType string) jobs.Job {
+	return jobs.Job{
+		CRD: &crd.DataCollection{
+			Spec: crd.DataCollectionSpec{
+				Source: crd.DataCollectionSource{Type: sourceType},
+			},
+		},
+	}
+}
+
 func testAddSucceeds(t *testing.T, q jobqueue.InputJobQueue) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
@@ -119,6 +365,32 @@ func testGetOutputChannelSucceeds(t *testing.T, q jobqueue.OutputJobQueue) {
 	}
 }
 
+func testOutputReleasesJobs(
+	t *testing.T,
+	source jobqueue.InputJobQueue,
+	q jobqueue.OutputJobQueue,
+	numJobs int,
+) {
+	outCh, err := q.GetOutputChannel()
+	if err != nil {
+		t.Fatalf("GetOutputChannel returned error: %v", err)
+	}
+
+	for range numJobs {
+		if addErr := source.Add(context.Background(), testJobDefinition()); addErr != nil {
+			t.Fatalf("Add returned error: %v", addErr)
+		}
+	}
+
+	for range numJobs {
+		select {
+		case <-outCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting to receive job")
+		}
+	}
+}
+
 func testFullJobQueueConcurrentAdds(t *testing.T, q jobqueue.FullJobQueue) {
 	outCh, err := q.GetOutputChannel()
 	if err != nil {
@@ -0,0 +1,171 @@
+package jobqueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+)
+
+func delayedJob(id string, notBefore time.Time) jobs.Job {
+	job := testJob(id, "retries", 1)
+	job.NotBefore = notBefore
+	return job
+}
+
+// waitForTimers blocks until the delayed queue has armed its timer, so that
+// advancing the fake clock afterwards is deterministic.
+func waitForTimers(t *testing.T, clk *clock.FakeClock, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for clk.Timers() != count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d timers, have %d", count, clk.Timers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func receiveJob(t *testing.T, outCh <-chan jobs.Job) jobs.Job {
+	t.Helper()
+
+	select {
+	case job := <-outCh:
+		return job
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting to receive job")
+	}
+
+	return jobs.Job{}
+}
+
+func TestDelayedJobQueueImplementations(t *testing.T) {
+	newQ := func() jobqueue.InputJobQueue {
+		return jobqueue.NewDelayedJobQueue(
+			context.Background(),
+			jobqueue.NewUnifiedJobQueue(10),
+			clock.NewFakeClock(time.Now()),
+		)
+	}
+
+	t.Run("DelayedJobQueue/AddSucceeds", func(t *testing.T) {
+		testAddSucceeds(t, newQ())
+	})
+	t.Run("DelayedJobQueue/AddHonorsCancel", func(t *testing.T) {
+		testAddHonorsCancel(t, newQ())
+	})
+}
+
+func TestDelayedJobQueue(t *testing.T) {
+	start := time.Date(2025, 4, 21, 9, 30, 0, 0, time.UTC)
+
+	t.Run("DueJobsPassThrough", func(t *testing.T) {
+		target := jobqueue.NewUnifiedJobQueue(10)
+		clk := clock.NewFakeClock(start)
+		q := jobqueue.NewDelayedJobQueue(context.Background(), target, clk)
+
+		outCh, _ := target.GetOutputChannel()
+		addJobs(t, q, delayedJob("now", start), delayedJob("past", start.Add(-time.Hour)), testJob("zero", "news", 1))
+
+		for _, expected := range []string{"now", "past", "zero"} {
+			if job := receiveJob(t, outCh); job.ID != expected {
+				t.Errorf("Expected job %s, got %s", expected, job.ID)
+			}
+		}
+
+		if q.Len() != 0 {
+			t.Errorf("Expected no delayed jobs, got %d", q.Len())
+		}
+	})
+
+	t.Run("ReleasesWhenDue", func(t *testing.T) {
+		target := jobqueue.NewUnifiedJobQueue(10)
+		clk := clock.NewFakeClock(start)
+		q := jobqueue.NewDelayedJobQueue(context.Background(), target, clk)
+
+		outCh, _ := target.GetOutputChannel()
+		addJobs(t, q, delayedJob("retry", start.Add(time.Minute)))
+		waitForTimers(t, clk, 1)
+
+		clk.Advance(30 * time.Second)
+		waitForTimers(t, clk, 1)
+		if q.Len() != 1 {
+			t.Fatalf("Expected job to still be delayed, %d delayed jobs", q.Len())
+		}
+
+		clk.Advance(30 * time.Second)
+		if job := receiveJob(t, outCh); job.ID != "retry" {
+			t.Errorf("Expected job retry, got %s", job.ID)
+		}
+	})
+
+	t.Run("ReleasesInNotBeforeOrder", func(t *testing.T) {
+		target := jobqueue.NewUnifiedJobQueue(10)
+		clk := clock.NewFakeClock(start)
+		q := jobqueue.NewDelayedJobQueue(context.Background(), target, clk)
+
+		outCh, _ := target.GetOutputChannel()
+		addJobs(t, q,
+			delayedJob("third", start.Add(3*time.Minute)),
+			delayedJob("first", start.Add(1*time.Minute)),
+			delayedJob("second", start.Add(2*time.Minute)),
+		)
+
+		peeked := jobIDs(q.Peek(jobqueue.Filter{}))
+		if expected := []string{"first", "second", "third"}; !equalIDs(peeked, expected) {
+			t.Errorf("Expected %v, got %v", expected, peeked)
+		}
+
+		waitForTimers(t, clk, 1)
+		clk.Advance(time.Hour)
+
+		for _, expected := range []string{"first", "second", "third"} {
+			if job := receiveJob(t, outCh); job.ID != expected {
+				t.Errorf("Expected job %s, got %s", expected, job.ID)
+			}
+		}
+	})
+
+	t.Run("EarlierJobRearmsTimer", func(t *testing.T) {
+		target := jobqueue.NewUnifiedJobQueue(10)
+		clk := clock.NewFakeClock(start)
+		q := jobqueue.NewDelayedJobQueue(context.Background(), target, clk)
+
+		outCh, _ := target.GetOutputChannel()
+		addJobs(t, q, delayedJob("later", start.Add(time.Hour)))
+		waitForTimers(t, clk, 1)
+
+		addJobs(t, q, delayedJob("sooner", start.Add(time.Minute)))
+		waitForTimers(t, clk, 1)
+
+		clk.Advance(time.Minute)
+		if job := receiveJob(t, outCh); job.ID != "sooner" {
+			t.Errorf("Expected job sooner, got %s", job.ID)
+		}
+
+		if q.Len() != 1 {
+			t.Errorf("Expected 1 delayed job, got %d", q.Len())
+		}
+	})
+
+	t.Run("DrainRemovesDelayedJobs", func(t *testing.T) {
+		target := jobqueue.NewUnifiedJobQueue(10)
+		clk := clock.NewFakeClock(start)
+		q := jobqueue.NewDelayedJobQueue(context.Background(), target, clk)
+
+		addJobs(t, q, delayedJob("retry", start.Add(time.Minute)))
+
+		drained := jobIDs(q.Drain(jobqueue.Filter{Collection: "retries"}))
+		if expected := []string{"retry"}; !equalIDs(drained, expected) {
+			t.Errorf("Expected to drain %v, got %v", expected, drained)
+		}
+
+		if stats := q.Stats(); stats.Total != 0 {
+			t.Errorf("Expected no delayed jobs, got %d", stats.Total)
+		}
+	})
+}
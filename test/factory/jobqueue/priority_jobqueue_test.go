@@ -0,0 +1,227 @@
+package jobqueue_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/common/jobs"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+)
+
+func testJob(id string, collection string, priority int) jobs.Job {
+	return jobs.Job{
+		ID: id,
+		CRD: &crd.DataCollection{
+			Metadata: crd.DataCollectionMetaData{Name: collection},
+			Spec: crd.DataCollectionSpec{
+				Options: crd.DataCollectionOptions{Priority: priority},
+			},
+		},
+	}
+}
+
+func addJobs(t *testing.T, q jobqueue.InputJobQueue, pending ...jobs.Job) {
+	t.Helper()
+
+	for _, job := range pending {
+		if err := q.Add(context.Background(), job); err != nil {
+			t.Fatalf("Add(%s) returned error: %v", job.ID, err)
+		}
+	}
+}
+
+func jobIDs(pending []jobs.Job) []string {
+	ids := make([]string, 0, len(pending))
+	for _, job := range pending {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func equalIDs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPriorityJobQueue(t *testing.T) {
+	t.Run("DeliversByPriority", func(t *testing.T) {
+		q := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		addJobs(t, q,
+			testJob("low-1", "news", 1),
+			testJob("high-1", "prices", 5),
+			testJob("low-2", "news", 1),
+			testJob("high-2", "prices", 5),
+		)
+
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		received := []string{}
+		for range 4 {
+			select {
+			case job := <-outCh:
+				received = append(received, job.ID)
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timed out waiting to receive job")
+			}
+		}
+
+		expected := []string{"high-1", "high-2", "low-1", "low-2"}
+		if !equalIDs(received, expected) {
+			t.Errorf("Expected delivery order %v, got %v", expected, received)
+		}
+
+		// The dispatcher settles a delivery shortly after the worker receives it.
+		deadline := time.Now().Add(2 * time.Second)
+		for q.Len() != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if q.Len() != 0 {
+			t.Errorf("Expected empty queue, got %d pending jobs", q.Len())
+		}
+	})
+
+	t.Run("PeekFilters", func(t *testing.T) {
+		q := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		addJobs(t, q,
+			testJob("news-1", "news", 1),
+			testJob("prices-1", "prices", 5),
+			testJob("news-2", "news", 1),
+		)
+
+		if q.Len() != 3 {
+			t.Errorf("Expected 3 pending jobs, got %d", q.Len())
+		}
+
+		all := jobIDs(q.Peek(jobqueue.Filter{}))
+		if expected := []string{"prices-1", "news-1", "news-2"}; !equalIDs(all, expected) {
+			t.Errorf("Expected %v, got %v", expected, all)
+		}
+
+		news := jobIDs(q.Peek(jobqueue.Filter{Collection: "news", Limit: 1}))
+		if expected := []string{"news-1"}; !equalIDs(news, expected) {
+			t.Errorf("Expected %v, got %v", expected, news)
+		}
+
+		priority := 5
+		urgent := jobIDs(q.Peek(jobqueue.Filter{Priority: &priority}))
+		if expected := []string{"prices-1"}; !equalIDs(urgent, expected) {
+			t.Errorf("Expected %v, got %v", expected, urgent)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		q := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		addJobs(t, q,
+			testJob("news-1", "news", 1),
+			testJob("prices-1", "prices", 5),
+			testJob("news-2", "news", 1),
+		)
+
+		stats := q.Stats()
+		if stats.Total != 3 {
+			t.Errorf("Expected total of 3, got %d", stats.Total)
+		}
+		if stats.ByPriority[1] != 2 || stats.ByPriority[5] != 1 {
+			t.Errorf("Unexpected per-priority counts: %v", stats.ByPriority)
+		}
+		if stats.ByCollection["news"] != 2 || stats.ByCollection["prices"] != 1 {
+			t.Errorf("Unexpected per-collection counts: %v", stats.ByCollection)
+		}
+	})
+
+	t.Run("DrainCollection", func(t *testing.T) {
+		q := jobqueue.NewPriorityJobQueue(context.Background(), 10)
+		addJobs(t, q,
+			testJob("news-1", "news", 1),
+			testJob("prices-1", "prices", 5),
+			testJob("news-2", "news", 1),
+		)
+
+		drained := jobIDs(q.Drain(jobqueue.Filter{Collection: "news"}))
+		if expected := []string{"news-1", "news-2"}; !equalIDs(drained, expected) {
+			t.Errorf("Expected to drain %v, got %v", expected, drained)
+		}
+
+		remaining := jobIDs(q.Peek(jobqueue.Filter{}))
+		if expected := []string{"prices-1"}; !equalIDs(remaining, expected) {
+			t.Errorf("Expected %v to remain, got %v", expected, remaining)
+		}
+	})
+
+	t.Run("DrainDoesNotDuplicateDeliveries", func(t *testing.T) {
+		const numJobs = 200
+
+		q := jobqueue.NewPriorityJobQueue(context.Background(), numJobs)
+		outCh, err := q.GetOutputChannel()
+		if err != nil {
+			t.Fatalf("GetOutputChannel returned error: %v", err)
+		}
+
+		for i := range numJobs {
+			addJobs(t, q, testJob(fmt.Sprintf("news-%d", i), "news", i%3))
+		}
+
+		var mu sync.Mutex
+		seen := make(map[string]int)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job := <-outCh:
+					mu.Lock()
+					seen[job.ID]++
+					mu.Unlock()
+				case <-time.After(100 * time.Millisecond):
+					return
+				}
+			}
+		}()
+
+		drained := q.Drain(jobqueue.Filter{Collection: "news"})
+		wg.Wait()
+
+		for _, job := range drained {
+			seen[job.ID]++
+		}
+
+		if len(seen) != numJobs {
+			t.Errorf("Expected %d distinct jobs, got %d", numJobs, len(seen))
+		}
+		for id, count := range seen {
+			if count != 1 {
+				t.Errorf("Job %s was both delivered and drained (%d times)", id, count)
+			}
+		}
+	})
+
+	t.Run("AddBlocksWhenFull", func(t *testing.T) {
+		q := jobqueue.NewPriorityJobQueue(context.Background(), 1)
+		addJobs(t, q, testJob("first", "news", 1))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if err := q.Add(ctx, testJob("second", "news", 1)); err == nil {
+			t.Fatal("Expected Add to a full queue to fail once the context is done")
+		}
+
+		q.Drain(jobqueue.Filter{Collection: "news"})
+		addJobs(t, q, testJob("third", "news", 1))
+	})
+}
@@ -0,0 +1,342 @@
+package factory_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fmp"
+	"github.com/zydee3/stockdb/internal/api/websocket"
+	"github.com/zydee3/stockdb/internal/common/clock"
+	"github.com/zydee3/stockdb/internal/common/crd"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/factory"
+	"github.com/zydee3/stockdb/internal/factory/jobqueue"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+// streamServer stands in for the FMP stream. It sends the messages of each
+// session to the connection with the same index, and closes every connection
+// but the last once its messages are sent.
+type streamServer struct {
+	*httptest.Server
+
+	sessions [][]string
+
+	mu         sync.Mutex
+	logins     []string
+	subscribed [][]string
+}
+
+func newStreamServer(t *testing.T, sessions ...[]string) *streamServer {
+	t.Helper()
+
+	server := &streamServer{sessions: sessions}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *streamServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	events := map[string]map[string]any{}
+	for range 2 {
+		_, data, readError := conn.ReadMessage()
+		if readError != nil {
+			return
+		}
+
+		event := struct {
+			Event string         `json:"event"`
+			Data  map[string]any `json:"data"`
+		}{}
+		if json.Unmarshal(data, &event) != nil {
+			return
+		}
+		events[event.Event] = event.Data
+	}
+
+	tickers := []string{}
+	for _, ticker := range events["subscribe"]["ticker"].([]any) {
+		tickers = append(tickers, ticker.(string))
+	}
+
+	s.mu.Lock()
+	s.logins = append(s.logins, fmt.Sprint(events["login"]["apiKey"]))
+	s.subscribed = append(s.subscribed, tickers)
+	session := len(s.subscribed) - 1
+	s.mu.Unlock()
+
+	if session >= len(s.sessions) {
+		session = len(s.sessions) - 1
+	}
+
+	for _, message := range s.sessions[session] {
+		if conn.WriteMessage(websocket.OpText, []byte(message)) != nil {
+			return
+		}
+	}
+
+	if session < len(s.sessions)-1 {
+		return
+	}
+
+	// Hold the last connection open until the client closes it.
+	for {
+		if _, _, readError := conn.ReadMessage(); readError != nil {
+			return
+		}
+	}
+}
+
+func (s *streamServer) getSubscriptions() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([][]string{}, s.subscribed...)
+}
+
+func (s *streamServer) getLogins() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.logins...)
+}
+
+func (s *streamServer) getURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func trade(symbol string, at time.Time, price float64, size float64) string {
+	return fmt.Sprintf(`{"s":%q,"t":%d,"type":"T","lp":%g,"ls":%g}`, symbol, at.UnixMilli(), price, size)
+}
+
+func streamingCollection(name string, parameters map[string]string, symbols ...string) *crd.DataCollection {
+	collection := testCollection(0, symbols...)
+	collection.Metadata.Name = name
+	collection.Spec.Source = crd.DataCollectionSource{
+		Type:       fmp.SourceType,
+		Endpoint:   fmp.EndpointPrices,
+		Parameters: parameters,
+	}
+	collection.Spec.Schedule = crd.DataCollectionSchedule{Type: crd.ScheduleStreaming}
+
+	return collection
+}
+
+func startStreams(t *testing.T, server *streamServer) (*factory.Streams, *storage.Store) {
+	t.Helper()
+
+	store := storage.NewStore()
+	p := fmp.NewProvider(nil, fmp.NewStreamClient(server.getURL(), "test-key"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	streams := factory.NewStreams(ctx, factory.StreamDependencies{
+		Providers:          newRegistry(t, p),
+		Store:              store,
+		Clock:              clock.NewRealClock(),
+		ReconnectBaseDelay: time.Millisecond,
+		ReconnectMaxDelay:  10 * time.Millisecond,
+		FlushInterval:      time.Hour,
+	})
+	t.Cleanup(func() {
+		cancel()
+		streams.Wait()
+	})
+
+	return streams, store
+}
+
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", description)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	minute := time.Date(2025, 4, 21, 14, 30, 0, 0, time.UTC)
+
+	t.Run("ReconnectsAndAggregatesBars", func(t *testing.T) {
+		server := newStreamServer(t,
+			[]string{
+				`{"event":"login","status":200,"message":"Authenticated"}`,
+				trade("aapl", minute.Add(5*time.Second), 100, 10),
+				trade("aapl", minute.Add(30*time.Second), 101.5, 5),
+			},
+			[]string{
+				trade("aapl", minute.Add(40*time.Second), 99, 1),
+				`{"s":"aapl","t":0,"type":"Q","bp":101,"bs":3,"ap":102,"as":4}`,
+				trade("aapl", minute.Add(70*time.Second), 102, 2),
+				trade("msft", minute.Add(10*time.Second), 400, 1),
+			},
+		)
+
+		streams, store := startStreams(t, server)
+
+		if err := streams.Start(streamingCollection("quotes", nil, "AAPL", "MSFT")); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+
+		waitFor(t, "the first bar", func() bool {
+			return len(store.BarsForSecurity("AAPL", records.Resolution1Min, minute, minute)) == 1
+		})
+
+		subscriptions := server.getSubscriptions()
+		if len(subscriptions) < 2 {
+			t.Fatalf("Expected the stream to reconnect, got %d connections", len(subscriptions))
+		}
+		for _, tickers := range subscriptions {
+			if strings.Join(tickers, ",") != "aapl,msft" {
+				t.Errorf("Expected every connection to subscribe to aapl,msft, got %v", tickers)
+			}
+		}
+		if logins := server.getLogins(); logins[0] != "test-key" {
+			t.Errorf("Expected login with the API key, got %q", logins[0])
+		}
+
+		if !streams.Stop("quotes") {
+			t.Fatal("Expected Stop() to find the stream")
+		}
+
+		bars := store.BarsForSecurity("AAPL", records.Resolution1Min, minute, minute.Add(time.Hour))
+		if len(bars) != 2 {
+			t.Fatalf("Expected the open bar to be flushed on Stop(), got %d bars", len(bars))
+		}
+
+		first := bars[0]
+		if first.Open != 100 || first.High != 101.5 || first.Low != 99 || first.Close != 99 || first.Volume != 16 {
+			t.Errorf("Unexpected first bar: %+v", first)
+		}
+		if first.Source != fmp.SourceType {
+			t.Errorf("Expected source %s, got %s", fmp.SourceType, first.Source)
+		}
+		if !bars[1].Time.Equal(minute.Add(time.Minute)) || bars[1].Close != 102 {
+			t.Errorf("Unexpected second bar: %+v", bars[1])
+		}
+
+		if msft := store.BarsForSecurity("MSFT", records.Resolution1Min, minute, minute); len(msft) != 1 {
+			t.Errorf("Expected one MSFT bar, got %d", len(msft))
+		}
+		if store.Ticks.Len() != 0 {
+			t.Errorf("Expected no raw ticks when aggregating, got %d", store.Ticks.Len())
+		}
+
+		if streams.Stop("quotes") {
+			t.Error("Expected a stopped stream to be gone")
+		}
+	})
+
+	t.Run("StoresRawTicks", func(t *testing.T) {
+		server := newStreamServer(t, []string{
+			trade("aapl", minute, 100, 10),
+			`{"s":"aapl","t":1745245800000,"type":"Q","bp":99.5,"bs":3,"ap":100.5,"as":4}`,
+		})
+
+		streams, store := startStreams(t, server)
+
+		collection := streamingCollection("ticks", map[string]string{factory.StreamParameterAggregate: "raw"}, "AAPL")
+		if err := streams.Start(collection); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+
+		waitFor(t, "both ticks", func() bool { return store.Ticks.Len() == 2 })
+
+		streams.Stop("ticks")
+
+		if store.Bars.Len() != 0 {
+			t.Errorf("Expected no bars in raw mode, got %d", store.Bars.Len())
+		}
+	})
+
+	t.Run("SkipsUndecodableMessages", func(t *testing.T) {
+		server := newStreamServer(t, []string{
+			trade("aapl", minute, 100, 10),
+			`not json`,
+			`{"s":"aapl","t":"soon","type":"T"}`,
+			trade("aapl", minute.Add(time.Second), 101, 5),
+		})
+
+		streams, store := startStreams(t, server)
+
+		collection := streamingCollection("ticks", map[string]string{factory.StreamParameterAggregate: "raw"}, "AAPL")
+		if err := streams.Start(collection); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+
+		waitFor(t, "both trades", func() bool { return store.Ticks.Len() == 2 })
+
+		streams.Stop("ticks")
+
+		if connections := len(server.getSubscriptions()); connections != 1 {
+			t.Errorf("Expected the stream to stay connected, got %d connections", connections)
+		}
+	})
+
+	t.Run("RejectsInvalidCollections", func(t *testing.T) {
+		server := newStreamServer(t, []string{})
+		streams, _ := startStreams(t, server)
+
+		cases := map[string]*crd.DataCollection{
+			"NoSecurities": streamingCollection("empty", nil),
+			"UnknownAggregate": streamingCollection("bad", map[string]string{
+				factory.StreamParameterAggregate: "candles",
+			}, "AAPL"),
+			"DailyResolution": streamingCollection("daily", map[string]string{
+				factory.StreamParameterResolution: "1day",
+			}, "AAPL"),
+		}
+
+		for name, collection := range cases {
+			if err := streams.Start(collection); err == nil {
+				t.Errorf("%s: expected Start() to fail", name)
+			}
+		}
+
+		if len(server.getSubscriptions()) != 0 {
+			t.Error("Expected no connection for invalid collections")
+		}
+	})
+
+	t.Run("ManagerStartsStreamsWithoutJobs", func(t *testing.T) {
+		server := newStreamServer(t, []string{trade("aapl", minute, 100, 10)})
+		streams, _ := startStreams(t, server)
+
+		p := fmp.NewProvider(nil, fmp.NewStreamClient(server.getURL(), "test-key"))
+		queue := jobqueue.NewUnifiedJobQueue(10)
+		collection := streamingCollection("managed", nil, "AAPL")
+
+		submitted, err := factory.NewManager(queue, newRegistry(t, p), streams, nil).Submit(context.Background(), collection)
+		if err != nil {
+			t.Fatalf("Submit() failed: %v", err)
+		}
+		if len(submitted) != 0 {
+			t.Errorf("Expected no jobs for a streaming collection, got %d", len(submitted))
+		}
+
+		waitFor(t, "the subscription", func() bool { return len(server.getSubscriptions()) == 1 })
+		streams.Stop("managed")
+
+		_, err = factory.NewManager(queue, newRegistry(t, p), nil, nil).Submit(context.Background(), collection)
+		if err == nil {
+			t.Error("Expected Submit() to fail without streams")
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package storage_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+// Apple paid a $0.82 dividend, ex-date 7 August 2020, before its 4-for-1
+// split of 31 August 2020. The closes are as traded. FMP reports those before
+// the split divided by 4, with their volumes multiplied by 4, and the dividend
+// as declared.
+//
+//nolint:gochecknoglobals // gochecknoglobals
+var (
+	appleCloses = []struct {
+		date  time.Time
+		close float64
+	}{
+		{time.Date(2020, time.August, 6, 0, 0, 0, 0, time.UTC), 455.61},
+		{time.Date(2020, time.August, 7, 0, 0, 0, 0, time.UTC), 444.45},
+		{time.Date(2020, time.August, 28, 0, 0, 0, 0, time.UTC), 499.23},
+		{time.Date(2020, time.August, 31, 0, 0, 0, 0, time.UTC), 129.04},
+	}
+
+	appleSplit = records.Split{
+		Symbol:      "AAPL",
+		ExDate:      time.Date(2020, time.August, 31, 0, 0, 0, 0, time.UTC),
+		Numerator:   4,
+		Denominator: 1,
+	}
+
+	appleDividend = records.Dividend{
+		Symbol: "AAPL",
+		ExDate: time.Date(2020, time.August, 7, 0, 0, 0, 0, time.UTC),
+		Amount: 0.82,
+	}
+)
+
+// writeActionHistory stores Apple's bars around its 2020 split as FMP returns
+// them, split-adjusted, or as they traded, with the split and the dividend.
+func writeActionHistory(t *testing.T, splitAdjusted bool) *storage.Store {
+	t.Helper()
+
+	store := storage.NewStore()
+
+	batch := []records.Record{appleSplit, appleDividend}
+	for _, traded := range appleCloses {
+		bar := testBar("AAPL", records.Resolution1Day, traded.date, traded.close)
+		if splitAdjusted && traded.date.Before(appleSplit.ExDate) {
+			bar = testBar("AAPL", records.Resolution1Day, traded.date, traded.close/4)
+			bar.Volume *= 4
+		}
+
+		bar.SplitAdjusted = splitAdjusted
+		batch = append(batch, bar)
+	}
+
+	if _, err := store.Write(context.Background(), batch); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	return store
+}
+
+func TestAdjustedBars(t *testing.T) {
+	// The dividend is the same fraction of the close before it on either
+	// basis.
+	dividendFactor := 1 - 0.82/455.61
+	adjusted := []float64{113.9025, 111.1125, 124.8075, 129.04}
+	adjustedVolumes := []float64{4000, 4000, 4000, 1000}
+
+	tests := []struct {
+		name          string
+		splitAdjusted bool
+		adjustment    storage.Adjustment
+		closes        []float64
+		volumes       []float64
+	}{
+		{
+			name:          "FMP/None",
+			splitAdjusted: true,
+			adjustment:    storage.AdjustNone,
+			closes:        adjusted,
+			volumes:       adjustedVolumes,
+		},
+		{
+			// FMP already applied the split, so it is not applied again.
+			name:          "FMP/Split",
+			splitAdjusted: true,
+			adjustment:    storage.AdjustSplit,
+			closes:        adjusted,
+			volumes:       adjustedVolumes,
+		},
+		{
+			name:          "FMP/TotalReturn",
+			splitAdjusted: true,
+			adjustment:    storage.AdjustTotalReturn,
+			closes:        []float64{113.9025 * dividendFactor, 111.1125, 124.8075, 129.04},
+			volumes:       adjustedVolumes,
+		},
+		{
+			name:       "Traded/None",
+			adjustment: storage.AdjustNone,
+			closes:     []float64{455.61, 444.45, 499.23, 129.04},
+			volumes:    []float64{1000, 1000, 1000, 1000},
+		},
+		{
+			name:       "Traded/Split",
+			adjustment: storage.AdjustSplit,
+			closes:     adjusted,
+			volumes:    adjustedVolumes,
+		},
+		{
+			name:       "Traded/TotalReturn",
+			adjustment: storage.AdjustTotalReturn,
+			closes:     []float64{113.9025 * dividendFactor, 111.1125, 124.8075, 129.04},
+			volumes:    adjustedVolumes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := writeActionHistory(t, tt.splitAdjusted)
+
+			bars := store.AdjustedBarsForSecurity("AAPL", records.Resolution1Day, time.Time{}, time.Time{}, tt.adjustment)
+			if len(bars) != len(tt.closes) {
+				t.Fatalf("Expected %d bars, got %d", len(tt.closes), len(bars))
+			}
+
+			for i, bar := range bars {
+				if math.Abs(bar.Close-tt.closes[i]) > 1e-9 || math.Abs(bar.Volume-tt.volumes[i]) > 1e-9 {
+					t.Errorf("Expected bar %d to close at %.4f on %.0f, got %.4f on %.0f",
+						i, tt.closes[i], tt.volumes[i], bar.Close, bar.Volume)
+				}
+
+				if bar.Open != bar.Close || bar.High != bar.Close || bar.Low != bar.Close {
+					t.Errorf("Expected every price of bar %d to be adjusted alike, got %+v", i, bar)
+				}
+			}
+		})
+	}
+}
+
+func TestAdjustedBarsWindow(t *testing.T) {
+	store := writeActionHistory(t, true)
+
+	// The dividend factor needs the close before the ex-date, which is outside
+	// the window.
+	august6 := appleCloses[0].date
+	bars := store.AdjustedBarsForSecurity("AAPL", records.Resolution1Day, august6, august6, storage.AdjustTotalReturn)
+
+	if len(bars) != 1 {
+		t.Fatalf("Expected 1 bar, got %d", len(bars))
+	}
+
+	if expected := 113.9025 * (1 - 0.205/113.9025); math.Abs(bars[0].Close-expected) > 1e-9 {
+		t.Errorf("Expected a close of %.4f, got %.4f", expected, bars[0].Close)
+	}
+
+	// Adjusting must not change the stored bars.
+	stored := store.BarsForSecurity("AAPL", records.Resolution1Day, august6, time.Time{})
+	if stored[0].Close != 113.9025 {
+		t.Errorf("Expected the stored close to be unchanged, got %.4f", stored[0].Close)
+	}
+}
+
+func TestParseAdjustment(t *testing.T) {
+	for _, value := range []string{"", "split", "total-return"} {
+		if _, err := storage.ParseAdjustment(value); err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	if _, err := storage.ParseAdjustment("dividend"); err == nil {
+		t.Error("Expected an error for an unsupported adjustment")
+	}
+}
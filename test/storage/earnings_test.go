@@ -0,0 +1,133 @@
+package storage_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func testDate(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEarningsReactions(t *testing.T) {
+	store := storage.NewStore()
+
+	batch := []records.Record{
+		// Reported on a Thursday, with a gap before it in the collected bars.
+		records.Earnings{
+			Symbol:             "MSFT",
+			ReportDate:         testDate(time.January, 30),
+			EPSActual:          float(3.23),
+			EPSEstimated:       float(3.11),
+			EPSSurprisePercent: records.SurprisePercent(float(3.23), float(3.11)),
+		},
+		records.Earnings{
+			Symbol:     "MSFT",
+			ReportDate: testDate(time.April, 30),
+			EPSActual:  float(3.46),
+		},
+		// Upcoming reports are not surprises yet.
+		records.Earnings{
+			Symbol:       "MSFT",
+			ReportDate:   testDate(time.July, 30),
+			EPSEstimated: float(3.35),
+		},
+	}
+
+	closes := map[time.Time]float64{
+		testDate(time.January, 28): 440,
+		testDate(time.January, 30): 414,
+		testDate(time.January, 31): 416,
+		testDate(time.February, 3): 411,
+		testDate(time.April, 29):   394,
+		testDate(time.April, 30):   395,
+		testDate(time.May, 1):      425,
+	}
+
+	for date, closePrice := range closes {
+		batch = append(batch, testBar("MSFT", records.Resolution1Day, date, closePrice))
+	}
+
+	// Intraday bars do not count as trading days.
+	batch = append(batch, testBar("MSFT", records.Resolution1Min, testDate(time.January, 29), 1))
+
+	if _, err := store.Write(context.Background(), batch); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	reactions := store.EarningsReactions(storage.EarningsReactionQuery{Symbol: "MSFT", Days: 1})
+	if len(reactions) != 2 {
+		t.Fatalf("Expected 2 reported earnings, got %d", len(reactions))
+	}
+
+	expected := []struct {
+		reportDate time.Time
+		baseDate   time.Time
+		endDate    time.Time
+		change     float64
+	}{
+		{testDate(time.April, 30), testDate(time.April, 29), testDate(time.May, 1), (425.0 - 394) / 394 * 100},
+		{testDate(time.January, 30), testDate(time.January, 28), testDate(time.January, 31), (416.0 - 440) / 440 * 100},
+	}
+
+	for i, reaction := range reactions {
+		want := expected[i]
+
+		if !reaction.Earnings.ReportDate.Equal(want.reportDate) {
+			t.Errorf("Expected report %d on %v, got %v", i, want.reportDate, reaction.Earnings.ReportDate)
+		}
+
+		if reaction.BaseBar == nil || reaction.ReactionBar == nil || reaction.PriceChangePercent == nil {
+			t.Fatalf("Expected a complete reaction, got %+v", reaction)
+		}
+
+		if !reaction.BaseBar.Time.Equal(want.baseDate) || !reaction.ReactionBar.Time.Equal(want.endDate) {
+			t.Errorf("Expected reaction from %v to %v, got %v to %v",
+				want.baseDate, want.endDate, reaction.BaseBar.Time, reaction.ReactionBar.Time)
+		}
+
+		if math.Abs(*reaction.PriceChangePercent-want.change) > 1e-9 {
+			t.Errorf("Expected a %.4f%% change, got %.4f%%", want.change, *reaction.PriceChangePercent)
+		}
+	}
+
+	if surprise := reactions[1].Earnings.EPSSurprisePercent; surprise == nil || math.Abs(*surprise-3.8585) > 1e-3 {
+		t.Errorf("Unexpected EPS surprise %v", surprise)
+	}
+
+	// The close of the report date precedes a report released after it, so
+	// the reaction runs through the next close by default.
+	limited := store.EarningsReactions(storage.EarningsReactionQuery{Symbol: "MSFT", Limit: 1})
+	if len(limited) != 1 || limited[0].ReactionBar == nil || !limited[0].ReactionBar.Time.Equal(testDate(time.May, 1)) {
+		t.Errorf("Expected the latest report with a reaction through the next close, got %+v", limited)
+	}
+
+	later := store.EarningsReactions(storage.EarningsReactionQuery{Symbol: "MSFT", Days: 2})
+	if later[1].ReactionBar == nil || !later[1].ReactionBar.Time.Equal(testDate(time.February, 3)) ||
+		later[0].ReactionBar != nil {
+		t.Errorf("Expected reactions 2 trading days after the report, got %+v", later)
+	}
+}
+
+func TestEarningsReactionsWithoutBars(t *testing.T) {
+	store := storage.NewStore()
+
+	report := records.Earnings{Symbol: "MSFT", ReportDate: testDate(time.January, 30), EPSActual: float(3.23)}
+	if _, err := store.Write(context.Background(), []records.Record{report}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	reactions := store.EarningsReactions(storage.EarningsReactionQuery{Symbol: "MSFT"})
+	if len(reactions) != 1 || reactions[0].PriceChangePercent != nil || reactions[0].BaseBar != nil {
+		t.Errorf("Expected a reaction without prices, got %+v", reactions)
+	}
+}
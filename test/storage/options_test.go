@@ -0,0 +1,101 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+func TestOptionChain(t *testing.T) {
+	store := storage.NewStore()
+
+	contract := func(underlying string, symbol string) records.OptionContract {
+		parsed, err := records.ParseOCCSymbol(symbol)
+		if err != nil {
+			t.Fatalf("ParseOCCSymbol(%q) failed: %v", symbol, err)
+		}
+		parsed.Underlying = underlying
+		return parsed
+	}
+
+	quote := func(symbol string, day int, bid float64) records.OptionQuote {
+		return records.OptionQuote{Symbol: symbol, Underlying: "AAPL", Time: testDate(time.April, day), Bid: bid}
+	}
+
+	batch := []records.Record{
+		contract("AAPL", "AAPL250620C00200000"),
+		contract("AAPL", "AAPL250425P00190000"),
+		contract("AAPL", "AAPL250425C00190000"),
+		contract("AAPL", "AAPL250425C00185000"),
+		contract("AAPL", "AAPL250411C00190000"),
+		contract("MSFT", "MSFT250425C00400000"),
+		quote("AAPL250411C00190000", 10, 6.2),
+		quote("AAPL250425C00185000", 17, 7.3),
+		quote("AAPL250425C00190000", 17, 4.5),
+		quote("AAPL250425C00190000", 21, 5.1),
+		quote("AAPL250425P00190000", 21, 1.9),
+	}
+
+	if _, err := store.Write(context.Background(), batch); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	chain := store.OptionChain(storage.OptionChainQuery{Underlying: "AAPL"})
+
+	expectedOrder := []string{
+		"AAPL250425C00185000",
+		"AAPL250425C00190000",
+		"AAPL250425P00190000",
+		"AAPL250620C00200000",
+	}
+	if len(chain) != len(expectedOrder) {
+		t.Fatalf("Expected %d options, got %d", len(expectedOrder), len(chain))
+	}
+	for i, symbol := range expectedOrder {
+		if chain[i].Contract.Symbol != symbol {
+			t.Errorf("Expected %s at %d, got %s", symbol, i, chain[i].Contract.Symbol)
+		}
+	}
+
+	if chain[0].Quote != nil {
+		t.Errorf("Expected no quote for an option missing from the latest snapshot, got %+v", chain[0].Quote)
+	}
+	if chain[3].Quote != nil {
+		t.Errorf("Expected no quote for an option without one, got %+v", chain[3].Quote)
+	}
+	if chain[1].Quote == nil || chain[1].Quote.Bid != 5.1 {
+		t.Errorf("Expected the latest quote, got %+v", chain[1].Quote)
+	}
+
+	asOf := store.OptionChain(storage.OptionChainQuery{Underlying: "AAPL", At: testDate(time.April, 18)})
+	if len(asOf) != len(expectedOrder) {
+		t.Fatalf("Expected the options unexpired on April 17th, got %d", len(asOf))
+	}
+	if asOf[0].Quote == nil || asOf[0].Quote.Bid != 7.3 || asOf[1].Quote == nil || asOf[1].Quote.Bid != 4.5 ||
+		asOf[2].Quote != nil {
+		t.Errorf("Expected the quotes as of April 18th, got %+v, %+v and %+v", asOf[0].Quote, asOf[1].Quote,
+			asOf[2].Quote)
+	}
+
+	expired := store.OptionChain(storage.OptionChainQuery{Underlying: "AAPL", At: testDate(time.April, 11)})
+	if len(expired) != 5 || expired[0].Contract.Symbol != "AAPL250411C00190000" || expired[0].Quote == nil {
+		t.Errorf("Expected the options unexpired on April 10th with the option expiring April 11th first, got %+v",
+			expired)
+	}
+
+	april := store.OptionChain(storage.OptionChainQuery{
+		Underlying:  "AAPL",
+		ExpiresFrom: testDate(time.April, 1),
+		ExpiresTo:   testDate(time.April, 30),
+	})
+	if len(april) != 4 {
+		t.Errorf("Expected the 4 April options, got %d", len(april))
+	}
+
+	if quotes := store.OptionQuotesForContract("AAPL250425C00190000", time.Time{}, time.Time{}); len(quotes) != 2 {
+		t.Errorf("Expected 2 quotes of the contract, got %d", len(quotes))
+	}
+}
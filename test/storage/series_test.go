@@ -0,0 +1,115 @@
+package storage_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/api/fred"
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+func TestSeriesVintages(t *testing.T) {
+	store := storage.NewStore()
+
+	// January was first published as 100 and revised to 101 on March 1st.
+	batch := []records.Record{
+		records.SeriesObservation{
+			SeriesID:      "CPIAUCSL",
+			Date:          testDate(time.January, 1),
+			RealtimeStart: testDate(time.February, 12),
+			RealtimeEnd:   testDate(time.March, 1),
+			Value:         float(100),
+		},
+		records.SeriesObservation{
+			SeriesID:      "CPIAUCSL",
+			Date:          testDate(time.January, 1),
+			RealtimeStart: testDate(time.March, 1),
+			Value:         float(101),
+		},
+		records.SeriesObservation{
+			SeriesID:      "CPIAUCSL",
+			Date:          testDate(time.February, 1),
+			RealtimeStart: testDate(time.March, 12),
+			Value:         float(102),
+		},
+	}
+
+	if _, err := store.Write(context.Background(), batch); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	if vintages := store.SeriesVintages("CPIAUCSL", time.Time{}, time.Time{}); len(vintages) != 3 {
+		t.Fatalf("Expected 3 vintages, got %d", len(vintages))
+	}
+
+	current := store.SeriesForID("CPIAUCSL", time.Time{}, time.Time{}, time.Time{})
+	if len(current) != 2 || *current[0].Value != 101 || *current[1].Value != 102 {
+		t.Errorf("Unexpected current values: %+v", current)
+	}
+
+	asOf := store.SeriesForID("CPIAUCSL", time.Time{}, time.Time{}, testDate(time.February, 20))
+	if len(asOf) != 1 || *asOf[0].Value != 100 {
+		t.Errorf("Expected only the first January value on February 20th, got %+v", asOf)
+	}
+}
+
+func TestSeriesCollectedTwice(t *testing.T) {
+	// FRED's answers before and after January was revised on March 12th,
+	// when February was first published.
+	responses := []string{
+		`{"observations": [
+			{"realtime_start": "2025-02-12", "realtime_end": "9999-12-31", "date": "2025-01-01", "value": "100"}
+		]}`,
+		`{"observations": [
+			{"realtime_start": "2025-02-12", "realtime_end": "2025-03-11", "date": "2025-01-01", "value": "100"},
+			{"realtime_start": "2025-03-12", "realtime_end": "9999-12-31", "date": "2025-01-01", "value": "101"},
+			{"realtime_start": "2025-03-12", "realtime_end": "9999-12-31", "date": "2025-02-01", "value": "102"}
+		]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(responses[0]))
+		responses = responses[1:]
+	}))
+	t.Cleanup(server.Close)
+
+	client := httpUtil.HTTPClient{Client: server.Client(), RetryCount: 0}
+	p := fred.NewProvider(fred.NewHTTPClient(client, "test-key", server.URL))
+	store := storage.NewStore()
+
+	for range 2 {
+		payload, err := p.Fetch(context.Background(), provider.Request{
+			Endpoint: fred.EndpointObservations,
+			Series:   "CPIAUCSL",
+		})
+		if err != nil {
+			t.Fatalf("Fetch() failed: %v", err)
+		}
+
+		normalized, err := p.Normalize(payload)
+		if err != nil {
+			t.Fatalf("Normalize() failed: %v", err)
+		}
+
+		if _, err = store.Write(context.Background(), normalized); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	// The second collection closed the first vintage of January rather than
+	// adding a second current value.
+	current := store.SeriesForID("CPIAUCSL", time.Time{}, time.Time{}, time.Time{})
+	if len(current) != 2 || *current[0].Value != 101 || *current[1].Value != 102 {
+		t.Errorf("Expected a single current value per date, got %+v", current)
+	}
+
+	if vintages := store.SeriesVintages("CPIAUCSL", time.Time{}, time.Time{}); len(vintages) != 3 {
+		t.Errorf("Expected 3 vintages, got %+v", vintages)
+	}
+}
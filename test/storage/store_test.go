@@ -0,0 +1,86 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/storage"
+)
+
+func testBar(symbol string, resolution records.Resolution, start time.Time, closePrice float64) records.Bar {
+	return records.Bar{
+		Symbol:     symbol,
+		Resolution: resolution,
+		Time:       start,
+		Open:       closePrice,
+		High:       closePrice,
+		Low:        closePrice,
+		Close:      closePrice,
+		Volume:     1000,
+		Source:     "FMP",
+	}
+}
+
+func TestStoreBars(t *testing.T) {
+	store := storage.NewStore()
+	start := time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC)
+
+	first := []records.Record{
+		testBar("NVDA", records.Resolution1Min, start, 100),
+		testBar("NVDA", records.Resolution1Min, start.Add(time.Minute), 101),
+		testBar("NVDA", records.Resolution5Min, start, 100),
+	}
+
+	// The second window overlaps the first by one bar, which has been revised.
+	second := []records.Record{
+		testBar("NVDA", records.Resolution1Min, start.Add(time.Minute), 102),
+		testBar("NVDA", records.Resolution1Min, start.Add(2*time.Minute), 103),
+	}
+
+	for _, batch := range [][]records.Record{first, second} {
+		if _, err := store.Write(context.Background(), batch); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	bars := store.BarsForSecurity("NVDA", records.Resolution1Min, time.Time{}, time.Time{})
+	if len(bars) != 3 {
+		t.Fatalf("Expected 3 one minute bars, got %d", len(bars))
+	}
+
+	for i, expectedClose := range []float64{100, 102, 103} {
+		if bars[i].Close != expectedClose || !bars[i].Time.Equal(start.Add(time.Duration(i)*time.Minute)) {
+			t.Errorf("Unexpected bar %d: %+v", i, bars[i])
+		}
+	}
+
+	if fiveMinute := store.BarsForSecurity("NVDA", records.Resolution5Min, start, start); len(fiveMinute) != 1 {
+		t.Errorf("Expected 1 five minute bar, got %d", len(fiveMinute))
+	}
+}
+
+func TestStoreRejectsUnknownRecord(t *testing.T) {
+	store := storage.NewStore()
+
+	batch := []records.Record{
+		testBar("NVDA", records.Resolution1Day, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), 100),
+		unknownRecord{},
+	}
+
+	if _, err := store.Write(context.Background(), batch); err == nil {
+		t.Fatal("Expected an error for an unknown record type")
+	}
+
+	if store.Bars.Len() != 0 {
+		t.Errorf("Expected the batch to be rejected whole, have %d bars", store.Bars.Len())
+	}
+}
+
+type unknownRecord struct{}
+
+func (unknownRecord) GetAnchor() string       { return "" }
+func (unknownRecord) GetTimestamp() time.Time { return time.Time{} }
+func (unknownRecord) GetKey() string          { return "" }
+func (unknownRecord) Validate() error         { return nil }
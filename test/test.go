@@ -1 +1,132 @@
 package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/zydee3/stockdb/internal/api/provider"
+	httpUtil "github.com/zydee3/stockdb/internal/api/utility"
+	"github.com/zydee3/stockdb/internal/common/records"
+	"github.com/zydee3/stockdb/internal/common/secrets"
+)
+
+// FakeProvider is a provider.Provider that returns canned records, or Err,
+// for every request and remembers the requests it received.
+type FakeProvider struct {
+	SourceType    string
+	EndpointNames []string
+	Records       []records.Record
+	Err           error
+	// Series makes the provider take series targets instead of securities.
+	Series bool
+
+	mu       sync.Mutex
+	requests []provider.Request
+	secrets  []secrets.Value
+}
+
+func (f *FakeProvider) Type() string {
+	return f.SourceType
+}
+
+func (f *FakeProvider) Capabilities() []provider.Capability {
+	if f.Series {
+		return []provider.Capability{provider.CapabilityHistorical, provider.CapabilitySeries}
+	}
+
+	return []provider.Capability{provider.CapabilityHistorical}
+}
+
+func (f *FakeProvider) Endpoints() []string {
+	return f.EndpointNames
+}
+
+func (f *FakeProvider) Limits() provider.RequestLimits {
+	return provider.RequestLimits{}
+}
+
+func (f *FakeProvider) Fetch(ctx context.Context, request provider.Request) (*provider.Payload, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	if value, ok := secrets.FromContext(ctx); ok {
+		f.secrets = append(f.secrets, value)
+	}
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return &provider.Payload{Request: request, Data: f.Records, BytesFetched: int64(len(f.Records))}, nil
+}
+
+func (f *FakeProvider) Normalize(payload *provider.Payload) ([]records.Record, error) {
+	normalized, _ := payload.Data.([]records.Record)
+	return normalized, nil
+}
+
+func (f *FakeProvider) Requests() []provider.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]provider.Request{}, f.requests...)
+}
+
+// GetSecrets returns the secrets requests were made with, see
+// secrets.NewContext.
+func (f *FakeProvider) GetSecrets() []secrets.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]secrets.Value{}, f.secrets...)
+}
+
+// RecordingRoundTripper answers every request with Status, or 200 if it is
+// unset, and Body, and remembers the requests it received.
+type RecordingRoundTripper struct {
+	Status int
+	Body   string
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (r *RecordingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, request)
+	r.mu.Unlock()
+
+	if err := request.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(r.Body)),
+	}, nil
+}
+
+func (r *RecordingRoundTripper) Requests() []*http.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*http.Request{}, r.requests...)
+}
+
+// NewHTTPClient returns a client sending requests through transport without
+// retrying them.
+func NewHTTPClient(transport http.RoundTripper) httpUtil.HTTPClient {
+	return httpUtil.HTTPClient{
+		Client:     &http.Client{Transport: transport},
+		RetryCount: 0,
+	}
+}